@@ -44,6 +44,16 @@ type Config struct {
 	InvalidSchemaURL     string
 	SlackCredentials     *Secret
 	MaxRetries           int
+	//LateArrivalHorizonInSec how long after batch window end processed window record is kept, later data file goes to catch-up window right away
+	LateArrivalHorizonInSec int
+}
+
+//LateArrivalHorizon returns late arrival horizon
+func (c *Config) LateArrivalHorizon() time.Duration {
+	if c.LateArrivalHorizonInSec <= 0 {
+		return shared.LateArrivalHorizonInSec * time.Second
+	}
+	return time.Duration(c.LateArrivalHorizonInSec) * time.Second
 }
 
 //BuildLoadURL returns active action ProcessURL for supplied event id
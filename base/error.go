@@ -2,6 +2,7 @@ package base
 
 import (
 	"fmt"
+	"github.com/pkg/errors"
 	"google.golang.org/api/googleapi"
 	"net/http"
 	"strings"
@@ -98,3 +99,29 @@ func IsPermissionDenied(err error) bool {
 	message := err.Error()
 	return strings.Contains(message, accessDenied)
 }
+
+//IsPreConditionError returns true if storage precondition (i.e. generation match) failed
+func IsPreConditionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	origin := errors.Cause(err)
+	if googleError, ok := origin.(*googleapi.Error); ok && googleError.Code == http.StatusPreconditionFailed {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, fmt.Sprintf(" %v", http.StatusPreconditionFailed))
+}
+
+//IsRateLimitError returns true if rate limit (too many requests) error
+func IsRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	origin := errors.Cause(err)
+	if googleError, ok := origin.(*googleapi.Error); ok && googleError.Code == http.StatusTooManyRequests {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, fmt.Sprintf(" %v", http.StatusTooManyRequests))
+}
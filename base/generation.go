@@ -0,0 +1,15 @@
+package base
+
+import (
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	gstorage "google.golang.org/api/storage/v1"
+)
+
+//GenerationMatch returns object generation match precondition, or nil if storage does not expose object generation
+func GenerationMatch(object storage.Object) *option.Generation {
+	if gsObject, ok := object.Sys().(*gstorage.Object); ok && gsObject != nil {
+		return option.NewGeneration(true, gsObject.Generation)
+	}
+	return nil
+}
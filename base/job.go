@@ -52,6 +52,9 @@ func (e *Job) JobID() string {
 
 //Dest returns job destination
 func (e *Job) Dest() string {
+	if e.Configuration == nil {
+		return ""
+	}
 	switch e.Configuration.JobType {
 	case "QUERY":
 		dest := e.Configuration.Query.DestinationTable
@@ -145,3 +148,47 @@ func IsJobDone(job *bigquery.Job) bool {
 	}
 	return job.Status.State == shared.DoneState
 }
+
+//Stats returns job attributes and statistics
+func (e *Job) Stats() map[string]interface{} {
+	var result = map[string]interface{}{
+		"JobID": e.JobID(),
+	}
+	if e.Configuration != nil {
+		result["JobType"] = e.Configuration.JobType
+		result["Source"] = e.Source()
+		result["Dest"] = e.Dest()
+		result["DestTable"] = e.DestTable()
+	}
+	if e.Status != nil {
+		result["State"] = e.Status.State
+		if err := e.Error(); err != nil {
+			result[shared.ErrorKey] = err.Error()
+		}
+	}
+	stats := e.Statistics
+	if stats == nil {
+		return result
+	}
+	result["TotalBytesProcessed"] = stats.TotalBytesProcessed
+	result["TotalSlotMs"] = stats.TotalSlotMs
+	if stats.StartTime > 0 && stats.EndTime > 0 {
+		result["ElapsedMs"] = stats.EndTime - stats.StartTime
+	}
+	if load := stats.Load; load != nil {
+		result["InputFiles"] = load.InputFiles
+		result["InputFileBytes"] = load.InputFileBytes
+		result["OutputRows"] = load.OutputRows
+		result["OutputBytes"] = load.OutputBytes
+		result["BadRecords"] = load.BadRecords
+	}
+	if query := stats.Query; query != nil {
+		result["TotalBytesBilled"] = query.TotalBytesBilled
+		result["NumDmlAffectedRows"] = query.NumDmlAffectedRows
+		if _, ok := result["OutputRows"]; !ok {
+			result["OutputRows"] = query.NumDmlAffectedRows
+		}
+		result["CacheHit"] = query.CacheHit
+	}
+	return result
+}
@@ -22,6 +22,47 @@ type Loader struct {
 	nextCheck      time.Time
 	onChange       Notify
 	onRemove       Notify
+	dependencies   *Resources
+	dependents     map[string][]string
+}
+
+//Depend registers URLs the dependent asset is derived from, dependent is notified when any of them changes
+func (m *Loader) Depend(dependent string, dependencies ...string) {
+	if len(dependencies) == 0 {
+		delete(m.dependents, dependent)
+		return
+	}
+	m.dependents[dependent] = dependencies
+}
+
+//changedDependencies returns dependency URLs modified since the last check
+func (m *Loader) changedDependencies(ctx context.Context, snapshot map[string]time.Time) map[string]bool {
+	var result = make(map[string]bool)
+	var current = make(map[string]time.Time)
+	for _, dependencies := range m.dependents {
+		for _, URL := range dependencies {
+			if _, ok := current[URL]; ok {
+				continue
+			}
+			lastModified, ok := snapshot[URL]
+			if !ok {
+				object, err := m.fs.Object(ctx, URL)
+				if err == nil {
+					lastModified = object.ModTime()
+				}
+			}
+			current[URL] = lastModified
+			modTime := m.dependencies.Get(URL)
+			if modTime != nil && !modTime.Equal(lastModified) {
+				result[URL] = true
+			}
+			m.dependencies.Add(URL, lastModified)
+		}
+	}
+	for _, URL := range m.dependencies.GetMissing(current) {
+		m.dependencies.Remove(URL)
+	}
+	return result
 }
 
 func (m *Loader) isCheckDue(now time.Time) bool {
@@ -52,6 +93,27 @@ func (m *Loader) notify(ctx context.Context, currentSnapshot []storage.Object) (
 		notified = true
 		m.onRemove(ctx, m.fs, URL)
 		m.rules.Remove(URL)
+		m.Depend(URL)
+	}
+	changed := m.changedDependencies(ctx, snapshot)
+	if len(changed) == 0 {
+		return notified
+	}
+	var dependents = make([]string, 0)
+	for dependent, dependencies := range m.dependents {
+		if _, ok := snapshot[dependent]; !ok {
+			continue
+		}
+		for _, URL := range dependencies {
+			if changed[URL] {
+				dependents = append(dependents, dependent)
+				break
+			}
+		}
+	}
+	for _, dependent := range dependents {
+		notified = true
+		m.onChange(ctx, m.fs, dependent)
 	}
 	return notified
 }
@@ -83,6 +145,8 @@ func NewLoader(baeURL string, checkFrequency time.Duration, fs afs.Service, onCh
 		checkFrequency: checkFrequency,
 		baseURL:        baeURL,
 		rules:          NewResources(),
+		dependencies:   NewResources(),
+		dependents:     make(map[string][]string),
 	}
 }
 
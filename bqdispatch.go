@@ -29,3 +29,19 @@ func handleDispatchEvent(ctx context.Context) (*contract.Response, error) {
 	}
 	return response, nil
 }
+
+//BqDispatchEvent BigQuery job completion audit log Pub/Sub trigger background cloud function entry point
+func BqDispatchEvent(ctx context.Context, message contract.Message) error {
+	service, err := dispatch.Singleton(ctx)
+	if err != nil {
+		return err
+	}
+	response := service.DispatchEvent(ctx, message.Data)
+	if shared.IsDebugLoggingLevel() || response.Error != "" {
+		shared.LogLn(response)
+	}
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	return nil
+}
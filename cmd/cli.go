@@ -8,6 +8,7 @@ import (
 	"github.com/viant/bqtail/cmd/option"
 	"github.com/viant/bqtail/cmd/rule/build"
 	"github.com/viant/bqtail/cmd/rule/validate"
+	"github.com/viant/bqtail/cmd/stats"
 	"github.com/viant/bqtail/cmd/tail"
 	"github.com/viant/bqtail/shared"
 	"github.com/viant/toolbox"
@@ -20,13 +21,25 @@ const defaultOperationURL = "file:///tmp/bqtail/operation"
 //RunClient run client
 func RunClient(Version string, args []string) {
 	options := &option.Options{}
-	_, err := flags.ParseArgs(options, args)
+	commands, err := flags.ParseArgs(options, args)
 	if isHelOption(args) {
 		return
 	}
 	if err != nil {
 		log.Fatal(err)
 	}
+	if len(commands) > 0 && commands[0] == schemaCommand {
+		if err = printRuleSchema(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(commands) > 0 && commands[0] == planCommand {
+		if err = runPlan(context.Background(), options, commands[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	client, err := auth.ClientFromURL(options.ClientURL())
 	if err != nil {
 		log.Fatal(err)
@@ -48,9 +61,13 @@ func RunClient(Version string, args []string) {
 		os.Setenv(shared.LoggingEnvKey, options.Logging)
 	}
 
-	canBuildRule := options.Destination != ""
+	command := ""
+	if len(commands) > 0 {
+		command = commands[0]
+	}
+	canBuildRule := options.Destination != "" && command == ""
 	canLoad := options.SourceURL != ""
-	if !(canLoad || options.Validate || canBuildRule) && len(args) == 1 {
+	if !(canLoad || options.Validate || canBuildRule || command != "") && len(args) == 1 {
 		os.Exit(1)
 	}
 
@@ -61,6 +78,17 @@ func RunClient(Version string, args []string) {
 
 	ctx := context.Background()
 
+	switch command {
+	case "":
+	case statsCommand:
+		if err = srv.Stats(ctx, &stats.Request{Options: options}); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	default:
+		log.Fatalf("unsupported command: %v", command)
+	}
+
 	if options.RuleURL == "" || canBuildRule {
 		err = srv.Build(ctx, &build.Request{Options: options})
 		if err != nil {
@@ -3,3 +3,15 @@ package cmd
 const (
 	processingRoutines = 30
 )
+
+const (
+	statsCommand  = "stats"
+	schemaCommand = "schema"
+	planCommand   = "plan"
+)
+
+const (
+	planProjectID = "project"
+	dotFormat     = "dot"
+	mermaidFormat = "mermaid"
+)
@@ -16,6 +16,8 @@ type Options struct {
 
 	Validate bool `short:"V" long:"validate" description:"run validation"`
 
+	Offline bool `long:"offline" description:"validate rule without BigQuery dry run checks (no credentials required)"`
+
 	Version bool `short:"v" long:"version" description:"bqtail version"`
 
 	ProjectID string `short:"p" long:"project" description:"Google Cloud Project"`
@@ -57,6 +59,14 @@ type Options struct {
 	Autodetect bool `short:"a" long:"autodetect" description:"auto detect schema"`
 
 	BaseOperationURL string `short:"i" long:"ops" description:"operation base URL"`
+
+	CounterURL string `long:"counter" description:"ingestion counters base URL (stats command), rule CounterURL is used by default"`
+
+	Hours int `long:"hours" description:"ingestion counters time range in hours (stats command)"`
+
+	Tables string `long:"tables" description:"JSON file with BigQuery tables keyed by table name, used by offline faker (plan command)"`
+
+	Format string `long:"format" description:"action tree format (plan command)" choice:"text" choice:"dot" choice:"mermaid"`
 }
 
 //ClientURI returns clientURL
@@ -88,14 +98,14 @@ func (r *Options) Init(config *tail.Config) {
 		r.MatchPrefix = shared.DefaultPrefix
 	}
 	if r.SourceURL != "" {
-		r.SourceURL = normalizeLocation(r.SourceURL)
+		r.SourceURL = NormalizeLocation(r.SourceURL)
 	}
 	if r.RuleURL != "" {
-		r.RuleURL = normalizeLocation(r.RuleURL)
+		r.RuleURL = NormalizeLocation(r.RuleURL)
 	}
 
 	if r.HistoryURL != "" {
-		r.HistoryURL = normalizeLocation(r.HistoryURL)
+		r.HistoryURL = NormalizeLocation(r.HistoryURL)
 	}
 
 	if r.Bucket == "" {
@@ -114,7 +124,8 @@ func (r *Options) initHistoryURL() {
 	}
 }
 
-func normalizeLocation(location string) string {
+//NormalizeLocation returns absolute location for home or working directory relative location
+func NormalizeLocation(location string) string {
 	if strings.HasPrefix(location, "~/") {
 		location = strings.Replace(location, "~/", os.Getenv("HOME"), 1)
 	}
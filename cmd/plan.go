@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/cmd/option"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/plan"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+)
+
+//runPlan prints offline execution plan for source URLs, BigQuery is replaced with faker and storage with memory file system
+func runPlan(ctx context.Context, options *option.Options, args []string) error {
+	var URLs = make([]string, 0)
+	for _, URL := range append(strings.Split(options.SourceURL, ","), args...) {
+		if URL = strings.TrimSpace(URL); URL != "" {
+			URLs = append(URLs, option.NormalizeLocation(URL))
+		}
+	}
+	if len(URLs) == 0 {
+		return errors.New("source URLs were empty")
+	}
+	if options.RuleURL == "" {
+		return errors.New("ruleURL was empty")
+	}
+	projectID := options.ProjectID
+	if projectID == "" {
+		projectID = planProjectID
+	}
+	fs := afs.New()
+	cfg, err := NewConfig(ctx, projectID, url.Join(shared.InMemoryStorageBaseURL, "plan"))
+	if err != nil {
+		return errors.Wrap(err, "failed to create config for plan")
+	}
+	tables, err := loadTables(ctx, fs, options.Tables)
+	if err != nil {
+		return err
+	}
+	srv := &service{config: cfg, fs: fs}
+	rule, err := srv.loadRule(ctx, option.NormalizeLocation(options.RuleURL))
+	if err != nil {
+		return err
+	}
+	srv.reportRule(rule)
+	planService := plan.New(cfg, fs, bq.NewFaker(tables, &bigquery.Table{Schema: &bigquery.TableSchema{}}))
+	plans, err := planService.Plan(ctx, URLs...)
+	if err != nil {
+		return err
+	}
+	for _, aPlan := range plans {
+		reportPlan(aPlan, options.Format)
+	}
+	return nil
+}
+
+//loadTables loads faker tables, table schema is used to build transient and destination SQL
+func loadTables(ctx context.Context, fs afs.Service, URL string) (map[string]*bigquery.Table, error) {
+	var result = make(map[string]*bigquery.Table)
+	if URL == "" {
+		return result, nil
+	}
+	data, err := fs.DownloadWithURL(ctx, option.NormalizeLocation(URL))
+	if err == nil {
+		defer func() { _ = data.Close() }()
+		err = json.NewDecoder(data).Decode(&result)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load tables: %v", URL)
+	}
+	return result, nil
+}
+
+func reportPlan(aPlan *plan.Plan, format string) {
+	fmt.Printf("==== PLAN: %v ====\n", aPlan.URL)
+	fmt.Printf("Status: %v\n", aPlan.Status)
+	if aPlan.Error != "" {
+		fmt.Printf("Error: %v\n", aPlan.Error)
+	}
+	if aPlan.Warning != "" {
+		fmt.Printf("Warning: %v\n", aPlan.Warning)
+	}
+	if aPlan.RuleURL == "" {
+		return
+	}
+	fmt.Printf("Rule: %v\n", aPlan.RuleURL)
+	fmt.Printf("Event ID: %v\n", aPlan.EventID)
+	fmt.Printf("Dest table: %v\n", aPlan.DestTable)
+	if aPlan.Partition != "" {
+		fmt.Printf("Partition: %v\n", aPlan.Partition)
+	}
+	if aPlan.WindowURL != "" {
+		fmt.Printf("Batch window: %v\n", aPlan.WindowURL)
+	}
+	if aPlan.OwnerEventID != "" {
+		fmt.Printf("Batched with event ID: %v\n", aPlan.OwnerEventID)
+		return
+	}
+	if aPlan.TransientTable != "" {
+		fmt.Printf("Transient table: %v\n", aPlan.TransientTable)
+	}
+	if len(aPlan.URIs) > 0 {
+		fmt.Printf("Source URIs:\n  %v\n", strings.Join(aPlan.URIs, "\n  "))
+	}
+	if len(aPlan.Steps) > 0 {
+		fmt.Printf("Jobs:\n")
+		for _, step := range aPlan.Steps {
+			if step.JobID != "" {
+				fmt.Printf("  %v: %v\n", step.Action, step.JobID)
+			}
+		}
+		for _, step := range aPlan.Steps {
+			if step.SQL != "" {
+				fmt.Printf("SQL (%v):\n%v\n", step.JobID, step.SQL)
+			}
+		}
+	}
+	if aPlan.Action == nil {
+		return
+	}
+	fmt.Printf("Actions:\n")
+	switch format {
+	case dotFormat:
+		fmt.Print(plan.Graphviz(aPlan.Action))
+	case mermaidFormat:
+		fmt.Print(plan.Mermaid(aPlan.Action))
+	default:
+		fmt.Print(plan.Text(aPlan.Action))
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"github.com/pkg/errors"
 	"github.com/viant/afs/file"
@@ -9,6 +10,7 @@ import (
 	"github.com/viant/bqtail/tail/config"
 	"github.com/viant/toolbox"
 	"gopkg.in/yaml.v2"
+	"io/ioutil"
 	"time"
 )
 
@@ -18,9 +20,16 @@ func (s *service) loadRule(ctx context.Context, URL string) (*config.Rule, error
 		return nil, errors.Wrapf(err, "failed to download rule: %v", URL)
 	}
 	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rule: %v", URL)
+	}
+	if data, err = s.config.Extend(ctx, s.fs, URL, data); err != nil {
+		return nil, errors.Wrapf(err, "failed to extend rule: %v", URL)
+	}
 	_, name := url.Split(URL, "")
 	ruleURL := url.Join(s.config.RulesURL, name)
-	err = s.fs.Upload(ctx, ruleURL, file.DefaultFileOsMode, reader)
+	err = s.fs.Upload(ctx, ruleURL, file.DefaultFileOsMode, bytes.NewReader(data))
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to update rule: %v", ruleURL)
 	}
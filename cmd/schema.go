@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/tail/config"
+)
+
+//printRuleSchema prints rule JSON schema
+func printRuleSchema() error {
+	data, err := json.MarshalIndent(config.RuleSchema(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode rule schema")
+	}
+	fmt.Printf("%s\n", data)
+	return nil
+}
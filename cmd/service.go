@@ -7,6 +7,7 @@ import (
 	"github.com/viant/afs"
 	"github.com/viant/bqtail/cmd/rule/build"
 	"github.com/viant/bqtail/cmd/rule/validate"
+	"github.com/viant/bqtail/cmd/stats"
 	ctail "github.com/viant/bqtail/cmd/tail"
 	"github.com/viant/bqtail/tail"
 	"github.com/viant/bqtail/tail/contract"
@@ -21,6 +22,8 @@ type Service interface {
 	Validate(ctx context.Context, request *validate.Request) error
 	//Load start load process for specified source and rule
 	Load(ctx context.Context, request *ctail.Request) (*ctail.Response, error)
+	//Stats reports rule ingestion counters
+	Stats(ctx context.Context, request *stats.Request) error
 	//Stop stop service
 	Stop()
 }
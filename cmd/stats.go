@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/cmd/stats"
+	"github.com/viant/bqtail/tail/counter"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultStatsHours = 24
+
+//Stats reports rule ingestion counters
+func (s *service) Stats(ctx context.Context, request *stats.Request) error {
+	request.Init(s.config)
+	counterURL := request.CounterURL
+	if counterURL == "" {
+		if request.RuleURL == "" {
+			return errors.New("ruleURL and counterURL were empty")
+		}
+		rule, err := s.loadRule(ctx, request.RuleURL)
+		if err != nil {
+			return err
+		}
+		if counterURL = rule.CounterURL; counterURL == "" {
+			return errors.Errorf("rule CounterURL was empty: %v", request.RuleURL)
+		}
+	}
+	hours := request.Hours
+	if hours == 0 {
+		hours = defaultStatsHours
+	}
+	to := time.Now().UTC()
+	from := to.Add(-time.Duration(hours) * time.Hour)
+	counters, err := counter.New(s.fs).List(ctx, counterURL, request.Destination, from, to)
+	if err != nil {
+		return err
+	}
+	s.reportCounters(counters)
+	return nil
+}
+
+func (s *service) reportCounters(counters []*counter.Counter) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(writer, "DEST\tHOUR\tFILES\tBYTES\tROWS\tCORRUPTED\tINVALID_SCHEMA\tRELOADS\n")
+	total := &counter.Counter{}
+	for _, c := range counters {
+		total.Add(c)
+		_, _ = fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", c.Dest, c.Hour, c.Files, c.Bytes, c.Rows, c.Corrupted, c.InvalidSchema, c.Reloads)
+	}
+	_, _ = fmt.Fprintf(writer, "TOTAL\t\t%v\t%v\t%v\t%v\t%v\t%v\n", total.Files, total.Bytes, total.Rows, total.Corrupted, total.InvalidSchema, total.Reloads)
+	_ = writer.Flush()
+}
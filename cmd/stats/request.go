@@ -0,0 +1,10 @@
+package stats
+
+import (
+	"github.com/viant/bqtail/cmd/option"
+)
+
+//Request represents ingestion counters request
+type Request struct {
+	*option.Options
+}
@@ -5,8 +5,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/viant/afs/file"
 	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/auth"
 	"github.com/viant/bqtail/cmd/rule/validate"
+	"github.com/viant/bqtail/service/bq"
 	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail"
+	"github.com/viant/bqtail/tail/check"
+	"github.com/viant/bqtail/task"
+	"google.golang.org/api/bigquery/v2"
+	goption "google.golang.org/api/option"
 )
 
 func (s *service) Validate(ctx context.Context, request *validate.Request) error {
@@ -20,11 +27,38 @@ func (s *service) Validate(ctx context.Context, request *validate.Request) error
 		return errors.Wrap(err, "failed to create config for validation")
 	}
 	cfg.RulesURL = parent
-	err = cfg.Init(ctx, s.fs)
-	if err == nil && len(cfg.Rules) > 0 {
-		s.reportRule(cfg.Rules[0])
-		shared.LogLn("Rule is VALID\n")
+	cfg.StrictMode = true
+	if err = cfg.Init(ctx, s.fs); err != nil {
+		return err
 	}
+	if len(cfg.Rules) == 0 {
+		return errors.Errorf("invalid rule: %v", request.RuleURL)
+	}
+	s.reportRule(cfg.Rules[0])
+	if request.Offline {
+		shared.LogLn("Rule is VALID (offline, BigQuery checks skipped)\n")
+		return nil
+	}
+	bqService, err := s.newBigQuery(ctx, cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create BigQuery service for validation")
+	}
+	if err = check.New(bqService).Check(ctx, cfg.Rules[0]); err != nil {
+		return err
+	}
+	shared.LogLn("Rule is VALID\n")
+	return nil
+}
 
-	return err
+//newBigQuery creates BigQuery service for rule dry run checks
+func (s *service) newBigQuery(ctx context.Context, cfg *tail.Config) (bq.Service, error) {
+	options := []goption.ClientOption{goption.WithScopes(auth.Scopes...)}
+	if client, _ := auth.DefaultHTTPClientProvider(ctx, auth.Scopes); client != nil {
+		options = append(options, goption.WithHTTPClient(client))
+	}
+	bqService, err := bigquery.NewService(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return bq.New(bqService, task.NewRegistry(), cfg.ProjectID, s.fs, cfg.Config), nil
 }
@@ -1,8 +1,12 @@
 package dispatch
 
 import (
+	"context"
 	"fmt"
+	"github.com/viant/afs/storage"
+	"github.com/viant/bqtail/shared"
 	"github.com/viant/toolbox"
+	"path"
 	"strings"
 	"time"
 )
@@ -21,3 +25,56 @@ func URLToWindowEndTime(URL string) (*time.Time, error) {
 	ts := time.Unix(int64(unixTimestamp), 0)
 	return &ts, nil
 }
+
+//isBatchFile returns true if file is batch window or early closed window marker
+func isBatchFile(name string) bool {
+	ext := path.Ext(name)
+	return ext == shared.WindowExt || ext == shared.ClosedWindowExt
+}
+
+//closedWindows returns window URLs with early closed window marker
+func closedWindows(objects []storage.Object) map[string]bool {
+	var result = make(map[string]bool)
+	for _, object := range objects {
+		if object.IsDir() || path.Ext(object.Name()) != shared.ClosedWindowExt {
+			continue
+		}
+		result[strings.Replace(object.URL(), shared.ClosedWindowExt, shared.WindowExt, 1)] = true
+	}
+	return result
+}
+
+//expireWindowTracking deletes batch window tracking folders (manifest, processed window record) once late arrival horizon has passed window end time,
+//data file arriving afterwards is routed to catch-up window without processed record lookup
+func (s *service) expireWindowTracking(ctx context.Context, objects []storage.Object) {
+	horizon := s.config.LateArrivalHorizon()
+	for _, object := range objects {
+		if !object.IsDir() {
+			continue
+		}
+		endTime, err := trackingEndTime(object.Name())
+		if err != nil || time.Since(*endTime) < horizon {
+			continue
+		}
+		if err = s.fs.Delete(ctx, object.URL()); err != nil && shared.IsDebugLoggingLevel() {
+			shared.LogF("failed to delete expired window tracking %v: %v\n", object.URL(), err)
+		}
+	}
+}
+
+//trackingEndTime returns window end time for window tracking folder, folder name uses dest_hash_endTime format
+func trackingEndTime(name string) (*time.Time, error) {
+	fragments := strings.Split(strings.Trim(name, "/"), "_")
+	if len(fragments) < 3 {
+		return nil, fmt.Errorf("invalid window tracking folder: %v", name)
+	}
+	if _, err := toolbox.ToInt(fragments[len(fragments)-2]); err != nil {
+		return nil, fmt.Errorf("invalid window tracking folder hash: %v", name)
+	}
+	unixTimestamp, err := toolbox.ToInt(fragments[len(fragments)-1])
+	if err != nil {
+		return nil, err
+	}
+	ts := time.Unix(int64(unixTimestamp), 0)
+	return &ts, nil
+}
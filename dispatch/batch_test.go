@@ -1,8 +1,17 @@
 package dispatch
 
 import (
+	"context"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_URLToWindowEndTime(t *testing.T) {
@@ -43,3 +52,37 @@ func Test_URLToWindowEndTime(t *testing.T) {
 	}
 
 }
+
+func TestService_ExpireWindowTracking(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/dispatch/tracking/tasks"
+	now := time.Now()
+	expired := fmt.Sprintf("mydataset.my_table_123_%v", now.Add(-2*time.Hour).Unix())
+	recent := fmt.Sprintf("mydataset.my_table_123_%v", now.Add(-time.Minute).Unix())
+	other := "proj:p1:US"
+	for _, folder := range []string{expired, recent, other} {
+		if !assert.Nil(t, fs.Upload(ctx, url.Join(baseURL, folder, shared.ProcessedFile), file.DefaultFileOsMode, strings.NewReader("{}"))) {
+			return
+		}
+	}
+	srv := &service{config: &Config{Config: base.Config{LateArrivalHorizonInSec: 3600}}, fs: fs}
+	objects, err := fs.List(ctx, baseURL)
+	if !assert.Nil(t, err) {
+		return
+	}
+	srv.expireWindowTracking(ctx, objects)
+	var useCases = []struct {
+		description string
+		folder      string
+		expect      bool
+	}{
+		{description: "window past late arrival horizon", folder: expired, expect: false},
+		{description: "window within late arrival horizon", folder: recent, expect: true},
+		{description: "non window folder", folder: other, expect: true},
+	}
+	for _, useCase := range useCases {
+		exists, _ := fs.Exists(ctx, url.Join(baseURL, useCase.folder, shared.ProcessedFile))
+		assert.Equal(t, useCase.expect, exists, useCase.description)
+	}
+}
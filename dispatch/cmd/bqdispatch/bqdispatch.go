@@ -0,0 +1,13 @@
+package main
+
+import (
+	"github.com/viant/bqtail/dispatch/cmd"
+	"os"
+)
+
+//Version app version
+var Version string
+
+func main() {
+	cmd.RunClient(Version, os.Args[1:])
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"github.com/jessevdk/go-flags"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afsc/gs"
+	"github.com/viant/bqtail/auth"
+	"github.com/viant/bqtail/dispatch"
+	"github.com/viant/bqtail/dispatch/lease"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/toolbox"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+//RunClient run client
+func RunClient(Version string, args []string) {
+	options := &Options{}
+	parser := flags.NewParser(options, flags.Default)
+	parser.Usage = "[OPTIONS] [" + ServeCommand + "]"
+	commands, err := parser.ParseArgs(args)
+	if isHelOption(args) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if options.Version {
+		shared.LogF("BqDispatch: Version: %v\n", Version)
+		return
+	}
+	options.Init()
+	if err = options.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	//application default credentials are used unless OAuth client or gsutil auth is requested
+	if options.Client != "" || toolbox.AsBoolean(os.Getenv("GCLOUD_AUTH")) {
+		client, err := auth.ClientFromURL(options.ClientURL())
+		if err != nil {
+			log.Fatal(err)
+		}
+		useGsUtilAuth := toolbox.AsBoolean(os.Getenv("GCLOUD_AUTH"))
+		setDefaultAuth(auth.New(client, useGsUtilAuth, options.ProjectID, auth.Scopes...))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service, err := newService(ctx, options.ConfigURL)
+	if err != nil {
+		log.Fatal(errors.Wrapf(err, "failed to create dispatch service with: %v", options.ConfigURL))
+	}
+	if len(commands) == 0 || commands[0] != ServeCommand {
+		toolbox.DumpIndent(service.Dispatch(ctx), true)
+		return
+	}
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+		sig := <-signals
+		shared.LogF("received %v, shutting down\n", sig)
+		cancel()
+	}()
+	config := service.Config()
+	leaseService := lease.New(afs.New(), config.LeaseURL, options.Holder, config.LeaseTTL())
+	server := dispatch.NewServer(service, leaseService, options.Holder, options.Addr)
+	if err = server.Serve(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newService(ctx context.Context, configURL string) (dispatch.Service, error) {
+	if configURL == "" {
+		return dispatch.Singleton(ctx)
+	}
+	config, err := dispatch.NewConfigFromURL(ctx, configURL)
+	if err != nil {
+		return nil, err
+	}
+	return dispatch.New(ctx, config)
+}
+
+func setDefaultAuth(authService auth.Service) {
+	auth.DefaultHTTPClientProvider = authService.AuthHTTPClient
+	auth.DefaultProjectProvider = authService.ProjectID
+	gs.DefaultHTTPClientProvider = authService.AuthHTTPClient
+	gs.DefaultProjectProvider = authService.ProjectID
+}
+
+func isHelOption(args []string) bool {
+	for _, arg := range args {
+		if arg == "-h" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/shared"
+	"os"
+)
+
+//ServeCommand runs dispatch daemon
+const ServeCommand = "serve"
+
+//portEnvKey Cloud Run port env key
+const portEnvKey = "PORT"
+
+//Options represents bqdispatch options
+type Options struct {
+	ConfigURL string `short:"c" long:"cfg" description:"Serverless BqDispatch config URL, CONFIG env is used if empty"`
+	Addr      string `short:"l" long:"listen" description:"daemon listen address, :${PORT} or :8080 by default"`
+	Holder    string `short:"i" long:"id" description:"replica lease holder ID, hostname-pid by default"`
+	ProjectID string `short:"p" long:"project" description:"Google Cloud Project"`
+	Client    string `short:"a" long:"aclient" description:"GCP OAuth client url"`
+	Version   bool   `short:"v" long:"version" description:"bqdispatch version"`
+}
+
+//Init initialises options
+func (o *Options) Init() {
+	if o.Addr == "" {
+		port := os.Getenv(portEnvKey)
+		if port == "" {
+			port = "8080"
+		}
+		o.Addr = ":" + port
+	}
+	if o.Holder == "" {
+		hostname, _ := os.Hostname()
+		o.Holder = fmt.Sprintf("%v-%v", hostname, os.Getpid())
+	}
+}
+
+//Validate checks if options are valid
+func (o *Options) Validate() error {
+	if o.ConfigURL == "" && os.Getenv(shared.ConfigEnvKey) == "" {
+		return errors.Errorf("configURL was empty and env.%v was not set", shared.ConfigEnvKey)
+	}
+	return nil
+}
+
+//ClientURL returns clientURL
+func (o *Options) ClientURL() string {
+	if o.Client == "" {
+		o.Client = shared.ClientSecretURL
+	}
+	return o.Client
+}
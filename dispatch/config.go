@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/shared"
 	"os"
 	"strings"
 )
@@ -21,6 +23,63 @@ type Config struct {
 	TimeToLiveInMin   int
 	MaxConcurrentSQL  int
 	MaxConcurrentLoad int
+	//MaxConcurrentSQLPerDest and MaxConcurrentLoadPerDest default destination table limits, used if neither rule nor Limits define one
+	MaxConcurrentSQLPerDest  int             `json:",omitempty"`
+	MaxConcurrentLoadPerDest int             `json:",omitempty"`
+	Limits                   []*config.Limit `json:",omitempty"`
+	//PriorityAgingInSec waiting time after which throttled job or window priority is raised by one, so that low priority work can not starve
+	PriorityAgingInSec int `json:",omitempty"`
+	//EventDriven flag indicating that BigQuery job completion events are dispatched by BqDispatchEvent, polling is used as reconciliation sweep
+	EventDriven bool `json:",omitempty"`
+	//JobEventSubscription Pub/Sub subscription with BigQuery job completion audit log events consumed by Consume
+	JobEventSubscription string `json:",omitempty"`
+	//LeaseURL dispatch daemon leader lease object URL, only lease holder replica dispatches (JournalURL/dispatch.lease by default)
+	LeaseURL string `json:",omitempty"`
+	//LeaseTTLInSec leader lease time to live, lease is renewed every third of it
+	LeaseTTLInSec int `json:",omitempty"`
+	//JobListCursorURL per project BigQuery job listing cursor location (JournalURL/cursor by default)
+	JobListCursorURL string `json:",omitempty"`
+	//MaxJobListWorkers max concurrent BigQuery job list calls per project
+	MaxJobListWorkers int `json:",omitempty"`
+}
+
+//JobListWorkers returns max concurrent BigQuery job list calls per project
+func (c *Config) JobListWorkers() int {
+	if c.MaxJobListWorkers == 0 {
+		return defaultJobListWorkers
+	}
+	return c.MaxJobListWorkers
+}
+
+//LeaseTTL returns leader lease time to live
+func (c *Config) LeaseTTL() time.Duration {
+	if c.LeaseTTLInSec == 0 {
+		return defaultLeaseTTL
+	}
+	return time.Duration(c.LeaseTTLInSec) * time.Second
+}
+
+//DestLimit returns the first matching destination limit or default per destination limit
+func (c *Config) DestLimit(destKey string) *config.Limit {
+	for _, limit := range c.Limits {
+		if limit.Matches(destKey) {
+			return limit
+		}
+	}
+	return &config.Limit{MaxConcurrentLoad: c.MaxConcurrentLoadPerDest, MaxConcurrentSQL: c.MaxConcurrentSQLPerDest}
+}
+
+//PriorityAging returns priority aging duration
+func (c *Config) PriorityAging() time.Duration {
+	if c.PriorityAgingInSec == 0 {
+		return defaultPriorityAging
+	}
+	return time.Duration(c.PriorityAgingInSec) * time.Second
+}
+
+//IsEventDriven returns true if BigQuery job completion events are consumed
+func (c *Config) IsEventDriven() bool {
+	return c.EventDriven || c.JobEventSubscription != ""
 }
 
 //TimeToLive returns time to live
@@ -40,6 +99,12 @@ func (c *Config) Init(ctx context.Context, fs afs.Service) error {
 	if c.TimeToLiveInMin == 0 {
 		c.TimeToLiveInMin = 1
 	}
+	if c.LeaseURL == "" {
+		c.LeaseURL = url.Join(c.JournalURL, shared.LeaseFile)
+	}
+	if c.JobListCursorURL == "" {
+		c.JobListCursorURL = url.Join(c.JournalURL, shared.CursorLocation)
+	}
 	return c.Ruleset.Init(ctx, fs, c.ProjectID)
 }
 
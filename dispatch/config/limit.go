@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage/activity"
+	"strings"
+)
+
+//Limit represents destination table concurrency limit
+type Limit struct {
+	Dest              string `json:",omitempty" description:"destination table, or table prefix ending with *"`
+	MaxConcurrentLoad int    `json:",omitempty"`
+	MaxConcurrentSQL  int    `json:",omitempty"`
+	Priority          int    `json:",omitempty" description:"dispatch priority, used if job or window rule does not define one"`
+}
+
+//Matches returns true if limit matches destination key
+func (l *Limit) Matches(destKey string) bool {
+	dest := DestKey(l.Dest)
+	if strings.HasSuffix(dest, "*") {
+		return strings.HasPrefix(destKey, strings.TrimSuffix(dest, "*"))
+	}
+	return dest == destKey
+}
+
+//IsEmpty returns true if no concurrency limit is set
+func (l *Limit) IsEmpty() bool {
+	return l.MaxConcurrentLoad == 0 && l.MaxConcurrentSQL == 0
+}
+
+//Allows returns true if another action job can be dispatched with supplied active jobs count
+func (l *Limit) Allows(action string, active int) bool {
+	max := 0
+	switch action {
+	case shared.ActionQuery:
+		max = l.MaxConcurrentSQL
+	case shared.ActionLoad:
+		max = l.MaxConcurrentLoad
+	}
+	return max == 0 || active < max
+}
+
+//DestKey returns destination key, encoded the same way as destination table in job ID
+func DestKey(dest string) string {
+	return activity.Decode(dest)
+}
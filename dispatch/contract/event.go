@@ -0,0 +1,106 @@
+package contract
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/shared"
+	"strings"
+)
+
+//Message represents Pub/Sub message carrying BigQuery audit log entry
+type Message struct {
+	Data []byte `json:"data"`
+}
+
+//JobEvent represents BigQuery job completion event
+type JobEvent struct {
+	ProjectID string
+	Region    string
+	JobID     string
+	State     string
+}
+
+//IsDone returns true if job is done
+func (e *JobEvent) IsDone() bool {
+	return strings.ToUpper(e.State) == shared.DoneState
+}
+
+type jobName struct {
+	ProjectID string `json:"projectId"`
+	JobID     string `json:"jobId"`
+	Location  string `json:"location"`
+}
+
+//auditLogEntry represents BigQuery audit log entry exported by log sink, both legacy AuditData and BigQueryAuditMetadata formats are supported
+type auditLogEntry struct {
+	ProtoPayload struct {
+		ServiceData *struct {
+			JobCompletedEvent *struct {
+				Job struct {
+					JobName   jobName `json:"jobName"`
+					JobStatus struct {
+						State string `json:"state"`
+					} `json:"jobStatus"`
+				} `json:"job"`
+			} `json:"jobCompletedEvent"`
+		} `json:"serviceData"`
+		Metadata *struct {
+			JobChange *struct {
+				After string `json:"after"`
+				Job   struct {
+					JobName   string `json:"jobName"`
+					JobStatus struct {
+						JobState string `json:"jobState"`
+					} `json:"jobStatus"`
+				} `json:"job"`
+			} `json:"jobChange"`
+		} `json:"metadata"`
+	} `json:"protoPayload"`
+	Resource struct {
+		Labels struct {
+			ProjectID string `json:"project_id"`
+			Location  string `json:"location"`
+		} `json:"labels"`
+	} `json:"resource"`
+}
+
+//NewJobEvent creates a job event from BigQuery audit log entry
+func NewJobEvent(data []byte) (*JobEvent, error) {
+	entry := &auditLogEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode audit log entry: %s", data)
+	}
+	event := &JobEvent{
+		ProjectID: entry.Resource.Labels.ProjectID,
+		Region:    entry.Resource.Labels.Location,
+	}
+	payload := entry.ProtoPayload
+	switch {
+	case payload.ServiceData != nil && payload.ServiceData.JobCompletedEvent != nil:
+		job := payload.ServiceData.JobCompletedEvent.Job
+		event.JobID = job.JobName.JobID
+		event.State = job.JobStatus.State
+		if job.JobName.ProjectID != "" {
+			event.ProjectID = job.JobName.ProjectID
+		}
+		if job.JobName.Location != "" {
+			event.Region = job.JobName.Location
+		}
+	case payload.Metadata != nil && payload.Metadata.JobChange != nil:
+		change := payload.Metadata.JobChange
+		event.State = change.After
+		if change.Job.JobStatus.JobState != "" {
+			event.State = change.Job.JobStatus.JobState
+		}
+		//jobName format: projects/{project}/jobs/{jobID}
+		elements := strings.Split(change.Job.JobName, "/")
+		if len(elements) == 4 {
+			event.ProjectID = elements[1]
+			event.JobID = elements[3]
+		}
+	}
+	if event.JobID == "" {
+		return nil, errors.Errorf("job ID was empty: %s", data)
+	}
+	return event, nil
+}
@@ -0,0 +1,51 @@
+package contract
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewJobEvent(t *testing.T) {
+	var useCases = []struct {
+		description string
+		data        string
+		expect      *JobEvent
+		hasError    bool
+	}{
+		{
+			description: "audit data job completed event",
+			data: `{"protoPayload": {"methodName": "jobservice.jobcompleted", "serviceData": {"jobCompletedEvent": {"eventName": "load_job_completed",
+  "job": {"jobName": {"projectId": "p1", "jobId": "mydataset_mytable--123_00002_load--dispatch", "location": "US"}, "jobStatus": {"state": "DONE"}}}}},
+  "resource": {"labels": {"project_id": "p1"}}}`,
+			expect: &JobEvent{ProjectID: "p1", Region: "US", JobID: "mydataset_mytable--123_00002_load--dispatch", State: "DONE"},
+		},
+		{
+			description: "audit metadata job change",
+			data: `{"protoPayload": {"metadata": {"jobChange": {"after": "DONE", "job": {"jobName": "projects/p2/jobs/mydataset_mytable--123_00003_query--dispatch", "jobStatus": {"jobState": "DONE"}}}}},
+  "resource": {"labels": {"project_id": "p2", "location": "EU"}}}`,
+			expect: &JobEvent{ProjectID: "p2", Region: "EU", JobID: "mydataset_mytable--123_00003_query--dispatch", State: "DONE"},
+		},
+		{
+			description: "not a job event",
+			data:        `{"protoPayload": {"methodName": "tableservice.insert"}}`,
+			hasError:    true,
+		},
+		{
+			description: "invalid JSON",
+			data:        `{"protoPayload`,
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		event, err := NewJobEvent([]byte(useCase.data))
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expect, event, useCase.description)
+		assert.True(t, event.IsDone(), useCase.description)
+	}
+}
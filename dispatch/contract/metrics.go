@@ -18,6 +18,19 @@ func (m Metrics) Count() int {
 	return m.QueryJobs + m.CopyJobs + m.LoadJobs + m.OtherJobs
 }
 
+//ActionCount returns metrics count for supplied action
+func (m Metrics) ActionCount(action string) int {
+	switch action {
+	case "query":
+		return m.QueryJobs
+	case "copy":
+		return m.CopyJobs
+	case "load", "reload":
+		return m.LoadJobs
+	}
+	return m.OtherJobs
+}
+
 //Update updates a metrics with job ID
 func (m *Metrics) Update(jobID string) *activity.Meta {
 	stageInfo := activity.Parse(jobID)
@@ -46,3 +59,11 @@ func (m *Metrics) Merge(metrics *Metrics) {
 	m.LoadJobs += metrics.LoadJobs
 	m.OtherJobs += metrics.OtherJobs
 }
+
+func (m *Metrics) clone() *Metrics {
+	if m == nil {
+		return nil
+	}
+	result := *m
+	return &result
+}
@@ -21,6 +21,10 @@ type Performance struct {
 	Dispatched *Metrics `json:",omitempty"`
 	Throttled  *Metrics `json:",omitempty"`
 	NoFound    int      `json:",omitempty"`
+	//ListCalls BigQuery job list calls, ListSaved calls saved by incremental listing, ListErrors failed list calls
+	ListCalls  int `json:",omitempty"`
+	ListSaved  int `json:",omitempty"`
+	ListErrors int `json:",omitempty"`
 }
 
 //Merge merges performance
@@ -32,11 +36,24 @@ func (p *Performance) Merge(perf *Performance) {
 		p.Pending = perf.Pending
 	}
 	p.NoFound += perf.NoFound
+	p.ListCalls += perf.ListCalls
+	p.ListSaved += perf.ListSaved
+	p.ListErrors += perf.ListErrors
 	p.Count += perf.Count
 	p.Dispatched.Merge(perf.Dispatched)
 	p.Throttled.Merge(perf.Throttled)
 }
 
+//Clone returns performance copy
+func (p *Performance) Clone() *Performance {
+	result := *p
+	result.Running = p.Running.clone()
+	result.Pending = p.Pending.clone()
+	result.Dispatched = p.Dispatched.clone()
+	result.Throttled = p.Throttled.clone()
+	return &result
+}
+
 //ActiveQueryCount returns active query count
 func (p Performance) ActiveQueryCount() int {
 	return p.Pending.QueryJobs +
@@ -55,6 +72,11 @@ func (p Performance) ActiveLoadCount() int {
 	return result
 }
 
+//ActiveCount returns pending and running jobs count for supplied action
+func (p Performance) ActiveCount(action string) int {
+	return p.Pending.ActionCount(action) + p.Running.ActionCount(action)
+}
+
 //AddEvent adds running, pending metrics
 func (p *Performance) AddEvent(state string, jobID string) {
 	atomic.AddUint32(&p.Count, 1)
@@ -33,6 +33,17 @@ func (r *Response) Merge(performance *Performance) {
 	r.Performance[performance.ProjectID].Merge(performance)
 }
 
+//PerformanceSnapshot returns copy of performance, it can be called while dispatch is in progress
+func (r *Response) PerformanceSnapshot() ProjectPerformance {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	var result = make(ProjectPerformance, len(r.Performance))
+	for key, performance := range r.Performance {
+		result[key] = performance.Clone()
+	}
+	return result
+}
+
 //HasBatch returns true if it has a bach
 func (r *Response) HasBatch(URL string) bool {
 	r.Jobs.mux.Lock()
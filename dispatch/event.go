@@ -0,0 +1,198 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/dispatch/contract"
+	"github.com/viant/bqtail/dispatch/project"
+	"github.com/viant/bqtail/stage/activity"
+	gpubsub "google.golang.org/api/pubsub/v1"
+	"time"
+)
+
+const maxEventMessages = 100
+
+//reconcileDelay defines min task age for polling reconciliation sweep in event driven mode, younger tasks are left to job completion events
+var reconcileDelay = time.Minute
+
+//DispatchEvent dispatches BigQuery job completion audit log event
+func (s *service) DispatchEvent(ctx context.Context, data []byte) *contract.Response {
+	response := contract.NewResponse()
+	defer response.SetTimeTaken(response.Started)
+	event, err := contract.NewJobEvent(data)
+	if err == nil {
+		err = s.dispatchJobEvent(ctx, event, response)
+	}
+	if err != nil {
+		response.SetIfError(err)
+	}
+	return response
+}
+
+//Consume pulls and dispatches BigQuery job completion events from JobEventSubscription till time to live or graceful stop,
+//event that failed to dispatch is released for redelivery, only malformed event is acknowledged with an error
+func (s *service) Consume(ctx context.Context) *contract.Response {
+	response := contract.NewResponse()
+	defer response.SetTimeTaken(response.Started)
+	if s.pubsub == nil {
+		response.SetIfError(errors.New("JobEventSubscription was empty"))
+		return response
+	}
+	ctx, cancelFunc := context.WithTimeout(ctx, s.config.TimeToLive())
+	defer cancelFunc()
+	for ctx.Err() == nil {
+		messages, err := s.pubsub.Pull(ctx, s.config.JobEventSubscription, maxEventMessages)
+		if err != nil {
+			if !IsContextError(err) {
+				response.SetIfError(err)
+			}
+			break
+		}
+		if len(messages) > 0 {
+			response.Cycles++
+			s.consumeMessages(ctx, messages, response)
+		}
+		select {
+		case <-stopped(ctx):
+			return response
+		case <-ctx.Done():
+		case <-time.After(s.pullWaitTime(len(messages))):
+		}
+	}
+	return response
+}
+
+//pullWaitTime returns wait time before the next pull, the next pull follows immediately after non empty one
+func (s *service) pullWaitTime(pulled int) time.Duration {
+	if pulled > 0 {
+		return 0
+	}
+	return thinkTime
+}
+
+func (s *service) consumeMessages(ctx context.Context, messages []*gpubsub.ReceivedMessage, response *contract.Response) {
+	var ackIDs = make([]string, 0)
+	var nackIDs = make([]string, 0)
+	for _, message := range messages {
+		event, err := decodeJobEvent(message.Message.Data)
+		if err != nil {
+			response.AddError(err)
+			ackIDs = append(ackIDs, message.AckId)
+			continue
+		}
+		if err = s.dispatchJobEvent(ctx, event, response); err != nil {
+			response.AddError(err)
+			nackIDs = append(nackIDs, message.AckId)
+			continue
+		}
+		ackIDs = append(ackIDs, message.AckId)
+	}
+	if err := s.pubsub.Acknowledge(ctx, s.config.JobEventSubscription, ackIDs); err != nil {
+		response.AddError(err)
+	}
+	if err := s.pubsub.Nack(ctx, s.config.JobEventSubscription, nackIDs); err != nil {
+		response.AddError(err)
+	}
+}
+
+func decodeJobEvent(encoded string) (*contract.JobEvent, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode message: %s", encoded)
+	}
+	return contract.NewJobEvent(data)
+}
+
+//dispatchJobEvent moves completed job task file to trigger bucket, event job state is used, so no BigQuery job listing is needed
+func (s *service) dispatchJobEvent(ctx context.Context, event *contract.JobEvent, response *contract.Response) error {
+	if !event.IsDone() {
+		return nil
+	}
+	if info := activity.Parse(event.JobID); !info.Async {
+		return nil //not a job with post actions
+	}
+	regionProject, URL, err := s.findTask(ctx, event)
+	if err != nil || URL == "" {
+		return err
+	}
+	events, object, err := s.taskEvents(ctx, regionProject, URL)
+	if err != nil || object == nil {
+		return err
+	}
+	info := activity.Parse(event.JobID)
+	candidate := &candidate{jobID: event.JobID, destKey: info.DestTable, state: event.State, object: object, priority: info.Priority, since: time.Now()}
+	if candidate.priority == 0 {
+		candidate.priority = s.destLimit(ctx, candidate.destKey, URL).Priority
+	}
+	stageInfo := events.AddDispatch(event.JobID)
+	destPerf := events.Destination(candidate.destKey)
+	if !s.canNotify(stageInfo.Action, events.Performance) || !s.canNotifyDest(ctx, stageInfo.Action, candidate, destPerf) ||
+		s.waiting.outranks(candidate, s.hasGlobalLimit(stageInfo.Action), 2*reconcileDelay, s.config.PriorityAging()) {
+		//throttled job or job outranked by waiting job is left to reconciliation sweep
+		events.AddThrottled(event.JobID)
+		destPerf.Throttled.Update(event.JobID)
+		s.waiting.put(candidate)
+		response.Merge(events.Performance)
+		return nil
+	}
+	destPerf.AddDispatch(event.JobID)
+	job := contract.NewJob(event.JobID, URL, event.State)
+	if err = s.notify(ctx, job, events); err != nil {
+		if IsNotFound(err) { //already dispatched by reconciliation sweep
+			return nil
+		}
+		return err
+	}
+	response.Jobs.Add(job)
+	response.Merge(events.Performance)
+	return nil
+}
+
+//taskEvents returns region project events with other outstanding tasks counted as running jobs, so that dispatch limits apply to event dispatched job,
+//it also returns the job task object, or nil if task was already dispatched
+func (s *service) taskEvents(ctx context.Context, regionProject, URL string) (*project.Events, storage.Object, error) {
+	events := project.New(regionProject)
+	parentURL, _ := url.Split(URL, file.Scheme)
+	objects, err := s.fs.List(ctx, parentURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var task storage.Object
+	for _, object := range objects {
+		if object.IsDir() || isBatchFile(object.Name()) {
+			continue
+		}
+		if object.URL() == URL {
+			task = object
+			continue
+		}
+		s.addRunningTask(events, object)
+	}
+	return events, task, nil
+}
+
+//findTask returns job task file URL with its region project, or empty URL if task file does not exist,
+//task URL is derived from the job ID, project task is checked first, then default project root task
+func (s *service) findTask(ctx context.Context, event *contract.JobEvent) (string, string, error) {
+	info := activity.Parse(event.JobID)
+	info.ProjectID = event.ProjectID
+	info.Region = event.Region
+	URL := url.Join(s.config.AsyncTaskURL, info.TaskFilename())
+	exists, err := s.fs.Exists(ctx, URL)
+	if err != nil || exists {
+		return event.ProjectID + ":" + event.Region, URL, err
+	}
+	if event.ProjectID != s.config.ProjectID {
+		return "", "", nil
+	}
+	info.ProjectID = ""
+	URL = url.Join(s.config.AsyncTaskURL, info.TaskFilename())
+	if exists, err = s.fs.Exists(ctx, URL); err != nil || !exists {
+		return "", "", err
+	}
+	return s.config.ProjectID, URL, nil
+}
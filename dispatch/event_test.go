@@ -0,0 +1,327 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/dispatch/contract"
+	"github.com/viant/bqtail/service/pubsub"
+	gpubsub "google.golang.org/api/pubsub/v1"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_FindTask(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/dispatch/event/tasks"
+	tasks := []string{
+		"proj:p1:US/mydataset_mytable--123_00002_load--dispatch",
+		"proj:p2:EU/mydataset_mytable--124_00002_load--dispatch",
+		"mydataset_other--125_00003_query--dispatch",
+		"proj:p1:US/mydataset.mytable--126_00002_load--dispatch",
+	}
+	for _, task := range tasks {
+		if !assert.Nil(t, fs.Upload(ctx, baseURL+"/"+task, file.DefaultFileOsMode, strings.NewReader("{}"))) {
+			return
+		}
+	}
+	srv := &service{config: &Config{Config: base.Config{ProjectID: "p1", AsyncTaskURL: baseURL}}, fs: fs}
+
+	var useCases = []struct {
+		description   string
+		event         *contract.JobEvent
+		expectProject string
+		expectURL     string
+	}{
+		{
+			description:   "project task",
+			event:         &contract.JobEvent{ProjectID: "p1", Region: "US", JobID: "mydataset_mytable--123_00002_load--dispatch"},
+			expectProject: "p1:US",
+			expectURL:     baseURL + "/" + tasks[0],
+		},
+		{
+			description:   "other project task",
+			event:         &contract.JobEvent{ProjectID: "p2", Region: "EU", JobID: "mydataset_mytable--124_00002_load--dispatch"},
+			expectProject: "p2:EU",
+			expectURL:     baseURL + "/" + tasks[1],
+		},
+		{
+			description:   "default project task",
+			event:         &contract.JobEvent{ProjectID: "p1", JobID: "mydataset_other--125_00003_query--dispatch"},
+			expectProject: "p1",
+			expectURL:     baseURL + "/" + tasks[2],
+		},
+		{
+			description: "task of other project",
+			event:       &contract.JobEvent{ProjectID: "p1", JobID: "mydataset_mytable--124_00002_load--dispatch"},
+		},
+		{
+			description: "task with not encoded job filename is left to reconciliation sweep",
+			event:       &contract.JobEvent{ProjectID: "p1", Region: "US", JobID: "mydataset_mytable--126_00002_load--dispatch"},
+		},
+		{
+			description: "missing task",
+			event:       &contract.JobEvent{ProjectID: "p1", JobID: "mydataset_mytable--999_00002_load--dispatch"},
+		},
+	}
+	for _, useCase := range useCases {
+		regionProject, URL, err := srv.findTask(ctx, useCase.event)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expectProject, regionProject, useCase.description)
+		assert.Equal(t, useCase.expectURL, URL, useCase.description)
+	}
+}
+
+type fakePubsub struct {
+	pubsub.Service
+	messages []*gpubsub.ReceivedMessage
+	acked    []string
+	nacked   []string
+}
+
+func (f *fakePubsub) Pull(ctx context.Context, subscription string, maxMessages int) ([]*gpubsub.ReceivedMessage, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	messages := f.messages
+	f.messages = nil
+	return messages, nil
+}
+
+func (f *fakePubsub) Acknowledge(ctx context.Context, subscription string, ackIDs []string) error {
+	f.acked = append(f.acked, ackIDs...)
+	return nil
+}
+
+func (f *fakePubsub) Nack(ctx context.Context, subscription string, ackIDs []string) error {
+	f.nacked = append(f.nacked, ackIDs...)
+	return nil
+}
+
+type failingMoveFs struct {
+	afs.Service
+}
+
+func (f *failingMoveFs) Move(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	return errors.New("move failed")
+}
+
+//movingFs removes moved source, trigger bucket is not accessible in test
+type movingFs struct {
+	afs.Service
+}
+
+func (f *movingFs) Move(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	return f.Service.Delete(ctx, sourceURL)
+}
+
+func TestService_Consume_Acknowledge(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/dispatch/consume/ack/tasks"
+	if !assert.Nil(t, fs.Upload(ctx, baseURL+"/proj:p1:US/mydataset_mytable--123_00002_load--dispatch", file.DefaultFileOsMode, strings.NewReader("{}"))) {
+		return
+	}
+	jobEvent := func(jobID string) string {
+		event := fmt.Sprintf(`{"protoPayload": {"serviceData": {"jobCompletedEvent": {"job": {"jobName": {"projectId": "p1", "location": "US", "jobId": "%v"}, "jobStatus": {"state": "DONE"}}}}}}`, jobID)
+		return base64.StdEncoding.EncodeToString([]byte(event))
+	}
+	pubsubService := &fakePubsub{messages: []*gpubsub.ReceivedMessage{
+		{AckId: "malformed", Message: &gpubsub.PubsubMessage{Data: "%%%"}},
+		{AckId: "failed", Message: &gpubsub.PubsubMessage{Data: jobEvent("mydataset_mytable--123_00002_load--dispatch")}},
+		{AckId: "dispatched", Message: &gpubsub.PubsubMessage{Data: jobEvent("mydataset_mytable--999_00002_load--dispatch")}},
+	}}
+	srv := &service{
+		config: &Config{Config: base.Config{ProjectID: "p1", AsyncTaskURL: baseURL}, JobEventSubscription: "bq-job-events", TimeToLiveInMin: 1},
+		fs:     &failingMoveFs{Service: fs},
+		pubsub: pubsubService,
+		limits: newDestLimits(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	response := srv.Consume(ctx)
+	assert.EqualValues(t, 1, response.Cycles)
+	assert.EqualValues(t, 2, len(response.Errors))
+	assert.EqualValues(t, []string{"malformed", "dispatched"}, pubsubService.acked)
+	assert.EqualValues(t, []string{"failed"}, pubsubService.nacked)
+}
+
+//TestService_Consume requires Pub/Sub emulator, i.e: gcloud beta emulators pubsub start --host-port=localhost:8085
+func TestService_Consume(t *testing.T) {
+	if os.Getenv(pubsub.EmulatorHostEnvKey) == "" {
+		t.Skipf("%v was not set", pubsub.EmulatorHostEnvKey)
+	}
+	ctx := context.Background()
+	projectID := "bqtail-e2e"
+	suffix := fmt.Sprintf("%v", time.Now().UnixNano())
+	topic := fmt.Sprintf("projects/%v/topics/bq-job-%v", projectID, suffix)
+	subscription := fmt.Sprintf("projects/%v/subscriptions/bq-job-%v", projectID, suffix)
+	client, err := gpubsub.NewService(ctx, pubsub.EmulatorOptions()...)
+	if !assert.Nil(t, err) {
+		return
+	}
+	projects := gpubsub.NewProjectsService(client)
+	if _, err = projects.Topics.Create(topic, &gpubsub.Topic{}).Do(); !assert.Nil(t, err) {
+		return
+	}
+	if _, err = projects.Subscriptions.Create(subscription, &gpubsub.Subscription{Topic: topic}).Do(); !assert.Nil(t, err) {
+		return
+	}
+	event := `{"protoPayload": {"serviceData": {"jobCompletedEvent": {"job": {"jobName": {"projectId": "p1", "jobId": "mydataset_mytable--999_00002_load--dispatch"}, "jobStatus": {"state": "DONE"}}}}}}`
+	_, err = projects.Topics.Publish(topic, &gpubsub.PublishRequest{Messages: []*gpubsub.PubsubMessage{
+		{Data: base64.StdEncoding.EncodeToString([]byte(event))},
+	}}).Do()
+	if !assert.Nil(t, err) {
+		return
+	}
+	pubsubService, err := pubsub.New(ctx, projectID, pubsub.EmulatorOptions()...)
+	if !assert.Nil(t, err) {
+		return
+	}
+	srv := &service{
+		config: &Config{Config: base.Config{ProjectID: "p1", AsyncTaskURL: "mem://localhost/dispatch/consume/tasks"}, JobEventSubscription: subscription, TimeToLiveInMin: 1},
+		fs:     afs.New(),
+		pubsub: pubsubService,
+		limits: newDestLimits(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	response := srv.Consume(ctx)
+	assert.Equal(t, "", response.Error)
+	assert.True(t, response.Cycles > 0)
+}
+
+func TestService_IsReconcileDue(t *testing.T) {
+	var useCases = []struct {
+		description string
+		config      *Config
+		reconciled  time.Time
+		expect      bool
+	}{
+		{
+			description: "polling mode",
+			config:      &Config{},
+			reconciled:  time.Now(),
+			expect:      true,
+		},
+		{
+			description: "event driven recent sweep",
+			config:      &Config{EventDriven: true},
+			reconciled:  time.Now(),
+			expect:      false,
+		},
+		{
+			description: "event driven sweep due",
+			config:      &Config{EventDriven: true},
+			reconciled:  time.Now().Add(-2 * reconcileDelay),
+			expect:      true,
+		},
+	}
+	for _, useCase := range useCases {
+		srv := &service{config: useCase.config, reconciled: useCase.reconciled}
+		assert.EqualValues(t, useCase.expect, srv.isReconcileDue(), useCase.description)
+	}
+}
+
+func TestService_DispatchJobEvent_Throttle(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	jobID := "mydataset_mytable--123_00002_load--dispatch"
+	var useCases = []struct {
+		description string
+		config      *Config
+		running     []string
+		waiting     *candidate
+		expectMoved bool
+	}{
+		{
+			description: "no limit",
+			config:      &Config{},
+			running:     []string{"mydataset_mytable--124_00002_load--dispatch"},
+			expectMoved: true,
+		},
+		{
+			description: "load limit not reached",
+			config:      &Config{MaxConcurrentLoad: 3},
+			running:     []string{"mydataset_mytable--124_00002_load--dispatch", "mydataset_other--125_00002_query--dispatch"},
+			expectMoved: true,
+		},
+		{
+			description: "load limit reached",
+			config:      &Config{MaxConcurrentLoad: 2},
+			running:     []string{"mydataset_mytable--124_00002_load--dispatch"},
+		},
+		{
+			description: "sql limit reached by other action",
+			config:      &Config{MaxConcurrentSQL: 2},
+			running:     []string{"mydataset_mytable--124_00002_query--dispatch"},
+			expectMoved: true,
+		},
+		{
+			description: "destination limit reached",
+			config:      &Config{MaxConcurrentLoadPerDest: 1},
+			running:     []string{"mydataset_mytable--124_00002_load--dispatch"},
+		},
+		{
+			description: "destination limit reached by other table",
+			config:      &Config{MaxConcurrentLoadPerDest: 1},
+			running:     []string{"mydataset_other--124_00002_load--dispatch"},
+			expectMoved: true,
+		},
+		{
+			description: "higher priority job waits for the destination",
+			config:      &Config{},
+			waiting:     &candidate{jobID: "mydataset_mytable--124_00002_load--dispatch", destKey: "mydataset_mytable", priority: 2, since: time.Now()},
+		},
+		{
+			description: "higher priority job waits for other destination",
+			config:      &Config{},
+			waiting:     &candidate{jobID: "mydataset_other--124_00002_load--dispatch", destKey: "mydataset_other", priority: 2, since: time.Now()},
+			expectMoved: true,
+		},
+		{
+			description: "higher priority job waits for global load limit",
+			config:      &Config{MaxConcurrentLoad: 10},
+			waiting:     &candidate{jobID: "mydataset_other--124_00002_load--dispatch", destKey: "mydataset_other", priority: 2, since: time.Now()},
+		},
+	}
+	for i, useCase := range useCases {
+		baseURL := fmt.Sprintf("mem://localhost/dispatch/event/throttle%v/tasks", i)
+		for _, name := range append(useCase.running, jobID) {
+			if !assert.Nil(t, fs.Upload(ctx, baseURL+"/proj:p1:US/"+name, file.DefaultFileOsMode, strings.NewReader("{}")), useCase.description) {
+				return
+			}
+		}
+		useCase.config.Config = base.Config{ProjectID: "p1", AsyncTaskURL: baseURL}
+		srv := &service{config: useCase.config, fs: &movingFs{Service: fs}, limits: newDestLimits(), waiting: newWaitingJobs()}
+		if useCase.waiting != nil {
+			srv.waiting.put(useCase.waiting)
+		}
+		response := contract.NewResponse()
+		err := srv.dispatchJobEvent(ctx, &contract.JobEvent{ProjectID: "p1", Region: "US", JobID: jobID, State: "DONE"}, response)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		exists, _ := fs.Exists(ctx, baseURL+"/proj:p1:US/"+jobID)
+		assert.EqualValues(t, !useCase.expectMoved, exists, useCase.description)
+		if useCase.expectMoved {
+			assert.EqualValues(t, 1, len(response.Jobs.Jobs), useCase.description)
+			continue
+		}
+		assert.EqualValues(t, 0, len(response.Jobs.Jobs), useCase.description)
+		assert.EqualValues(t, 1, response.Performance["p1"].Throttled.LoadJobs, useCase.description)
+		_, waiting := srv.waiting.byID[jobID]
+		assert.True(t, waiting, useCase.description)
+	}
+}
@@ -0,0 +1,14 @@
+package lease
+
+import "time"
+
+//Lease represents leader lease record
+type Lease struct {
+	Holder string
+	Expiry time.Time
+}
+
+//IsExpired returns true if lease expired at supplied time
+func (l *Lease) IsExpired(now time.Time) bool {
+	return !now.Before(l.Expiry)
+}
@@ -0,0 +1,116 @@
+package lease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/bqtail/base"
+	"io/ioutil"
+	"time"
+)
+
+//Service represents storage object based leader lease service
+type Service interface {
+	//Acquire acquires or renews a lease, returns true if holder is the leader
+	Acquire(ctx context.Context) (bool, error)
+
+	//Release expires a lease held by the holder, so that other replica can take over without waiting for lease expiry
+	Release(ctx context.Context) error
+
+	//TTL returns lease time to live
+	TTL() time.Duration
+}
+
+type service struct {
+	fs     afs.Service
+	URL    string
+	holder string
+	ttl    time.Duration
+}
+
+//TTL returns lease time to live
+func (s *service) TTL() time.Duration {
+	return s.ttl
+}
+
+//Acquire acquires or renews a lease, lease update uses storage generation precondition, so that only one holder wins a race
+func (s *service) Acquire(ctx context.Context) (bool, error) {
+	lease, generation, err := s.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if lease != nil && lease.Holder != s.holder && !lease.IsExpired(now) {
+		return false, nil
+	}
+	err = s.upload(ctx, &Lease{Holder: s.holder, Expiry: now.Add(s.ttl)}, generation)
+	if base.IsPreConditionError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Release expires a lease held by the holder
+func (s *service) Release(ctx context.Context) error {
+	lease, generation, err := s.load(ctx)
+	if err != nil || lease == nil || lease.Holder != s.holder {
+		return err
+	}
+	err = s.upload(ctx, &Lease{Holder: s.holder, Expiry: time.Now()}, generation)
+	if base.IsPreConditionError(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *service) upload(ctx context.Context, lease *Lease, generation *option.Generation) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	var options = make([]storage.Option, 0)
+	if generation != nil {
+		options = append(options, generation)
+	}
+	if err = s.fs.Upload(ctx, s.URL, file.DefaultFileOsMode, bytes.NewReader(data), options...); err != nil {
+		return errors.Wrapf(err, "failed to upload lease: %v", s.URL)
+	}
+	return nil
+}
+
+//load returns lease with generation precondition or nil lease if it does not exists yet
+func (s *service) load(ctx context.Context) (*Lease, *option.Generation, error) {
+	if ok, _ := s.fs.Exists(ctx, s.URL, option.NewObjectKind(true)); !ok {
+		return nil, option.NewGeneration(true, 0), nil
+	}
+	object, err := s.fs.Object(ctx, s.URL, option.NewObjectKind(true))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get lease: %v", s.URL)
+	}
+	reader, err := s.fs.Download(ctx, object)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to download lease: %v", s.URL)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read lease: %v", s.URL)
+	}
+	lease := &Lease{}
+	if err = json.Unmarshal(data, lease); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to decode lease: %v", s.URL)
+	}
+	return lease, base.GenerationMatch(object), nil
+}
+
+//New creates a lease service for supplied lease URL and holder
+func New(fs afs.Service, URL, holder string, ttl time.Duration) Service {
+	return &service{fs: fs, URL: URL, holder: holder, ttl: ttl}
+}
@@ -0,0 +1,70 @@
+package lease
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"testing"
+	"time"
+)
+
+func TestService_Acquire(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+
+	useCases := []struct {
+		description string
+		URL         string
+		ttl         time.Duration
+		release     bool
+		wait        time.Duration
+		expect      bool
+	}{
+		{
+			description: "follower can not take over active lease",
+			URL:         "mem://localhost/lease/case001/dispatch.lease",
+			ttl:         time.Minute,
+			expect:      false,
+		},
+		{
+			description: "follower takes over expired lease",
+			URL:         "mem://localhost/lease/case002/dispatch.lease",
+			ttl:         10 * time.Millisecond,
+			wait:        20 * time.Millisecond,
+			expect:      true,
+		},
+		{
+			description: "follower takes over released lease",
+			URL:         "mem://localhost/lease/case003/dispatch.lease",
+			ttl:         time.Minute,
+			release:     true,
+			expect:      true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		leader := New(fs, useCase.URL, "replica-1", useCase.ttl)
+		follower := New(fs, useCase.URL, "replica-2", useCase.ttl)
+		acquired, err := leader.Acquire(ctx)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.True(t, acquired, useCase.description)
+		renewed, err := leader.Acquire(ctx)
+		assert.Nil(t, err, useCase.description)
+		assert.True(t, renewed, useCase.description)
+
+		if useCase.release {
+			assert.Nil(t, leader.Release(ctx), useCase.description)
+		}
+		time.Sleep(useCase.wait)
+		acquired, err = follower.Acquire(ctx)
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.expect, acquired, useCase.description)
+		if useCase.expect {
+			acquired, err = leader.Acquire(ctx)
+			assert.Nil(t, err, useCase.description)
+			assert.False(t, acquired, useCase.description)
+		}
+	}
+}
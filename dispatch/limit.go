@@ -0,0 +1,136 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/viant/afs/storage"
+	"github.com/viant/bqtail/dispatch/config"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//destLimits caches destination limits by task or batch window file URL, so that each rule keeps its own limits
+type destLimits struct {
+	mux   *sync.Mutex
+	byURL map[string]*config.Limit
+}
+
+func (l *destLimits) get(URL string) (*config.Limit, bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	limit, ok := l.byURL[URL]
+	return limit, ok
+}
+
+func (l *destLimits) put(URL string, limit *config.Limit) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.byURL[URL] = limit
+}
+
+//remove removes dispatched file limit
+func (l *destLimits) remove(URL string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	delete(l.byURL, URL)
+}
+
+//reset removes all cached limits
+func (l *destLimits) reset() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.byURL = make(map[string]*config.Limit)
+}
+
+func newDestLimits() *destLimits {
+	return &destLimits{mux: &sync.Mutex{}, byURL: make(map[string]*config.Limit)}
+}
+
+//ruleLimit represents rule limits carried by task or batch window file process
+type ruleLimit struct {
+	MaxConcurrentLoad int
+	MaxConcurrentSQL  int
+	Priority          int
+	Meta              *ruleLimit
+}
+
+//destLimit returns destination limit, rule limits and priority from task or window file take precedence over dispatch config,
+//limits are cached by file URL, since rules writing to the same destination can define different limits
+func (s *service) destLimit(ctx context.Context, destKey string, URL string) *config.Limit {
+	if limit, ok := s.limits.get(URL); ok {
+		return limit
+	}
+	limit := *s.config.DestLimit(destKey)
+	if reader, err := s.fs.DownloadWithURL(ctx, URL); err == nil {
+		process := &ruleLimit{}
+		err = json.NewDecoder(reader).Decode(process)
+		_ = reader.Close()
+		if err == nil {
+			if process.Meta != nil {
+				process = process.Meta
+			}
+			if process.MaxConcurrentLoad > 0 || process.MaxConcurrentSQL > 0 {
+				limit.MaxConcurrentLoad, limit.MaxConcurrentSQL = process.MaxConcurrentLoad, process.MaxConcurrentSQL
+			}
+			if process.Priority > 0 {
+				limit.Priority = process.Priority
+			}
+		}
+	}
+	s.limits.put(URL, &limit)
+	return &limit
+}
+
+//windowDestKey returns batch window destination key, window name uses dest_hash_endTime.win format
+func windowDestKey(name string) string {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	for i := 0; i < 2; i++ {
+		if index := strings.LastIndex(name, "_"); index != -1 {
+			name = string(name[:index])
+		}
+	}
+	return config.DestKey(name)
+}
+
+//candidate represents done job task to dispatch
+type candidate struct {
+	jobID   string
+	destKey string
+	state   string
+	object  storage.Object
+	due     time.Time
+	//priority job or window priority, since is time since the candidate waits for dispatch
+	priority int
+	since    time.Time
+}
+
+//roundRobin orders candidates taking one per destination in turn, destination order is rotated by offset, so that no destination is always first
+func roundRobin(byDest map[string][]*candidate, offset int) []*candidate {
+	var keys = make([]string, 0, len(byDest))
+	for key := range byDest {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	offset = offset % len(keys)
+	keys = append(keys[offset:], keys[:offset]...)
+	var result = make([]*candidate, 0)
+	for i := 0; ; i++ {
+		added := false
+		for _, key := range keys {
+			if i < len(byDest[key]) {
+				result = append(result, byDest[key][i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return result
+}
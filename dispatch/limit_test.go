@@ -0,0 +1,138 @@
+package dispatch
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/dispatch/config"
+	"github.com/viant/bqtail/dispatch/contract"
+	"github.com/viant/bqtail/dispatch/project"
+	"github.com/viant/bqtail/shared"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRoundRobin(t *testing.T) {
+	byDest := map[string][]*candidate{
+		"a": {{jobID: "a1"}, {jobID: "a2"}, {jobID: "a3"}},
+		"b": {{jobID: "b1"}},
+		"c": {{jobID: "c1"}, {jobID: "c2"}},
+	}
+	var useCases = []struct {
+		description string
+		offset      int
+		expect      []string
+	}{
+		{description: "no offset", offset: 0, expect: []string{"a1", "b1", "c1", "a2", "c2", "a3"}},
+		{description: "rotated", offset: 1, expect: []string{"b1", "c1", "a1", "c2", "a2", "a3"}},
+		{description: "wrapped", offset: 5, expect: []string{"c1", "a1", "b1", "c2", "a2", "a3"}},
+	}
+	for _, useCase := range useCases {
+		var actual = make([]string, 0)
+		for _, candidate := range roundRobin(byDest, useCase.offset) {
+			actual = append(actual, candidate.jobID)
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+	assert.Nil(t, roundRobin(map[string][]*candidate{}, 3))
+}
+
+func TestWindowDestKey(t *testing.T) {
+	assert.Equal(t, "mydataset_mytable", windowDestKey("mydataset.mytable_1723459_1574193994.win"))
+	assert.Equal(t, "proj_mydataset_my_table", windowDestKey("proj:mydataset.my_table_1723459_1574193994.win"))
+}
+
+func TestService_DestLimit(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/dispatch/limit/tasks"
+	assets := map[string]string{
+		"mydataset.rule--123_00002_load--dispatch.json":     `{"Action":"load","Meta":{"DestTable":"mydataset.rule","MaxConcurrentLoad":2,"MaxConcurrentSQL":1}}`,
+		"mydataset.window_123_1574193994.win":               `{"DestTable":"mydataset.window","MaxConcurrentLoad":3,"Priority":4}`,
+		"mydataset.events_1--124_00002_load--dispatch.json": `{"Action":"load","Meta":{"DestTable":"mydataset.events_1"}}`,
+		"mydataset.other--125_00002_load--dispatch.json":    `{"Action":"load","Meta":{"DestTable":"mydataset.other"}}`,
+		"mydataset.rule--126_00002_load--dispatch.json":     `{"Action":"load","Meta":{"DestTable":"mydataset.rule","MaxConcurrentLoad":1}}`,
+	}
+	for name, content := range assets {
+		if !assert.Nil(t, fs.Upload(ctx, baseURL+"/"+name, file.DefaultFileOsMode, strings.NewReader(content))) {
+			return
+		}
+	}
+	srv := &service{
+		fs:     fs,
+		limits: newDestLimits(),
+		config: &Config{
+			MaxConcurrentLoadPerDest: 5,
+			Limits:                   []*config.Limit{{Dest: "mydataset.events_*", MaxConcurrentLoad: 4, Priority: 2}},
+		},
+	}
+	var useCases = []struct {
+		description string
+		destKey     string
+		name        string
+		expectLoad  int
+		expectSQL   int
+		expectPrio  int
+	}{
+		{description: "rule limit", destKey: "mydataset_rule", name: "mydataset.rule--123_00002_load--dispatch.json", expectLoad: 2, expectSQL: 1},
+		{description: "window rule limit", destKey: windowDestKey("mydataset.window_123_1574193994.win"), name: "mydataset.window_123_1574193994.win", expectLoad: 3, expectPrio: 4},
+		{description: "config limit", destKey: "mydataset_events_1", name: "mydataset.events_1--124_00002_load--dispatch.json", expectLoad: 4, expectPrio: 2},
+		{description: "default limit", destKey: "mydataset_other", name: "mydataset.other--125_00002_load--dispatch.json", expectLoad: 5},
+		{description: "other rule of the same destination", destKey: "mydataset_rule", name: "mydataset.rule--126_00002_load--dispatch.json", expectLoad: 1},
+	}
+	for _, useCase := range useCases {
+		limit := srv.destLimit(ctx, useCase.destKey, baseURL+"/"+useCase.name)
+		assert.Equal(t, useCase.expectLoad, limit.MaxConcurrentLoad, useCase.description)
+		assert.Equal(t, useCase.expectSQL, limit.MaxConcurrentSQL, useCase.description)
+		assert.Equal(t, useCase.expectPrio, limit.Priority, useCase.description)
+	}
+
+	ruleURL := baseURL + "/mydataset.rule--123_00002_load--dispatch.json"
+	if !assert.Nil(t, fs.Delete(ctx, ruleURL)) {
+		return
+	}
+	limit := srv.destLimit(ctx, "mydataset_rule", ruleURL)
+	assert.Equal(t, 2, limit.MaxConcurrentLoad, "cached limit")
+	assert.True(t, limit.Allows(shared.ActionLoad, 1))
+	assert.False(t, limit.Allows(shared.ActionLoad, 2))
+	assert.False(t, limit.Allows(shared.ActionQuery, 1))
+	assert.True(t, limit.Allows(shared.ActionCopy, 10))
+}
+
+func TestService_DispatchEvents_DestLimit(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/dispatch/limit/events"
+	for _, name := range []string{
+		"mydataset_mytable--1_00002_load--dispatch",
+		"mydataset_mytable--2_00002_load--dispatch",
+		"mydataset_other--3_00002_query--dispatch",
+		"mydataset.mytable_123_1574193994.win",
+	} {
+		if !assert.Nil(t, fs.Upload(ctx, baseURL+"/"+name, file.DefaultFileOsMode, strings.NewReader("{}"))) {
+			return
+		}
+	}
+	objects, err := fs.List(ctx, baseURL)
+	if !assert.Nil(t, err) {
+		return
+	}
+	srv := &service{
+		fs:     fs,
+		limits: newDestLimits(),
+		config: &Config{Config: base.Config{AsyncTaskURL: baseURL}, EventDriven: true, MaxConcurrentLoadPerDest: 2},
+	}
+	events := project.New("p1")
+	events.Items = objects
+	response := contract.NewResponse()
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(1)
+	srv.dispatchEvents(ctx, waitGroup, response, events, false)
+	assert.EqualValues(t, "", response.Error)
+	assert.EqualValues(t, 2, events.Destination("mydataset_mytable").ActiveLoadCount())
+	assert.EqualValues(t, 1, events.Destination("mydataset_other").ActiveQueryCount())
+	assert.EqualValues(t, 0, len(response.Batched), "window is throttled by running dest load jobs")
+}
@@ -0,0 +1,296 @@
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/dispatch/project"
+	"github.com/viant/bqtail/shared"
+	"google.golang.org/api/bigquery/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	listStep              = 10 * time.Minute
+	listLag               = time.Minute
+	defaultJobListWorkers = 4
+)
+
+var listStates = []string{"done", "pending", "running"}
+
+//pageLister lists a page of jobs created within supplied time range
+type pageLister func(ctx context.Context, minCreated, maxCreated time.Time, pageToken string) ([]*bigquery.JobListJobs, string, error)
+
+//slice represents job creation time range listing, pageToken is the next page to list
+type slice struct {
+	minCreated time.Time
+	maxCreated time.Time
+	pageToken  string
+	done       bool
+}
+
+//listing represents incremental job listing of a dispatch cycle
+type listing struct {
+	since   time.Time
+	now     time.Time
+	resumed *slice
+	created []*slice
+	changed []*slice
+}
+
+func (l *listing) slices() []*slice {
+	var result = make([]*slice, 0, 1+len(l.created)+len(l.changed))
+	if l.resumed != nil {
+		result = append(result, l.resumed)
+	}
+	result = append(result, l.created...)
+	return append(result, l.changed...)
+}
+
+//advance moves cursor past listed slices, interrupted slice is resumed with its page token in the next cycle
+func (l *listing) advance(cursor *project.Cursor) {
+	if l.resumed != nil {
+		cursor.PageToken = ""
+		if !l.resumed.done && l.resumed.pageToken != "" {
+			cursor.PageToken = l.resumed.pageToken
+		}
+	}
+	if len(l.created) == 0 {
+		return
+	}
+	listed := l.since
+	for _, item := range l.created {
+		if !item.done {
+			if item.pageToken == "" || cursor.PageToken != "" {
+				break
+			}
+			cursor.PageToken, cursor.PageMinCreated, cursor.PageMaxCreated = item.pageToken, item.minCreated, item.maxCreated
+		}
+		listed = item.maxCreated
+	}
+	cursor.Extend(l.since, listed)
+}
+
+//newListing returns slices with new jobs of not listed tasks and with known not done jobs
+func newListing(cursor *project.Cursor, tasks map[string]time.Time, now time.Time) *listing {
+	result := &listing{now: now}
+	if cursor.PageToken != "" {
+		result.resumed = &slice{minCreated: cursor.PageMinCreated, maxCreated: cursor.PageMaxCreated, pageToken: cursor.PageToken}
+	}
+	var active = make([]time.Time, 0)
+	for jobID, modTime := range tasks {
+		if job, ok := cursor.Jobs[jobID]; ok {
+			if strings.ToUpper(job.State) == shared.DoneState {
+				continue
+			}
+			if job.Statistics != nil && job.Statistics.CreationTime > 0 {
+				modTime = time.Unix(0, job.Statistics.CreationTime*int64(time.Millisecond))
+			}
+			active = append(active, modTime)
+			continue
+		}
+		//listed task job that has not been found is checked individually
+		if cursor.Covers(modTime, listLag) {
+			continue
+		}
+		if result.since.IsZero() || modTime.Before(result.since) {
+			result.since = modTime
+		}
+	}
+	if !result.since.IsZero() {
+		result.since = result.since.Add(-listLag)
+		if minCreated := now.Add(-maxBqJobListLoopback); result.since.Before(minCreated) {
+			result.since = minCreated
+		}
+		//listing continues from the last listed creation time unless it leaves a gap longer than listStep
+		if listed := cursor.MaxCreated.Add(-listLag); !cursor.MinCreated.IsZero() && !result.since.Before(cursor.MinCreated) && result.since.Sub(listed) <= listStep {
+			result.since = listed
+		}
+		if result.resumed != nil && !result.since.Before(result.resumed.minCreated) && result.since.Before(result.resumed.maxCreated) {
+			result.since = result.resumed.maxCreated
+		}
+		result.created = split(result.since, now)
+	}
+	result.changed = activeSlices(active, result.since)
+	return result
+}
+
+//split splits creation time range into listStep slices
+func split(minCreated, maxCreated time.Time) []*slice {
+	var result = make([]*slice, 0)
+	for from := minCreated; from.Before(maxCreated); from = from.Add(listStep) {
+		to := from.Add(listStep)
+		if to.After(maxCreated) {
+			to = maxCreated
+		}
+		result = append(result, &slice{minCreated: from, maxCreated: to})
+	}
+	return result
+}
+
+//activeSlices groups not done job creation times, jobs created after since are listed with new jobs
+func activeSlices(created []time.Time, since time.Time) []*slice {
+	sort.Slice(created, func(i, j int) bool {
+		return created[i].Before(created[j])
+	})
+	var result = make([]*slice, 0)
+	var current *slice
+	for _, ts := range created {
+		if !since.IsZero() && !ts.Before(since) {
+			break
+		}
+		if current != nil && ts.Sub(current.minCreated) <= listStep {
+			current.maxCreated = ts.Truncate(time.Second).Add(time.Second)
+			continue
+		}
+		current = &slice{minCreated: ts.Truncate(time.Second), maxCreated: ts.Truncate(time.Second).Add(time.Second)}
+		result = append(result, current)
+	}
+	return result
+}
+
+//runListing lists slices with bounded worker pool, it puts task jobs and returns number of list calls and failed list calls,
+//failed slice is listed again in the next cycle
+func runListing(ctx context.Context, slices []*slice, workers int, list pageLister, tasks map[string]time.Time, jobsByID *jobs) (int, int) {
+	channel := make(chan *slice, len(slices))
+	for i := range slices {
+		channel <- slices[i]
+	}
+	close(channel)
+	calls := int32(0)
+	failures := int32(0)
+	waitGroup := &sync.WaitGroup{}
+	for i := 0; i < workers && i < len(slices); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for item := range channel {
+				if ctx.Err() != nil {
+					continue
+				}
+				for {
+					jobs, pageToken, err := list(ctx, item.minCreated, item.maxCreated, item.pageToken)
+					atomic.AddInt32(&calls, 1)
+					if err != nil {
+						atomic.AddInt32(&failures, 1)
+						if !IsContextError(err) {
+							shared.LogF("failed to list jobs created %v - %v: %v\n", item.minCreated, item.maxCreated, err)
+						}
+						break
+					}
+					for _, job := range jobs {
+						if job.JobReference == nil {
+							continue
+						}
+						if _, ok := tasks[job.JobReference.JobId]; ok {
+							jobsByID.put(trimJob(job))
+						}
+					}
+					if item.pageToken = pageToken; pageToken == "" {
+						item.done = true
+						break
+					}
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+	return int(calls), int(failures)
+}
+
+//savedListCalls returns number of list calls saved compared to listing all slices back to the oldest task
+func savedListCalls(minListTime, now time.Time, calls int) int {
+	all := int(now.Sub(minListTime)/listStep) + 1
+	if all > calls {
+		return all - calls
+	}
+	return 0
+}
+
+//trimJob returns job with fields used by dispatcher
+func trimJob(job *bigquery.JobListJobs) *bigquery.JobListJobs {
+	result := &bigquery.JobListJobs{Id: job.Id, JobReference: job.JobReference, State: job.State, ErrorResult: job.ErrorResult}
+	if job.Statistics != nil {
+		result.Statistics = &bigquery.JobStatistics{CreationTime: job.Statistics.CreationTime, StartTime: job.Statistics.StartTime, EndTime: job.Statistics.EndTime}
+	}
+	return result
+}
+
+//jobListEntry returns job list entry for supplied job
+func jobListEntry(job *bigquery.Job) *bigquery.JobListJobs {
+	result := &bigquery.JobListJobs{Id: job.Id, JobReference: job.JobReference, Statistics: job.Statistics}
+	if job.Status != nil {
+		result.State = job.Status.State
+		result.ErrorResult = job.Status.ErrorResult
+	}
+	return trimJob(result)
+}
+
+//jobPageLister returns project job page lister
+func (s *service) jobPageLister(projectID string) pageLister {
+	return func(ctx context.Context, minCreated, maxCreated time.Time, pageToken string) ([]*bigquery.JobListJobs, string, error) {
+		return s.bq.ListJobPage(ctx, projectID, minCreated, maxCreated, pageToken, listStates...)
+	}
+}
+
+//cursor returns region project job listing cursor
+func (s *service) cursor(ctx context.Context, projectID, region string) *project.Cursor {
+	key := project.CursorKey(projectID, region)
+	s.cursorMux.Lock()
+	defer s.cursorMux.Unlock()
+	if cursor, ok := s.cursors[key]; ok {
+		return cursor
+	}
+	cursor, err := s.loadCursor(ctx, key)
+	if err != nil {
+		shared.LogF("%v\n", err)
+	}
+	if cursor == nil || cursor.Key() != key {
+		cursor = project.NewCursor(projectID, region)
+	}
+	if cursor.Jobs == nil {
+		cursor.Jobs = make(map[string]*bigquery.JobListJobs)
+	}
+	s.cursors[key] = cursor
+	return cursor
+}
+
+func (s *service) loadCursor(ctx context.Context, key string) (*project.Cursor, error) {
+	URL := s.cursorURL(key)
+	if ok, _ := s.fs.Exists(ctx, URL, option.NewObjectKind(true)); !ok {
+		return nil, nil
+	}
+	reader, err := s.fs.DownloadWithURL(ctx, URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download cursor: %v", URL)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	cursor := &project.Cursor{}
+	if err = json.NewDecoder(reader).Decode(cursor); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cursor: %v", URL)
+	}
+	return cursor, nil
+}
+
+func (s *service) saveCursor(ctx context.Context, cursor *project.Cursor) error {
+	URL := s.cursorURL(cursor.Key())
+	JSON, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(JSON))
+}
+
+func (s *service) cursorURL(key string) string {
+	return url.Join(s.config.JobListCursorURL, key+shared.JSONExt)
+}
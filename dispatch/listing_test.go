@@ -0,0 +1,192 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/bqtail/dispatch/project"
+	"google.golang.org/api/bigquery/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+func listedJob(jobID, state string, created time.Time) *bigquery.JobListJobs {
+	return &bigquery.JobListJobs{
+		JobReference: &bigquery.JobReference{JobId: jobID},
+		State:        state,
+		Statistics:   &bigquery.JobStatistics{CreationTime: created.UnixNano() / int64(time.Millisecond)},
+	}
+}
+
+func TestNewListing(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description   string
+		cursor        *project.Cursor
+		tasks         map[string]time.Time
+		expectCreated int
+		expectChanged int
+		expectResumed bool
+		expectSince   time.Time
+	}{
+		{
+			description:   "not listed project",
+			cursor:        project.NewCursor("p1", ""),
+			tasks:         map[string]time.Time{"job1": now.Add(-25 * time.Minute), "job2": now.Add(-3 * time.Minute)},
+			expectCreated: 3,
+			expectSince:   now.Add(-26 * time.Minute),
+		},
+		{
+			description: "done jobs are not listed again",
+			cursor: &project.Cursor{ProjectID: "p1", MinCreated: now.Add(-time.Hour), MaxCreated: now.Add(-5 * time.Minute),
+				Jobs: map[string]*bigquery.JobListJobs{"job1": listedJob("job1", "DONE", now.Add(-30*time.Minute))}},
+			tasks: map[string]time.Time{"job1": now.Add(-30 * time.Minute), "job2": now.Add(-20 * time.Minute)},
+		},
+		{
+			description: "only jobs created after last listed creation time are listed",
+			cursor: &project.Cursor{ProjectID: "p1", MinCreated: now.Add(-time.Hour), MaxCreated: now.Add(-5 * time.Minute),
+				Jobs: map[string]*bigquery.JobListJobs{"job1": listedJob("job1", "DONE", now.Add(-30*time.Minute))}},
+			tasks:         map[string]time.Time{"job1": now.Add(-30 * time.Minute), "job3": now.Add(-2 * time.Minute)},
+			expectCreated: 1,
+			expectSince:   now.Add(-6 * time.Minute),
+		},
+		{
+			description: "not done jobs are refreshed",
+			cursor: &project.Cursor{ProjectID: "p1", MinCreated: now.Add(-time.Hour), MaxCreated: now.Add(-5 * time.Minute),
+				Jobs: map[string]*bigquery.JobListJobs{
+					"job1": listedJob("job1", "RUNNING", now.Add(-40*time.Minute)),
+					"job2": listedJob("job2", "PENDING", now.Add(-35*time.Minute)),
+					"job3": listedJob("job3", "RUNNING", now.Add(-10*time.Minute)),
+				}},
+			tasks:         map[string]time.Time{"job1": now.Add(-40 * time.Minute), "job2": now.Add(-35 * time.Minute), "job3": now.Add(-10 * time.Minute)},
+			expectChanged: 2,
+		},
+		{
+			description: "interrupted listing is resumed",
+			cursor: &project.Cursor{ProjectID: "p1", MinCreated: now.Add(-time.Hour), MaxCreated: now.Add(-5 * time.Minute),
+				PageToken: "page2", PageMinCreated: now.Add(-15 * time.Minute), PageMaxCreated: now.Add(-5 * time.Minute)},
+			tasks:         map[string]time.Time{"job4": now.Add(-2 * time.Minute)},
+			expectResumed: true,
+			expectCreated: 1,
+			expectSince:   now.Add(-5 * time.Minute),
+		},
+	}
+
+	for _, useCase := range useCases {
+		if useCase.cursor.Jobs == nil {
+			useCase.cursor.Jobs = make(map[string]*bigquery.JobListJobs)
+		}
+		listing := newListing(useCase.cursor, useCase.tasks, now)
+		assert.EqualValues(t, useCase.expectCreated, len(listing.created), useCase.description)
+		assert.EqualValues(t, useCase.expectChanged, len(listing.changed), useCase.description)
+		assert.EqualValues(t, useCase.expectResumed, listing.resumed != nil, useCase.description)
+		assert.EqualValues(t, useCase.expectSince, listing.since, useCase.description)
+	}
+}
+
+func TestRunListing(t *testing.T) {
+	now := time.Now()
+	since := now.Add(-30 * time.Minute)
+	var useCases = []struct {
+		description     string
+		failedPage      string
+		expectCalls     int
+		expectErrors    int
+		expectJobs      []string
+		expectMax       time.Time
+		expectPageToken string
+	}{
+		{
+			description: "all slices listed",
+			expectCalls: 5,
+			expectJobs:  []string{"job1", "job2", "job3"},
+			expectMax:   now,
+		},
+		{
+			description:     "interrupted slice page token",
+			failedPage:      "page2",
+			expectCalls:     5,
+			expectErrors:    1,
+			expectJobs:      []string{"job1", "job3"},
+			expectMax:       now,
+			expectPageToken: "page2",
+		},
+		{
+			description:  "failed slice is listed again",
+			failedPage:   "page1",
+			expectCalls:  4,
+			expectErrors: 1,
+			expectJobs:   []string{"job3"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		cursor := project.NewCursor("p1", "")
+		tasks := map[string]time.Time{"job1": since, "job2": since, "job3": now.Add(-5 * time.Minute)}
+		listing := newListing(cursor, tasks, now)
+		mux := &sync.Mutex{}
+		list := func(ctx context.Context, minCreated, maxCreated time.Time, pageToken string) ([]*bigquery.JobListJobs, string, error) {
+			mux.Lock()
+			defer mux.Unlock()
+			if !minCreated.Equal(listing.since) {
+				if maxCreated.Equal(now) {
+					return []*bigquery.JobListJobs{listedJob("job3", "DONE", now.Add(-5*time.Minute)), listedJob("other", "DONE", now)}, "", nil
+				}
+				return nil, "", nil
+			}
+			page := pageToken
+			if page == "" {
+				page = "page1"
+			}
+			if page == useCase.failedPage {
+				return nil, "", errors.New("context deadline exceeded")
+			}
+			if page == "page1" {
+				return []*bigquery.JobListJobs{listedJob("job1", "DONE", since)}, "page2", nil
+			}
+			return []*bigquery.JobListJobs{listedJob("job2", "RUNNING", since)}, "", nil
+		}
+		jobsByID := &jobs{mutex: &sync.Mutex{}, byID: cursor.Jobs}
+		calls, failures := runListing(context.Background(), listing.slices(), 2, list, tasks, jobsByID)
+		listing.advance(cursor)
+		assert.EqualValues(t, useCase.expectCalls, calls, useCase.description)
+		assert.EqualValues(t, useCase.expectErrors, failures, useCase.description)
+		assert.EqualValues(t, len(useCase.expectJobs), len(cursor.Jobs), useCase.description)
+		for _, jobID := range useCase.expectJobs {
+			assert.NotNil(t, cursor.Jobs[jobID], useCase.description+" "+jobID)
+		}
+		assert.EqualValues(t, useCase.expectMax, cursor.MaxCreated, useCase.description)
+		assert.EqualValues(t, useCase.expectPageToken, cursor.PageToken, useCase.description)
+	}
+}
+
+func TestService_Cursor(t *testing.T) {
+	ctx := context.Background()
+	srv := &service{
+		fs:        afs.New(),
+		config:    &Config{JobListCursorURL: "mem://localhost/dispatch/cursor"},
+		cursors:   make(map[string]*project.Cursor),
+		cursorMux: &sync.Mutex{},
+	}
+	us := srv.cursor(ctx, "p1", "US")
+	eu := srv.cursor(ctx, "p1", "EU")
+	assert.True(t, us != eu, "each region project has its own cursor")
+	us.Jobs["job1"] = listedJob("job1", "DONE", time.Now())
+	eu.Jobs["job2"] = listedJob("job2", "DONE", time.Now())
+	eu.Retain(map[string]time.Time{"job2": time.Now()})
+	assert.NotNil(t, us.Jobs["job1"], "other region jobs are retained")
+	assert.True(t, us == srv.cursor(ctx, "p1", "US"))
+
+	for _, cursor := range []*project.Cursor{us, eu} {
+		assert.Nil(t, srv.saveCursor(ctx, cursor))
+	}
+	for _, cursor := range []*project.Cursor{us, eu} {
+		loaded, err := srv.loadCursor(ctx, cursor.Key())
+		if assert.Nil(t, err) && assert.NotNil(t, loaded) {
+			assert.EqualValues(t, cursor.Region, loaded.Region)
+			assert.EqualValues(t, len(cursor.Jobs), len(loaded.Jobs))
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package dispatch
+
+import (
+	"github.com/viant/bqtail/stage/activity"
+	"sort"
+	"sync"
+	"time"
+)
+
+var defaultPriorityAging = 5 * time.Minute
+
+//prioritize stable sorts candidates by aged priority, higher first, priority is raised by one for each aging period the candidate waits,
+//so that low priority work can not starve, candidates with the same aged priority keep their (round robin) order
+func prioritize(candidates []*candidate, now time.Time, aging time.Duration) []*candidate {
+	var aged = make(map[*candidate]int, len(candidates))
+	for _, candidate := range candidates {
+		aged[candidate] = agedPriority(candidate, now, aging)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return aged[candidates[i]] > aged[candidates[j]]
+	})
+	return candidates
+}
+
+func agedPriority(candidate *candidate, now time.Time, aging time.Duration) int {
+	result := candidate.priority
+	if aging > 0 && !candidate.since.IsZero() && now.After(candidate.since) {
+		result += int(now.Sub(candidate.since) / aging)
+	}
+	return result
+}
+
+//waitingJobs tracks done jobs throttled by the last sweep, so that event dispatched job does not overtake higher priority waiting job
+type waitingJobs struct {
+	mux  *sync.Mutex
+	byID map[string]*candidate
+	seen map[string]time.Time
+}
+
+//put adds or refreshes throttled job
+func (w *waitingJobs) put(candidate *candidate) {
+	if w == nil {
+		return
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.byID[candidate.jobID] = candidate
+	w.seen[candidate.jobID] = time.Now()
+}
+
+//remove removes dispatched job
+func (w *waitingJobs) remove(jobID string) {
+	if w == nil {
+		return
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	delete(w.byID, jobID)
+	delete(w.seen, jobID)
+}
+
+//outranks returns true if waiting job of the same destination, or the same action if global limit applies, has higher aged priority than the candidate,
+//job not seen by sweep within expiry is removed, since it was dispatched or removed elsewhere
+func (w *waitingJobs) outranks(candidate *candidate, global bool, expiry time.Duration, aging time.Duration) bool {
+	if w == nil {
+		return false
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	now := time.Now()
+	action := activity.Parse(candidate.jobID).Action
+	priority := agedPriority(candidate, now, aging)
+	for jobID, waiting := range w.byID {
+		if now.Sub(w.seen[jobID]) > expiry {
+			delete(w.byID, jobID)
+			delete(w.seen, jobID)
+			continue
+		}
+		if jobID == candidate.jobID {
+			continue
+		}
+		if waiting.destKey != candidate.destKey && !(global && activity.Parse(jobID).Action == action) {
+			continue
+		}
+		if agedPriority(waiting, now, aging) > priority {
+			return true
+		}
+	}
+	return false
+}
+
+func newWaitingJobs() *waitingJobs {
+	return &waitingJobs{mux: &sync.Mutex{}, byID: make(map[string]*candidate), seen: make(map[string]time.Time)}
+}
@@ -0,0 +1,123 @@
+package dispatch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/stage/activity"
+	"testing"
+	"time"
+)
+
+func TestPrioritize(t *testing.T) {
+	now := time.Now()
+	aging := time.Minute
+	var useCases = []struct {
+		description string
+		candidates  []*candidate
+		expect      []string
+	}{
+		{
+			description: "higher priority first",
+			candidates: []*candidate{
+				{jobID: "debug1", since: now},
+				{jobID: "revenue1", priority: 5, since: now},
+				{jobID: "debug2", since: now},
+				{jobID: "revenue2", priority: 5, since: now},
+			},
+			expect: []string{"revenue1", "revenue2", "debug1", "debug2"},
+		},
+		{
+			description: "aged low priority",
+			candidates: []*candidate{
+				{jobID: "revenue1", priority: 2, since: now},
+				{jobID: "debug1", since: now.Add(-3 * time.Minute)},
+				{jobID: "debug2", since: now.Add(-time.Minute)},
+			},
+			expect: []string{"debug1", "revenue1", "debug2"},
+		},
+		{
+			description: "equal priority keeps order",
+			candidates: []*candidate{
+				{jobID: "b"},
+				{jobID: "a"},
+			},
+			expect: []string{"b", "a"},
+		},
+	}
+	for _, useCase := range useCases {
+		var actual = make([]string, 0)
+		for _, candidate := range prioritize(useCase.candidates, now, aging) {
+			actual = append(actual, candidate.jobID)
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestJobID_Priority(t *testing.T) {
+	baseURL := "mem://localhost/dispatch/priority/tasks"
+	process := &stage.Process{EventID: "123", DestTable: "mydataset.revenue", ProjectID: "p1", Region: "US", Priority: 7}
+	meta := activity.New(process, "load", "dispatch", 2)
+	jobID := JobID(baseURL, baseURL+"/"+meta.JobFilename()+".json")
+	assert.Equal(t, "mydataset_revenue--123_00002_load_p7--dispatch", jobID)
+	info := activity.Parse(jobID)
+	assert.Equal(t, 7, info.Priority)
+	assert.Equal(t, "load", info.Action)
+	assert.Equal(t, 2, info.Step)
+	assert.True(t, info.Async)
+}
+
+func TestWaitingJobs_Outranks(t *testing.T) {
+	aging := time.Minute
+	job := &candidate{jobID: "mydataset_mytable--1_00002_load--dispatch", destKey: "mydataset_mytable", priority: 1, since: time.Now()}
+	var useCases = []struct {
+		description string
+		waiting     *candidate
+		global      bool
+		seen        time.Duration
+		expect      bool
+	}{
+		{
+			description: "higher priority",
+			waiting:     &candidate{jobID: "mydataset_mytable--2_00002_load--dispatch", destKey: "mydataset_mytable", priority: 2, since: time.Now()},
+			expect:      true,
+		},
+		{
+			description: "lower priority",
+			waiting:     &candidate{jobID: "mydataset_mytable--2_00002_load--dispatch", destKey: "mydataset_mytable", since: time.Now()},
+		},
+		{
+			description: "aged priority",
+			waiting:     &candidate{jobID: "mydataset_mytable--2_00002_load--dispatch", destKey: "mydataset_mytable", since: time.Now().Add(-2 * aging)},
+			expect:      true,
+		},
+		{
+			description: "other destination",
+			waiting:     &candidate{jobID: "mydataset_other--2_00002_load--dispatch", destKey: "mydataset_other", priority: 2, since: time.Now()},
+		},
+		{
+			description: "other destination with global limit",
+			waiting:     &candidate{jobID: "mydataset_other--2_00002_load--dispatch", destKey: "mydataset_other", priority: 2, since: time.Now()},
+			global:      true,
+			expect:      true,
+		},
+		{
+			description: "other action with global limit",
+			waiting:     &candidate{jobID: "mydataset_other--2_00002_query--dispatch", destKey: "mydataset_other", priority: 2, since: time.Now()},
+			global:      true,
+		},
+		{
+			description: "expired",
+			waiting:     &candidate{jobID: "mydataset_mytable--2_00002_load--dispatch", destKey: "mydataset_mytable", priority: 2, since: time.Now()},
+			seen:        -3 * time.Minute,
+		},
+	}
+	for _, useCase := range useCases {
+		waiting := newWaitingJobs()
+		waiting.put(useCase.waiting)
+		waiting.seen[useCase.waiting.jobID] = time.Now().Add(useCase.seen)
+		assert.EqualValues(t, useCase.expect, waiting.outranks(job, useCase.global, 2*time.Minute, aging), useCase.description)
+	}
+	var none *waitingJobs
+	none.put(job)
+	assert.False(t, none.outranks(job, true, time.Minute, aging))
+}
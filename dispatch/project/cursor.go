@@ -0,0 +1,77 @@
+package project
+
+import (
+	"google.golang.org/api/bigquery/v2"
+	"time"
+)
+
+//Cursor represents persisted incremental BigQuery job listing state of a region project
+type Cursor struct {
+	ProjectID string
+	Region    string `json:",omitempty"`
+	//MinCreated and MaxCreated job creation time range that has been listed, MaxCreated is the last listed creation time
+	MinCreated time.Time
+	MaxCreated time.Time
+	//PageToken next page token of interrupted PageMinCreated - PageMaxCreated slice listing
+	PageToken      string    `json:",omitempty"`
+	PageMinCreated time.Time `json:",omitempty"`
+	PageMaxCreated time.Time `json:",omitempty"`
+	//Jobs listed jobs that still have a task file, keyed by job ID
+	Jobs map[string]*bigquery.JobListJobs `json:",omitempty"`
+}
+
+//Covers returns true if jobs created at supplied time have been listed, lag accounts for job listing visibility delay
+func (c *Cursor) Covers(created time.Time, lag time.Duration) bool {
+	if c.MinCreated.IsZero() {
+		return false
+	}
+	return !created.Before(c.MinCreated) && created.Before(c.MaxCreated.Add(-lag))
+}
+
+//Extend extends listed range with supplied range, disjoint range replaces listed one
+func (c *Cursor) Extend(minCreated, maxCreated time.Time) {
+	if !maxCreated.After(minCreated) {
+		return
+	}
+	if c.MinCreated.IsZero() || minCreated.After(c.MaxCreated) || maxCreated.Before(c.MinCreated) {
+		c.MinCreated, c.MaxCreated = minCreated, maxCreated
+		return
+	}
+	if minCreated.Before(c.MinCreated) {
+		c.MinCreated = minCreated
+	}
+	if maxCreated.After(c.MaxCreated) {
+		c.MaxCreated = maxCreated
+	}
+}
+
+//Retain removes jobs without a task
+func (c *Cursor) Retain(jobIDs map[string]time.Time) {
+	for jobID := range c.Jobs {
+		if _, ok := jobIDs[jobID]; !ok {
+			delete(c.Jobs, jobID)
+		}
+	}
+}
+
+//Key returns cursor key, each region project task folder has its own cursor
+func (c *Cursor) Key() string {
+	return CursorKey(c.ProjectID, c.Region)
+}
+
+//CursorKey returns region project cursor key
+func CursorKey(projectID, region string) string {
+	if region == "" {
+		return projectID
+	}
+	return projectID + ":" + region
+}
+
+//NewCursor creates a region project cursor
+func NewCursor(projectID, region string) *Cursor {
+	return &Cursor{
+		ProjectID: projectID,
+		Region:    region,
+		Jobs:      make(map[string]*bigquery.JobListJobs),
+	}
+}
@@ -10,13 +10,27 @@ import (
 type Events struct {
 	*contract.Performance
 	Items []storage.Object
+	//Destinations per destination table performance, keyed by config.DestKey
+	Destinations map[string]*contract.Performance `json:"-"`
+}
+
+//Destination returns destination performance
+func (e *Events) Destination(destKey string) *contract.Performance {
+	result, ok := e.Destinations[destKey]
+	if !ok {
+		result = contract.NewPerformance()
+		result.ProjectID = destKey
+		e.Destinations[destKey] = result
+	}
+	return result
 }
 
 //New creates project events
 func New(regionProject string) *Events {
 	result := &Events{
-		Performance: contract.NewPerformance(),
-		Items:       make([]storage.Object, 0),
+		Performance:  contract.NewPerformance(),
+		Items:        make([]storage.Object, 0),
+		Destinations: make(map[string]*contract.Performance),
 	}
 	parts := strings.Split(regionProject, ":")
 	result.ProjectID = regionProject
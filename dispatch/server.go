@@ -0,0 +1,250 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/bqtail/dispatch/contract"
+	"github.com/viant/bqtail/dispatch/lease"
+	"github.com/viant/bqtail/shared"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLeaseTTL     = 30 * time.Second
+	shutdownGracePeriod = 8 * time.Second
+)
+
+type stopKey struct{}
+
+type progressKey struct{}
+
+//Status represents dispatch daemon status, Performance shows in progress dispatch run, Response the last completed one
+type Status struct {
+	Holder      string
+	Leader      bool
+	Runs        int
+	Started     time.Time
+	Performance contract.ProjectPerformance `json:",omitempty"`
+	Response    *contract.Response          `json:",omitempty"`
+}
+
+//Server represents long running dispatch daemon, it dispatches continuously while holding leader lease
+type Server struct {
+	service Service
+	lease   lease.Service
+	addr    string
+	mux     *sync.RWMutex
+	status  *Status
+	current *contract.Response
+}
+
+//Serve runs dispatch cycles and HTTP endpoints until context is cancelled, in-flight cycle is completed before return
+func (s *Server) Serve(ctx context.Context) error {
+	server := &http.Server{Addr: s.addr, Handler: s.Handler()}
+	errChannel := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChannel <- err
+		}
+	}()
+	shared.LogF("serving dispatch on %v as %v\n", s.addr, s.status.Holder)
+	s.run(ctx, errChannel)
+	if err := s.lease.Release(context.Background()); err != nil {
+		shared.LogF("failed to release lease: %v\n", err)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	select {
+	case err := <-errChannel:
+		return err
+	default:
+	}
+	return nil
+}
+
+func (s *Server) run(ctx context.Context, errChannel chan error) {
+	for ctx.Err() == nil && len(errChannel) == 0 {
+		leader, err := s.lease.Acquire(ctx)
+		if err != nil {
+			shared.LogF("failed to acquire lease: %v\n", err)
+		}
+		s.setLeader(leader)
+		if !leader {
+			select {
+			case <-ctx.Done():
+			case <-time.After(s.lease.TTL() / 3):
+			}
+			continue
+		}
+		s.setResponse(s.dispatch(ctx))
+	}
+}
+
+//dispatch runs dispatch and job events consumer while renewing the lease, on lost lease or shutdown dispatch completes current cycle,
+//it is cancelled after grace period or before the lease could expire, whichever comes first
+func (s *Server) dispatch(ctx context.Context) *contract.Response {
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runCtx, stop := withStop(runCtx)
+	runCtx = withProgress(runCtx, s.setCurrent)
+	go func() {
+		renewInterval := s.lease.TTL() / 3
+		renewed := time.Now()
+		//stopAndCancel stops dispatch gracefully, and cancels it if not completed by deadline
+		stopAndCancel := func(deadline time.Time) {
+			stop()
+			select {
+			case <-runCtx.Done():
+			case <-time.After(time.Until(deadline)):
+				cancel()
+			}
+		}
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ctx.Done():
+				deadline := time.Now().Add(shutdownGracePeriod)
+				if expiry := renewed.Add(s.lease.TTL() - renewInterval); expiry.Before(deadline) {
+					deadline = expiry
+				}
+				stopAndCancel(deadline)
+				return
+			case <-time.After(renewInterval):
+				leader, err := s.lease.Acquire(runCtx)
+				if leader {
+					renewed = time.Now()
+					continue
+				}
+				//transient renewal error is tolerated as long as the lease has not expired
+				if err != nil && time.Since(renewed) < s.lease.TTL()-renewInterval {
+					shared.LogF("failed to renew lease: %v\n", err)
+					continue
+				}
+				shared.LogF("lost lease\n")
+				s.setLeader(false)
+				stopAndCancel(renewed.Add(s.lease.TTL() - renewInterval))
+				return
+			}
+		}
+	}()
+	consumed := s.consume(runCtx)
+	response := s.service.Dispatch(runCtx)
+	if consumed == nil {
+		return response
+	}
+	stop()
+	consumeResponse := <-consumed
+	for _, performance := range consumeResponse.Performance {
+		response.Merge(performance)
+	}
+	if consumeResponse.Error != "" {
+		shared.LogF("failed to consume job events: %v\n", consumeResponse.Error)
+	}
+	return response
+}
+
+//consume consumes BigQuery job completion events alongside dispatch if JobEventSubscription is configured, returns nil otherwise
+func (s *Server) consume(ctx context.Context) <-chan *contract.Response {
+	if s.service.Config().JobEventSubscription == "" {
+		return nil
+	}
+	consumed := make(chan *contract.Response, 1)
+	go func() {
+		consumed <- s.service.Consume(ctx)
+	}()
+	return consumed
+}
+
+func (s *Server) setLeader(leader bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.status.Leader = leader
+}
+
+func (s *Server) setResponse(response *contract.Response) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.status.Runs++
+	s.status.Response = response
+	s.current = nil
+}
+
+//setCurrent sets in progress dispatch run response
+func (s *Server) setCurrent(response *contract.Response) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.current = response
+}
+
+//Handler returns daemon HTTP handler with /healthz and /status endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = fmt.Fprint(writer, "ok")
+	})
+	mux.HandleFunc("/status", s.handleStatus)
+	return mux
+}
+
+func (s *Server) handleStatus(writer http.ResponseWriter, request *http.Request) {
+	s.mux.RLock()
+	status := *s.status
+	if s.current != nil {
+		status.Performance = s.current.PerformanceSnapshot()
+	}
+	data, err := json.Marshal(status)
+	s.mux.RUnlock()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write(data)
+}
+
+//withStop returns context with graceful stop function, stopped dispatch returns after the current cycle
+func withStop(ctx context.Context) (context.Context, func()) {
+	stopChannel := make(chan struct{})
+	once := &sync.Once{}
+	return context.WithValue(ctx, stopKey{}, stopChannel), func() {
+		once.Do(func() {
+			close(stopChannel)
+		})
+	}
+}
+
+//stopped returns graceful stop channel or nil if context does not support graceful stop
+func stopped(ctx context.Context) <-chan struct{} {
+	stopChannel, _ := ctx.Value(stopKey{}).(chan struct{})
+	return stopChannel
+}
+
+//withProgress returns context with in progress dispatch response listener
+func withProgress(ctx context.Context, listener func(response *contract.Response)) context.Context {
+	return context.WithValue(ctx, progressKey{}, listener)
+}
+
+//reportProgress passes in progress dispatch response to context listener if any
+func reportProgress(ctx context.Context, response *contract.Response) {
+	if listener, ok := ctx.Value(progressKey{}).(func(response *contract.Response)); ok {
+		listener(response)
+	}
+}
+
+//NewServer creates a dispatch daemon
+func NewServer(service Service, lease lease.Service, holder, addr string) *Server {
+	return &Server{
+		service: service,
+		lease:   lease,
+		addr:    addr,
+		mux:     &sync.RWMutex{},
+		status:  &Status{Holder: holder, Started: time.Now()},
+	}
+}
@@ -0,0 +1,197 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/bqtail/dispatch/contract"
+	"github.com/viant/bqtail/dispatch/lease"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cycleService struct {
+	Service
+	subscription string
+	dispatched   int32
+	consumed     int32
+}
+
+func (s *cycleService) Config() *Config {
+	return &Config{JobEventSubscription: s.subscription}
+}
+
+//Consume waits for graceful stop
+func (s *cycleService) Consume(ctx context.Context) *contract.Response {
+	atomic.AddInt32(&s.consumed, 1)
+	response := contract.NewResponse()
+	select {
+	case <-stopped(ctx):
+	case <-ctx.Done():
+	}
+	return response
+}
+
+//Dispatch runs cycles until gracefully stopped
+func (s *cycleService) Dispatch(ctx context.Context) *contract.Response {
+	atomic.AddInt32(&s.dispatched, 1)
+	response := contract.NewResponse()
+	for {
+		response.Cycles++
+		select {
+		case <-stopped(ctx):
+			return response
+		case <-ctx.Done():
+			response.SetIfError(ctx.Err())
+			return response
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestServer_Serve(t *testing.T) {
+	fs := afs.New()
+	var useCases = []struct {
+		description  string
+		URL          string
+		holder       string
+		subscription string
+		expectRuns   bool
+	}{
+		{
+			description: "leader dispatches",
+			URL:         "mem://localhost/serve/case001/dispatch.lease",
+			expectRuns:  true,
+		},
+		{
+			description: "follower waits for lease",
+			URL:         "mem://localhost/serve/case002/dispatch.lease",
+			holder:      "replica-0",
+			expectRuns:  false,
+		},
+		{
+			description:  "leader dispatches and consumes job events",
+			URL:          "mem://localhost/serve/case003/dispatch.lease",
+			subscription: "bq-job-events",
+			expectRuns:   true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		if useCase.holder != "" {
+			_, err := lease.New(fs, useCase.URL, useCase.holder, time.Minute).Acquire(context.Background())
+			assert.Nil(t, err, useCase.description)
+		}
+		service := &cycleService{subscription: useCase.subscription}
+		server := NewServer(service, lease.New(fs, useCase.URL, "replica-1", time.Minute), "replica-1", "127.0.0.1:0")
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := server.Serve(ctx)
+		cancel()
+		assert.Nil(t, err, useCase.description)
+
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+		assert.EqualValues(t, http.StatusOK, recorder.Code, useCase.description)
+		status := &Status{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), status), useCase.description)
+		assert.EqualValues(t, "replica-1", status.Holder, useCase.description)
+		if !useCase.expectRuns {
+			assert.EqualValues(t, 0, atomic.LoadInt32(&service.dispatched), useCase.description)
+			assert.False(t, status.Leader, useCase.description)
+			continue
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt32(&service.dispatched), useCase.description)
+		if useCase.subscription != "" {
+			assert.EqualValues(t, 1, atomic.LoadInt32(&service.consumed), useCase.description)
+		} else {
+			assert.EqualValues(t, 0, atomic.LoadInt32(&service.consumed), useCase.description)
+		}
+		assert.EqualValues(t, 1, status.Runs, useCase.description)
+		if assert.NotNil(t, status.Response, useCase.description) {
+			assert.True(t, status.Response.Cycles > 0, useCase.description)
+			assert.EqualValues(t, "", status.Response.Error, useCase.description)
+		}
+		//released lease can be taken over by other replica
+		acquired, err := lease.New(fs, useCase.URL, "replica-2", time.Minute).Acquire(context.Background())
+		assert.Nil(t, err, useCase.description)
+		assert.True(t, acquired, useCase.description)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewServer(&cycleService{}, nil, "replica-1", "").Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.EqualValues(t, http.StatusOK, recorder.Code)
+}
+
+type lostLease struct {
+	acquired int32
+	ttl      time.Duration
+}
+
+//Acquire grants the lease only once
+func (l *lostLease) Acquire(ctx context.Context) (bool, error) {
+	return atomic.AddInt32(&l.acquired, 1) == 1, nil
+}
+
+func (l *lostLease) Release(ctx context.Context) error {
+	return nil
+}
+
+func (l *lostLease) TTL() time.Duration {
+	return l.ttl
+}
+
+type progressService struct {
+	Service
+	running chan bool
+}
+
+func (s *progressService) Config() *Config {
+	return &Config{}
+}
+
+//Dispatch reports progress and ignores graceful stop
+func (s *progressService) Dispatch(ctx context.Context) *contract.Response {
+	response := contract.NewResponse()
+	reportProgress(ctx, response)
+	performance := contract.NewPerformance()
+	performance.ProjectID = "p1"
+	performance.Running.LoadJobs = 2
+	response.Merge(performance)
+	s.running <- true
+	<-ctx.Done()
+	response.SetIfError(ctx.Err())
+	return response
+}
+
+func TestServer_Dispatch_LostLease(t *testing.T) {
+	service := &progressService{running: make(chan bool, 1)}
+	leaseService := &lostLease{ttl: 300 * time.Millisecond}
+	server := NewServer(service, leaseService, "replica-1", "")
+	_, _ = leaseService.Acquire(context.Background())
+
+	responses := make(chan *contract.Response, 1)
+	started := time.Now()
+	go func() {
+		responses <- server.dispatch(context.Background())
+	}()
+	<-service.running
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	status := &Status{}
+	if assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), status)) && assert.NotNil(t, status.Performance["p1"]) {
+		assert.EqualValues(t, 2, status.Performance["p1"].Running.LoadJobs)
+	}
+
+	select {
+	case response := <-responses:
+		//lease is renewed every 100ms, lost lease dispatch is cancelled 200ms after the last renewal, before the lease expires
+		assert.True(t, time.Since(started) < leaseService.ttl)
+		assert.Contains(t, response.Error, context.Canceled.Error())
+	case <-time.After(time.Second):
+		assert.Fail(t, "dispatch was not cancelled on lost lease")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/viant/bqtail/dispatch/contract"
 	"github.com/viant/bqtail/dispatch/project"
 	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/service/pubsub"
 	"github.com/viant/bqtail/service/secret"
 	"github.com/viant/bqtail/service/slack"
 	"github.com/viant/bqtail/service/storage"
@@ -36,15 +37,24 @@ var maxBqJobListLoopback = 6 * time.Hour
 //Service represents event service
 type Service interface {
 	Dispatch(ctx context.Context) *contract.Response
+	DispatchEvent(ctx context.Context, data []byte) *contract.Response
+	Consume(ctx context.Context) *contract.Response
 	Config() *Config
 }
 
 type service struct {
 	task.Registry
-	lastCheck *time.Time
-	config    *Config
-	fs        afs.Service
-	bq        bq.Service
+	lastCheck  *time.Time
+	reconciled time.Time
+	config     *Config
+	fs         afs.Service
+	bq         bq.Service
+	pubsub     pubsub.Service
+	limits     *destLimits
+	waiting    *waitingJobs
+	round      int32
+	cursors    map[string]*project.Cursor
+	cursorMux  *sync.Mutex
 }
 
 //Config returns service config
@@ -71,6 +81,11 @@ func (s *service) Init(ctx context.Context) error {
 	s.bq = bq.New(bqService, s.Registry, s.config.ProjectID, s.fs, s.config.Config)
 	bq.InitRegistry(s.Registry, s.bq)
 	storage.InitRegistry(s.Registry, storage.New(s.fs))
+	if s.config.JobEventSubscription != "" {
+		if s.pubsub, err = pubsub.New(ctx, s.config.ProjectID, pubsub.EmulatorOptions()...); err != nil {
+			return err
+		}
+	}
 	return err
 }
 
@@ -78,6 +93,7 @@ func (s *service) Init(ctx context.Context) error {
 func (s *service) Dispatch(ctx context.Context) *contract.Response {
 	response := contract.NewResponse()
 	defer response.SetTimeTaken(response.Started)
+	reportProgress(ctx, response)
 	err := s.dispatch(ctx, response)
 	if err != nil {
 		response.SetIfError(err)
@@ -98,6 +114,7 @@ func (s *service) dispatch(ctx context.Context, response *contract.Response) err
 
 	running := int32(1)
 	timeoutDuration = timeoutDuration - thinkTime
+	s.limits.reset()
 
 	for atomic.LoadInt32(&running) == 1 {
 		cycleStartTime := time.Now()
@@ -109,9 +126,10 @@ func (s *service) dispatch(ctx context.Context, response *contract.Response) err
 		if isProcessingError(err) {
 			return err
 		}
+		reconcile := s.isReconcileDue()
 		for i := range projectEvents {
 			waitGroup.Add(1)
-			go s.dispatchEvents(ctx, waitGroup, response, projectEvents[i])
+			go s.dispatchEvents(ctx, waitGroup, response, projectEvents[i], reconcile)
 		}
 		response.Cycles++
 		if err = s.logPerformance(ctx, response); err != nil {
@@ -135,6 +153,7 @@ func (s *service) listProjectEvents(ctx context.Context) ([]*project.Events, err
 	}
 
 	addEvents(s.config.ProjectID, events, registry)
+	s.expireWindowTracking(ctx, events)
 	for _, obj := range events {
 		if obj.IsDir() && strings.HasPrefix(obj.Name(), shared.TempProjectPrefix) {
 			projectRegion := string(obj.Name()[len(shared.TempProjectPrefix):])
@@ -180,6 +199,8 @@ func (s *service) wait(ctx context.Context, startTime time.Time, waitGroup *sync
 
 	select {
 	case <-time.After(2 * thinkTime):
+	case <-stopped(ctx):
+		return false
 	case <-ctx.Done():
 		atomic.StoreInt32(running, 0)
 		return false
@@ -187,9 +208,14 @@ func (s *service) wait(ctx context.Context, startTime time.Time, waitGroup *sync
 	return true
 }
 
-func (s *service) dispatchEvents(ctx context.Context, waitGroup *sync.WaitGroup, response *contract.Response, projectEvents *project.Events) {
+func (s *service) dispatchEvents(ctx context.Context, waitGroup *sync.WaitGroup, response *contract.Response, projectEvents *project.Events, reconcile bool) {
 	defer waitGroup.Done()
-	err := s.dispatchBqEvents(ctx, response, projectEvents)
+	var err error
+	if reconcile {
+		err = s.dispatchBqEvents(ctx, response, projectEvents)
+	} else {
+		s.addRunningTasks(projectEvents, response)
+	}
 	if err == nil || IsNotFound(err) {
 		err = s.dispatchBatchEvents(ctx, response, projectEvents)
 	}
@@ -202,7 +228,7 @@ func (s *service) dispatchEvents(ctx context.Context, waitGroup *sync.WaitGroup,
 
 func (s *service) logPerformance(ctx context.Context, response *contract.Response) error {
 	URL := url.Join(s.config.JournalURL, shared.PerformanceFile)
-	JSON, err := json.Marshal(response.Performance)
+	JSON, err := json.Marshal(response.PerformanceSnapshot())
 	if err != nil {
 		return err
 	}
@@ -213,7 +239,7 @@ func (s *service) logPerformance(ctx context.Context, response *contract.Respons
 func (s *service) filterCandidate(response *contract.Response, objects []astorage.Object, action string) map[string][]astorage.Object {
 	var result = make(map[string][]astorage.Object, 0)
 	for i, object := range objects {
-		if object.IsDir() || path.Ext(object.Name()) == shared.WindowExt {
+		if object.IsDir() || isBatchFile(object.Name()) {
 			continue
 		}
 		if response.Jobs.Has(object.URL()) {
@@ -239,25 +265,30 @@ func (s *service) filterCandidate(response *contract.Response, objects []astorag
 }
 
 func (s *service) notifyDoneProcesses(ctx context.Context, events *project.Events, response *contract.Response, jobsByID *jobs) (err error) {
-	waitGroup := &sync.WaitGroup{}
-	for i, object := range events.Items {
-		if object.IsDir() || path.Ext(object.Name()) == shared.WindowExt {
+	var byDest = make(map[string][]*candidate)
+	//items are sorted by mod time descending, so that each destination candidates are collected oldest first
+	for i := len(events.Items) - 1; i >= 0; i-- {
+		object := events.Items[i]
+		if object.IsDir() || isBatchFile(object.Name()) {
 			continue
 		}
 
-		age := time.Now().Sub(events.Items[i].ModTime())
-		//if just create skip to next
-		if age < thinkTime {
+		if response.Jobs.Has(object.URL()) {
 			continue
 		}
-		if response.Jobs.Has(object.URL()) {
+		age := time.Now().Sub(object.ModTime())
+		//if just create skip to next, its job is counted as running
+		if age < s.minTaskAge() {
+			s.addRunningTask(events, object)
 			continue
 		}
 		jobID := JobID(s.Config().AsyncTaskURL, object.URL())
 		var state string
+		var statistics *bigquery.JobStatistics
 		listJob := jobsByID.get(jobID)
 		if listJob != nil {
 			state = listJob.State
+			statistics = listJob.Statistics
 		} else {
 			response.GetCount++
 			job, err := s.bq.GetJob(ctx, events.Region, events.ProjectID, jobID)
@@ -268,21 +299,46 @@ func (s *service) notifyDoneProcesses(ctx context.Context, events *project.Event
 			if job.Status != nil {
 				state = job.Status.State
 			}
+			statistics = job.Statistics
+			if job.JobReference != nil {
+				jobsByID.put(jobListEntry(job))
+			}
 		}
-
+		info := activity.Parse(jobID)
+		destKey := info.DestTable
 		switch strings.ToUpper(state) {
 		case shared.DoneState:
 			break
 		default:
 			events.AddEvent(state, jobID)
+			events.Destination(destKey).AddEvent(state, jobID)
 			continue
 		}
-		stageInfo := events.AddDispatch(jobID)
-		if !s.canNotify(stageInfo.Action, events.Performance) {
-			events.AddThrottled(jobID)
+		done := &candidate{jobID: jobID, destKey: destKey, state: state, object: object, priority: info.Priority, since: object.ModTime()}
+		if statistics != nil && statistics.EndTime > 0 {
+			done.since = time.Unix(0, statistics.EndTime*int64(time.Millisecond))
+		}
+		if done.priority == 0 {
+			done.priority = s.destLimit(ctx, destKey, object.URL()).Priority
+		}
+		byDest[destKey] = append(byDest[destKey], done)
+	}
+
+	waitGroup := &sync.WaitGroup{}
+	//throttled jobs are released by aged priority, then in round robin destination order
+	candidates := prioritize(roundRobin(byDest, int(atomic.AddInt32(&s.round, 1))), time.Now(), s.config.PriorityAging())
+	for _, candidate := range candidates {
+		stageInfo := events.AddDispatch(candidate.jobID)
+		destPerf := events.Destination(candidate.destKey)
+		if !s.canNotify(stageInfo.Action, events.Performance) || !s.canNotifyDest(ctx, stageInfo.Action, candidate, destPerf) {
+			events.AddThrottled(candidate.jobID)
+			destPerf.Throttled.Update(candidate.jobID)
+			s.waiting.put(candidate)
 			continue
 		}
-		job := contract.NewJob(jobID, object.URL(), state)
+		s.waiting.remove(candidate.jobID)
+		destPerf.AddDispatch(candidate.jobID)
+		job := contract.NewJob(candidate.jobID, candidate.object.URL(), candidate.state)
 
 		waitGroup.Add(1)
 		go func(job *contract.Job) {
@@ -299,6 +355,61 @@ func (s *service) notifyDoneProcesses(ctx context.Context, events *project.Event
 	return err
 }
 
+//addRunningTasks counts tasks as running jobs in their destination performance when job statuses are not checked in the cycle,
+//so that destination limits apply to batch windows in any cycle
+func (s *service) addRunningTasks(events *project.Events, response *contract.Response) {
+	for _, object := range events.Items {
+		if object.IsDir() || isBatchFile(object.Name()) || response.Jobs.Has(object.URL()) {
+			continue
+		}
+		s.addRunningTask(events, object)
+	}
+}
+
+//addRunningTask counts task with not checked job status as running job in project and its destination performance
+func (s *service) addRunningTask(events *project.Events, object astorage.Object) {
+	jobID := JobID(s.config.AsyncTaskURL, object.URL())
+	events.AddEvent(shared.RunningState, jobID)
+	events.Destination(activity.Parse(jobID).DestTable).AddEvent(shared.RunningState, jobID)
+}
+
+//canNotifyDest returns true if destination limit allows to dispatch another job, jobs dispatched in this cycle are counted as active
+func (s *service) canNotifyDest(ctx context.Context, action string, candidate *candidate, perf *contract.Performance) bool {
+	limit := s.destLimit(ctx, candidate.destKey, candidate.object.URL())
+	return limit.Allows(action, perf.ActiveCount(action)+perf.Dispatched.ActionCount(action))
+}
+
+//isReconcileDue returns true if task job statuses are to be checked with BigQuery, in event driven mode reconciliation sweep runs every reconcileDelay
+func (s *service) isReconcileDue() bool {
+	if !s.config.IsEventDriven() {
+		return true
+	}
+	if time.Since(s.reconciled) < reconcileDelay {
+		return false
+	}
+	s.reconciled = time.Now()
+	return true
+}
+
+//minTaskAge returns min age of task to check its job status, in event driven mode recent tasks are dispatched by job completion events
+func (s *service) minTaskAge() time.Duration {
+	if s.config.IsEventDriven() {
+		return reconcileDelay
+	}
+	return thinkTime
+}
+
+//hasGlobalLimit returns true if MaxConcurrentSQL or MaxConcurrentLoad applies to the action
+func (s *service) hasGlobalLimit(action string) bool {
+	switch action {
+	case shared.ActionQuery:
+		return s.config.MaxConcurrentSQL > 0
+	case shared.ActionLoad:
+		return s.config.MaxConcurrentLoad > 0
+	}
+	return false
+}
+
 func (s *service) canNotify(action string, perf *contract.Performance) bool {
 	if action == shared.ActionQuery {
 		return s.config.MaxConcurrentSQL == 0 || s.config.MaxConcurrentSQL > perf.ActiveQueryCount()+1
@@ -310,10 +421,6 @@ func (s *service) canNotify(action string, perf *contract.Performance) bool {
 }
 
 func (s *service) dispatchBqEvents(ctx context.Context, response *contract.Response, events *project.Events) error {
-	var jobsByID = newJobs()
-	stepDuration := 10 * time.Minute
-	maxCreated := time.Now()
-	minCreated := maxCreated.Add(-stepDuration)
 	perf := events.Performance
 	if len(events.Items) == 0 {
 		return nil
@@ -327,36 +434,38 @@ func (s *service) dispatchBqEvents(ctx context.Context, response *contract.Respo
 		minListTime = time.Now().Add(-maxBqJobListLoopback)
 	}
 	startTime := time.Now()
-	waitGroup := &sync.WaitGroup{}
-	for {
-		waitGroup.Add(1)
-		go func(minCreated, maxCreated time.Time) {
-			defer waitGroup.Done()
-			s.listBQJobs(ctx, perf.ProjectID, minCreated, maxCreated, jobsByID)
-		}(minCreated, maxCreated)
-		maxCreated = maxCreated.Add(-stepDuration)
-		minCreated = minCreated.Add(-stepDuration)
-		candidate := minCreated.Add(-stepDuration)
-		if candidate.Before(minListTime) {
-			break
-		}
-	}
-	go func() {
-		waitGroup.Wait()
-		response.ListTime = fmt.Sprintf("%s", time.Now().Sub(startTime))
-	}()
+	cursor := s.cursor(ctx, perf.ProjectID, perf.Region)
+	tasks := s.taskJobs(events, response)
+	cursor.Retain(tasks)
+	jobsByID := &jobs{mutex: &sync.Mutex{}, byID: cursor.Jobs}
+	//only jobs created after the last listed creation time and not done jobs are listed
+	listing := newListing(cursor, tasks, startTime)
+	calls, failures := runListing(ctx, listing.slices(), s.config.JobListWorkers(), s.jobPageLister(perf.ProjectID), tasks, jobsByID)
+	listing.advance(cursor)
+	perf.ListCalls += calls
+	perf.ListErrors += failures
+	perf.ListSaved += savedListCalls(minListTime, startTime, calls)
+	response.ListTime = fmt.Sprintf("%s", time.Now().Sub(startTime))
 	err := s.notifyDoneProcesses(ctx, events, response, jobsByID)
+	if e := s.saveCursor(ctx, cursor); e != nil {
+		shared.LogF("failed to save cursor: %v\n", e)
+	}
 	return err
 }
 
-func (s *service) listBQJobs(ctx context.Context, projectID string, minCreated, maxCreated time.Time, jobsByID *jobs) {
-	jobs, err := s.bq.ListJob(ctx, projectID, minCreated, maxCreated, "done", "pending", "running")
-	if err != nil {
-		return
-	}
-	for i := range jobs {
-		jobsByID.put(jobs[i])
+//taskJobs returns task creation time by job ID for tasks to check job status
+func (s *service) taskJobs(events *project.Events, response *contract.Response) map[string]time.Time {
+	var result = make(map[string]time.Time)
+	for _, object := range events.Items {
+		if object.IsDir() || isBatchFile(object.Name()) {
+			continue
+		}
+		if time.Now().Sub(object.ModTime()) < s.minTaskAge() || response.Jobs.Has(object.URL()) {
+			continue
+		}
+		result[JobID(s.Config().AsyncTaskURL, object.URL())] = object.ModTime()
 	}
+	return result
 }
 
 //notify notify bqtail
@@ -368,7 +477,11 @@ func (s *service) notify(ctx context.Context, job *contract.Job, events *project
 	if shared.IsDebugLoggingLevel() {
 		shared.LogF("notify: %v -> %v\n", job.URL, taskURL)
 	}
-	return s.fs.Move(ctx, job.URL, taskURL, option.NewObjectKind(true))
+	err := s.fs.Move(ctx, job.URL, taskURL, option.NewObjectKind(true))
+	if err == nil {
+		s.limits.remove(job.URL)
+	}
+	return err
 }
 
 func (s *service) dispatchBatchEvents(ctx context.Context, response *contract.Response, projectObjects *project.Events) (err error) {
@@ -378,7 +491,10 @@ func (s *service) dispatchBatchEvents(ctx context.Context, response *contract.Re
 		return nil
 	}
 	response.BatchCount = len(objects)
-	for _, obj := range objects {
+	closed := closedWindows(objects)
+	var byDest = make(map[string][]*candidate)
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
 		if obj.IsDir() || path.Ext(obj.Name()) != shared.WindowExt {
 			continue
 		}
@@ -390,19 +506,34 @@ func (s *service) dispatchBatchEvents(ctx context.Context, response *contract.Re
 			err = e
 			continue
 		}
-		if time.Now().After(dueTime.Add(shared.StorageListVisibilityDelay * time.Millisecond)) {
-			if !s.canNotify(shared.ActionLoad, perf) {
-				continue
-			}
-			perf.Metric(shared.RunningState).BatchJobs++
-			perf.Metric(shared.RunningState).LoadJobs++
-			response.AddBatch(obj.URL(), *dueTime)
-			baseURL := fmt.Sprintf("gs://%v%v", s.config.TriggerBucket, s.config.BatchPrefix)
-			destURL := url.Join(baseURL, obj.Name())
-			if e := s.fs.Move(ctx, obj.URL(), destURL, option.NewObjectKind(true)); e != nil {
-				err = e
-			}
+		//window closed early by batch limit is scheduled before its end time
+		if closed[obj.URL()] || time.Now().After(dueTime.Add(shared.StorageListVisibilityDelay*time.Millisecond)) {
+			destKey := windowDestKey(obj.Name())
+			priority := s.destLimit(ctx, destKey, obj.URL()).Priority
+			byDest[destKey] = append(byDest[destKey], &candidate{destKey: destKey, object: obj, due: *dueTime, priority: priority, since: *dueTime})
+		}
+	}
+	//due windows are scheduled by aged priority, then in round robin destination order
+	candidates := prioritize(roundRobin(byDest, int(atomic.AddInt32(&s.round, 1))), time.Now(), s.config.PriorityAging())
+	for _, candidate := range candidates {
+		if !s.canNotify(shared.ActionLoad, perf) {
+			break
+		}
+		destPerf := projectObjects.Destination(candidate.destKey)
+		if !s.destLimit(ctx, candidate.destKey, candidate.object.URL()).Allows(shared.ActionLoad, destPerf.ActiveLoadCount()) {
+			continue
+		}
+		destPerf.Metric(shared.RunningState).LoadJobs++
+		perf.Metric(shared.RunningState).BatchJobs++
+		perf.Metric(shared.RunningState).LoadJobs++
+		response.AddBatch(candidate.object.URL(), candidate.due)
+		baseURL := fmt.Sprintf("gs://%v%v", s.config.TriggerBucket, s.config.BatchPrefix)
+		destURL := url.Join(baseURL, candidate.object.Name())
+		if e := s.fs.Move(ctx, candidate.object.URL(), destURL, option.NewObjectKind(true)); e != nil {
+			err = e
+			continue
 		}
+		s.limits.remove(candidate.object.URL())
 	}
 	return err
 }
@@ -426,9 +557,13 @@ func JobID(baseURL string, URL string) string {
 //New creates a dispatchBqEvents service
 func New(ctx context.Context, config *Config) (Service, error) {
 	srv := &service{
-		config:   config,
-		fs:       afs.New(),
-		Registry: task.NewRegistry(),
+		config:    config,
+		fs:        afs.New(),
+		Registry:  task.NewRegistry(),
+		limits:    newDestLimits(),
+		waiting:   newWaitingJobs(),
+		cursors:   make(map[string]*project.Cursor),
+		cursorMux: &sync.Mutex{},
 	}
 	return srv, srv.Init(ctx)
 }
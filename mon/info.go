@@ -12,6 +12,7 @@ type Info struct {
 	Stalled         info.Metrics `json:",omitempty"`
 	Corrupted       *info.Metric `json:",omitempty"`
 	InvalidSchema   *info.Metric `json:",omitempty"`
+	Late            *info.Metric `json:",omitempty"`
 	rule            *config.Rule
 	traversed       bool
 	activeDatafile  int
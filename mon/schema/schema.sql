@@ -137,6 +137,11 @@ CREATE OR REPLACE  TABLE bqtail.bqmon (
                             Min TIMESTAMP,
                             Max TIMESTAMP,
                             Count INT64
+                    >,
+                    Late STRUCT<
+                            Min TIMESTAMP,
+                            Max TIMESTAMP,
+                            Count INT64
                     >
             >
         >,
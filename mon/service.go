@@ -63,13 +63,14 @@ func (s *service) Check(ctx context.Context, request *Request) *Response {
 
 func (s *service) check(ctx context.Context, request *Request, response *Response) (err error) {
 	waitGroup := &sync.WaitGroup{}
-	waitGroup.Add(5)
+	waitGroup.Add(6)
 	infoDest := map[string]*Info{}
 	_ = s.Config.ReloadIfNeeded(ctx, s.fs)
 	var active, doneLoads activeLoads
 	var schedules batches
 	var errors []*info.Error
 	var stages []*activity.Meta
+	var late map[string]*info.Metric
 	go func() {
 		defer waitGroup.Done()
 		var e error
@@ -110,6 +111,13 @@ func (s *service) check(ctx context.Context, request *Request, response *Respons
 			err = e
 		}
 	}()
+	go func() {
+		defer waitGroup.Done()
+		var e error
+		if late, e = s.getLateArrivals(ctx, request.Recency); e != nil {
+			err = e
+		}
+	}()
 	waitGroup.Wait()
 
 	if len(active) > 0 {
@@ -131,6 +139,9 @@ func (s *service) check(ctx context.Context, request *Request, response *Respons
 	if len(errors) > 0 {
 		s.updateErrors(errors, infoDest)
 	}
+	if len(late) > 0 {
+		s.updateLateArrivals(late, infoDest)
+	}
 
 	var keys = make([]string, 0)
 	for k, inf := range infoDest {
@@ -352,6 +363,46 @@ func (s *service) getErrors(ctx context.Context, recencyExpr string) ([]*info.Er
 	return result, nil
 }
 
+//getLateArrivals returns recent late arrival data files metric per destination
+func (s *service) getLateArrivals(ctx context.Context, recencyExpr string) (map[string]*info.Metric, error) {
+	baseURL := url.Join(s.Config.JournalURL, shared.LateLocation)
+	if ok, _ := s.fs.Exists(ctx, baseURL); !ok {
+		return nil, nil
+	}
+	destFolders, err := s.fs.List(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	var result = make(map[string]*info.Metric)
+	modifiedAfter := getErrorLoopback(recencyExpr)
+	for _, folder := range destFolders {
+		if url.Equals(folder.URL(), baseURL) || !folder.IsDir() {
+			continue
+		}
+		files, err := s.fs.List(ctx, folder.URL(), matcher.NewModification(nil, &modifiedAfter))
+		if err != nil {
+			return nil, err
+		}
+		metric := info.NewMetric()
+		for _, file := range files {
+			if !file.IsDir() {
+				metric.AddEvent(file.ModTime())
+			}
+		}
+		if metric.Count > 0 {
+			result[folder.Name()] = metric
+		}
+	}
+	return result, nil
+}
+
+func (s *service) updateLateArrivals(late map[string]*info.Metric, infoDest map[string]*Info) {
+	for dest, metric := range late {
+		inf := s.getInfo(dest, infoDest)
+		inf.Late = metric
+	}
+}
+
 func getErrorLoopback(recencyExpr string) time.Time {
 	modifiedAfter := time.Now().Add(-time.Hour)
 	if recencyExpr != "" {
@@ -471,7 +522,7 @@ func (s *service) listLoadStages(ctx context.Context, result *[]*activity.Meta)
 		return err
 	}
 	for _, object := range objects {
-		if object.IsDir() || path.Ext(object.Name()) == shared.WindowExt {
+		if object.IsDir() || path.Ext(object.Name()) == shared.WindowExt || path.Ext(object.Name()) == shared.ClosedWindowExt {
 			continue
 		}
 
@@ -1,10 +1,14 @@
 package schema
 
 const (
-	ModeRepeated    = "REPEATED"
-	FieldTypeFloat  = "FLOAT64"
-	FieldTypeInt    = "INT64"
-	FieldTypeBool   = "BOOLEAN"
-	FieldTypeString = "STRING"
-	FieldTypeRecord = "RECORD"
+	ModeRepeated       = "REPEATED"
+	ModeRequired       = "REQUIRED"
+	ModeNullable       = "NULLABLE"
+	FieldTypeFloat     = "FLOAT64"
+	FieldTypeInt       = "INT64"
+	FieldTypeNumeric   = "NUMERIC"
+	FieldTypeBool      = "BOOLEAN"
+	FieldTypeString    = "STRING"
+	FieldTypeRecord    = "RECORD"
+	numericIntegerSize = 29
 )
@@ -39,7 +39,11 @@ func New(aMap map[string]interface{}, description string) ([]*bigquery.TableFiel
 			aSlice := v.([]interface{})
 			aSliceFields := make([][]*bigquery.TableFieldSchema, 0)
 			for _, item := range aSlice {
-				fields, err := New(item.(map[string]interface{}), description)
+				record, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				fields, err := New(record, description)
 				if err != nil {
 					return nil, err
 				}
@@ -76,27 +80,65 @@ func IndexFields(schemaFields []*bigquery.TableFieldSchema) map[string]bool {
 	return result
 }
 
-//MergeFields merge multi schema fields
+//Field returns field for case insensitive column name, nested field is referenced with dot separated path
+func Field(fields []*bigquery.TableFieldSchema, column string) *bigquery.TableFieldSchema {
+	column = strings.ToLower(column)
+	if index := strings.Index(column, "."); index != -1 {
+		parent := string(column[:index])
+		for i := range fields {
+			if parent == strings.ToLower(fields[i].Name) {
+				return Field(fields[i].Fields, column[index+1:])
+			}
+		}
+	}
+	for i := range fields {
+		if column == strings.ToLower(fields[i].Name) {
+			return fields[i]
+		}
+	}
+	return nil
+}
+
+//MergeFields merge multi schema fields, nested and repeated record fields are merged at any depth,
+//a field without type takes type from subsequent schemas, INT64 is widened to FLOAT64 on conflict
 func MergeFields(schemaFields ...[]*bigquery.TableFieldSchema) []*bigquery.TableFieldSchema {
-	var merged = make(map[string]int)
+	var merged = make(map[string]*bigquery.TableFieldSchema)
 	var result = make([]*bigquery.TableFieldSchema, 0)
 	if len(schemaFields) == 1 {
 		return schemaFields[0]
 	}
 	for k := range schemaFields {
-		for i, field := range schemaFields[k] {
-			index, ok := merged[field.Name]
+		for _, field := range schemaFields[k] {
+			key := strings.ToLower(field.Name)
+			existing, ok := merged[key]
 			if !ok {
-				merged[field.Name] = i
-				result = append(result, schemaFields[k][i])
+				cloned := *field
+				merged[key] = &cloned
+				result = append(result, &cloned)
 				continue
 			}
-			result[index].Fields = MergeFields(result[index].Fields, field.Fields)
+			mergeType(existing, field)
+			if len(field.Fields) > 0 {
+				existing.Fields = MergeFields(existing.Fields, field.Fields)
+			}
 		}
 	}
 	return result
 }
 
+//mergeType sets type of a field inferred without a value (i.e. empty array), numeric type is widened on conflict
+func mergeType(existing, field *bigquery.TableFieldSchema) {
+	switch {
+	case existing.Type == "":
+		existing.Type = field.Type
+		if existing.Mode == "" {
+			existing.Mode = field.Mode
+		}
+	case existing.Type == FieldTypeInt && field.Type == FieldTypeFloat:
+		existing.Type = FieldTypeFloat
+	}
+}
+
 //FieldType big query a schema returns a field type
 func FieldType(v interface{}) (fieldType string, repeated bool) {
 	switch val := v.(type) {
@@ -110,8 +152,11 @@ func FieldType(v interface{}) (fieldType string, repeated bool) {
 	case bool:
 		fieldType = FieldTypeBool
 	case []interface{}:
-		if len(val) > 0 {
-			fieldType, _ = FieldType(val[0])
+		for _, item := range val {
+			if item != nil {
+				fieldType, _ = FieldType(item)
+				break
+			}
 		}
 		repeated = true
 	case map[string]interface{}:
@@ -5,6 +5,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/assertly"
 	"github.com/viant/toolbox"
+	"google.golang.org/api/bigquery/v2"
 	"testing"
 )
 
@@ -151,3 +152,77 @@ func TestNew(t *testing.T) {
 	}
 
 }
+
+func TestMergeFields(t *testing.T) {
+	useCases := []struct {
+		description string
+		fields      [][]*bigquery.TableFieldSchema
+		expect      interface{}
+	}{
+		{
+			description: "top level field addition",
+			fields: [][]*bigquery.TableFieldSchema{
+				{{Name: "id", Type: "INT64"}},
+				{{Name: "id", Type: "INT64"}, {Name: "name", Type: "STRING"}},
+			},
+			expect: `[{"name":"id","type":"INT64"},{"name":"name","type":"STRING"}]`,
+		},
+		{
+			description: "deep repeated record field addition",
+			fields: [][]*bigquery.TableFieldSchema{
+				{
+					{Name: "id", Type: "INT64"},
+					{Name: "items", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{
+						{Name: "sku", Type: "STRING"},
+						{Name: "attrs", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{{Name: "k", Type: "STRING"}}},
+					}},
+				},
+				{
+					{Name: "items", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{
+						{Name: "attrs", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{{Name: "v", Type: "FLOAT64"}}},
+					}},
+				},
+				{
+					{Name: "items", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{
+						{Name: "qty", Type: "INT64"},
+					}},
+					{Name: "ts", Type: "TIMESTAMP"},
+				},
+			},
+			expect: `[
+	{"name":"id","type":"INT64"},
+	{"name":"items","type":"RECORD","mode":"REPEATED","fields":[
+		{"name":"sku","type":"STRING"},
+		{"name":"attrs","type":"RECORD","mode":"REPEATED","fields":[{"name":"k","type":"STRING"},{"name":"v","type":"FLOAT64"}]},
+		{"name":"qty","type":"INT64"}
+	]},
+	{"name":"ts","type":"TIMESTAMP"}
+]`,
+		},
+		{
+			description: "empty array type taken from subsequent schema",
+			fields: [][]*bigquery.TableFieldSchema{
+				{{Name: "tags", Mode: "REPEATED"}},
+				{{Name: "tags", Type: "STRING", Mode: "REPEATED"}},
+			},
+			expect: `[{"name":"tags","type":"STRING","mode":"REPEATED"}]`,
+		},
+		{
+			description: "int widened to float",
+			fields: [][]*bigquery.TableFieldSchema{
+				{{Name: "value", Type: "INT64"}},
+				{{Name: "value", Type: "FLOAT64"}},
+				{{Name: "value", Type: "INT64"}},
+			},
+			expect: `[{"name":"value","type":"FLOAT64"}]`,
+		},
+	}
+
+	for _, useCase := range useCases {
+		merged := MergeFields(useCase.fields...)
+		actual, _ := json.Marshal(merged)
+		if !assertly.AssertValues(t, useCase.expect, string(actual), useCase.description) {
+			toolbox.DumpIndent(merged, true)
+		}
+	}
+}
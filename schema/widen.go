@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"google.golang.org/api/bigquery/v2"
+	"strconv"
+	"strings"
+)
+
+//typeWidths ranks numeric types, a value of narrower type can be safely cast to a wider one
+var typeWidths = map[string]int{
+	FieldTypeInt:     1,
+	FieldTypeNumeric: 2,
+	FieldTypeFloat:   3,
+}
+
+//StandardType returns standard SQL type name for supplied (legacy) field type
+func StandardType(fieldType string) string {
+	switch fieldType = strings.ToUpper(fieldType); fieldType {
+	case "INTEGER":
+		return FieldTypeInt
+	case "FLOAT", "DOUBLE":
+		return FieldTypeFloat
+	}
+	return fieldType
+}
+
+//IsWider returns true if field type is wider than the other type
+func IsWider(fieldType, otherType string) bool {
+	width, ok := typeWidths[StandardType(fieldType)]
+	if !ok {
+		return false
+	}
+	otherWidth, ok := typeWidths[StandardType(otherType)]
+	return ok && width > otherWidth
+}
+
+//WidenType returns the narrowest type wider than field type able to represent supplied value, or empty string if there is no safe widening
+func WidenType(fieldType, value string) string {
+	if StandardType(fieldType) != FieldTypeInt {
+		return ""
+	}
+	value = strings.TrimSpace(value)
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return ""
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return ""
+	}
+	if digits := strings.TrimLeft(value, "+-"); isDigits(digits) && len(digits) <= numericIntegerSize {
+		return FieldTypeNumeric
+	}
+	return FieldTypeFloat
+}
+
+//Widen widens column type and relaxes its REQUIRED mode when mode is NULLABLE, column can use dot to address nested field, returns true if field was changed
+func Widen(fields []*bigquery.TableFieldSchema, column, fieldType, mode string) bool {
+	field := Field(fields, column)
+	if field == nil {
+		return false
+	}
+	changed := false
+	if fieldType != "" && IsWider(fieldType, field.Type) {
+		field.Type = StandardType(fieldType)
+		changed = true
+	}
+	if mode == ModeNullable && field.Mode == ModeRequired {
+		field.Mode = ModeNullable
+		changed = true
+	}
+	return changed
+}
+
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestWidenType(t *testing.T) {
+	var useCases = []struct {
+		description string
+		fieldType   string
+		value       string
+		expect      string
+	}{
+		{description: "fraction", fieldType: "INTEGER", value: "1.5", expect: FieldTypeFloat},
+		{description: "exponent", fieldType: "INT64", value: "1e3", expect: FieldTypeFloat},
+		{description: "int64 overflow", fieldType: "INT64", value: "92233720368547758070", expect: FieldTypeNumeric},
+		{description: "numeric overflow", fieldType: "INT64", value: "123456789012345678901234567890", expect: FieldTypeFloat},
+		{description: "int value", fieldType: "INT64", value: "12", expect: ""},
+		{description: "string value", fieldType: "INT64", value: "abc", expect: ""},
+		{description: "non int type", fieldType: "STRING", value: "1.5", expect: ""},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, WidenType(useCase.fieldType, useCase.value), useCase.description)
+	}
+}
+
+func TestWiden(t *testing.T) {
+	fields := []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER", Mode: ModeRequired},
+		{Name: "info", Type: FieldTypeRecord, Fields: []*bigquery.TableFieldSchema{
+			{Name: "qty", Type: "INTEGER"},
+		}},
+		{Name: "price", Type: "FLOAT"},
+	}
+	assert.True(t, Widen(fields, "info.qty", FieldTypeNumeric, ""))
+	assert.Equal(t, FieldTypeNumeric, fields[1].Fields[0].Type)
+	assert.True(t, Widen(fields, "INFO.qty", FieldTypeFloat, ""))
+	assert.Equal(t, FieldTypeFloat, fields[1].Fields[0].Type)
+	assert.False(t, Widen(fields, "info.qty", FieldTypeNumeric, ""), "narrowing")
+	assert.False(t, Widen(fields, "price", FieldTypeFloat, ""), "same type")
+	assert.True(t, Widen(fields, "id", "", ModeNullable))
+	assert.Equal(t, ModeNullable, fields[0].Mode)
+	assert.Equal(t, "INTEGER", fields[0].Type)
+	assert.False(t, Widen(fields, "missing", FieldTypeFloat, ModeNullable))
+}
@@ -13,10 +13,12 @@ func (s *service) runActions(ctx context.Context, err error, parent *bigquery.Jo
 		return fmt.Errorf("parent was empty")
 	}
 	baseJob := base.Job(*parent)
-	toRun := onDone.ToRun(err, &baseJob)
+	toRun, conditionErr := onDone.ToRun(err, &baseJob)
 	if len(toRun) == 0 {
-		return nil
+		return conditionErr
 	}
-	_, err = task.RunAll(ctx, s.Registry, toRun)
-	return err
+	if _, err = task.RunAll(ctx, s.Registry, toRun); err != nil {
+		return err
+	}
+	return conditionErr
 }
@@ -0,0 +1,31 @@
+package bq
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"google.golang.org/api/bigquery/v2"
+)
+
+//DryRun validates supplied standard SQL without running it, it returns dry run job with query result schema
+func (s *service) DryRun(ctx context.Context, projectID, SQL string) (*bigquery.Job, error) {
+	if projectID == "" {
+		projectID = s.projectID
+	}
+	useLegacy := false
+	job := &bigquery.Job{
+		Configuration: &bigquery.JobConfiguration{
+			DryRun: true,
+			Query: &bigquery.JobConfigurationQuery{
+				Query:        SQL,
+				UseLegacySql: &useLegacy,
+			},
+		},
+	}
+	call := bigquery.NewJobsService(s.Service).Insert(projectID, job)
+	call.Context(ctx)
+	result, err := call.Do()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid SQL: %v", SQL)
+	}
+	return result, nil
+}
@@ -5,19 +5,41 @@ import (
 	"github.com/pkg/errors"
 	"github.com/viant/bqtail/base"
 	"google.golang.org/api/bigquery/v2"
+	"regexp"
+	"strings"
+)
+
+var (
+	fakeTableExpr     = regexp.MustCompile("(?i)\\b(?:FROM|JOIN)\\s+`?([\\w\\-:.]+)`?\\s+(?:AS\\s+)?(\\w+)")
+	fakeReferenceExpr = regexp.MustCompile(`\b([A-Za-z_]\w*)\.([A-Za-z_]\w*)\b`)
+	fakeLiteralExpr   = regexp.MustCompile("`[^`]*`|'[^']*'|\"[^\"]*\"")
+	fakeKeywords      = map[string]bool{"WHERE": true, "ON": true, "LEFT": true, "RIGHT": true, "INNER": true, "JOIN": true, "GROUP": true, "ORDER": true, "LIMIT": true, "UNION": true, "USING": true}
 )
 
 type faker struct {
 	Service
-	tables map[string]*bigquery.Table
+	tables       map[string]*bigquery.Table
+	defaultTable *bigquery.Table
 }
 
 func (f *faker) Table(ctx context.Context, reference *bigquery.TableReference) (*bigquery.Table, error) {
 	key := base.EncodeTableReference(reference, false)
-	if len(f.tables) == 0 || f.tables[key] == nil {
-		return nil, errors.Errorf("not found table: %v", key)
+	if table, ok := f.tables[key]; ok && table != nil {
+		return table, nil
+	}
+	if table, ok := f.tables[reference.DatasetId+"."+reference.TableId]; ok && table != nil {
+		return table, nil
 	}
-	return f.tables[key], nil
+	if f.defaultTable != nil {
+		table := *f.defaultTable
+		table.TableReference = reference
+		return &table, nil
+	}
+	return nil, errors.Errorf("not found table: %v", key)
+}
+
+func (f *faker) Patch(ctx context.Context, request *PatchRequest) (*bigquery.Table, error) {
+	return request.TemplateTable, nil
 }
 
 func (f *faker) CreateDatasetIfNotExist(ctx context.Context, region string, dataset *bigquery.DatasetReference) error {
@@ -28,7 +50,47 @@ func (f *faker) CreateTableIfNotExist(ctx context.Context, table *bigquery.Table
 	return nil
 }
 
+//DryRun checks that SQL tables exist and alias qualified column references match table schema
+func (f *faker) DryRun(ctx context.Context, projectID, SQL string) (*bigquery.Job, error) {
+	var aliases = make(map[string]map[string]bool)
+	for _, match := range fakeTableExpr.FindAllStringSubmatch(SQL, -1) {
+		if fakeKeywords[strings.ToUpper(match[2])] {
+			continue
+		}
+		tableRef, err := base.NewTableReference(match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid table: %v", match[1])
+		}
+		table, err := f.Table(ctx, tableRef)
+		if err != nil {
+			return nil, errors.Errorf("Not found: Table %v", match[1])
+		}
+		var fields = make(map[string]bool)
+		if table.Schema != nil {
+			for _, field := range table.Schema.Fields {
+				fields[strings.ToLower(field.Name)] = true
+			}
+		}
+		aliases[strings.ToLower(match[2])] = fields
+	}
+	for _, match := range fakeReferenceExpr.FindAllStringSubmatch(fakeLiteralExpr.ReplaceAllString(SQL, ""), -1) {
+		fields, ok := aliases[strings.ToLower(match[1])]
+		if !ok {
+			continue
+		}
+		if !fields[strings.ToLower(match[2])] {
+			return nil, errors.Errorf("Unrecognized name: %v.%v", match[1], match[2])
+		}
+	}
+	return &bigquery.Job{Configuration: &bigquery.JobConfiguration{DryRun: true}}, nil
+}
+
 //NewFakerWithTables creates a faker with tables
 func NewFakerWithTables(tables map[string]*bigquery.Table) Service {
 	return &faker{tables: tables}
 }
+
+//NewFaker creates a faker with tables, default table is returned for any other table
+func NewFaker(tables map[string]*bigquery.Table, defaultTable *bigquery.Table) Service {
+	return &faker{tables: tables, defaultTable: defaultTable}
+}
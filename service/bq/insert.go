@@ -0,0 +1,22 @@
+package bq
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/base"
+	"google.golang.org/api/bigquery/v2"
+)
+
+//InsertAll streams rows into table, per row insert errors are returned with response
+func (s *service) InsertAll(ctx context.Context, reference *bigquery.TableReference, request *bigquery.TableDataInsertAllRequest) (*bigquery.TableDataInsertAllResponse, error) {
+	if reference.ProjectId == "" {
+		reference.ProjectId = s.projectID
+	}
+	call := bigquery.NewTabledataService(s.Service).InsertAll(reference.ProjectId, reference.DatasetId, reference.TableId, request)
+	call.Context(ctx)
+	response, err := call.Do()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to stream %v rows into %v", len(request.Rows), base.EncodeTableReference(reference, false))
+	}
+	return response, err
+}
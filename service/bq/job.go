@@ -21,26 +21,37 @@ func (s *service) GetJob(ctx context.Context, location, projectID, jobID string)
 	return job, err
 }
 
-//GetJob returns a job ID
+//ListJob returns all jobs created within supplied time range
 func (s *service) ListJob(ctx context.Context, projectID string, minCreateTime time.Time, maxCreateTime time.Time, stateFilter ...string) ([]*bigquery.JobListJobs, error) {
-	jobService := bigquery.NewJobsService(s.Service)
-	call := jobService.List(projectID)
-	call.MinCreationTime(uint64(minCreateTime.Unix() * 1000))
-	call.MaxCreationTime(uint64(maxCreateTime.Unix() * 1000))
-	call.StateFilter(stateFilter...)
 	result := make([]*bigquery.JobListJobs, 0)
 	pageToken := ""
 	for {
-		call.Context(ctx)
-		call.PageToken(pageToken)
-		list, err := call.Do()
+		jobs, nextPageToken, err := s.ListJobPage(ctx, projectID, minCreateTime, maxCreateTime, pageToken, stateFilter...)
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, list.Jobs...)
-		if pageToken = list.NextPageToken; pageToken == "" {
+		result = append(result, jobs...)
+		if pageToken = nextPageToken; pageToken == "" {
 			break
 		}
 	}
 	return result, nil
 }
+
+//ListJobPage returns a page of jobs created within supplied time range with next page token
+func (s *service) ListJobPage(ctx context.Context, projectID string, minCreateTime time.Time, maxCreateTime time.Time, pageToken string, stateFilter ...string) ([]*bigquery.JobListJobs, string, error) {
+	jobService := bigquery.NewJobsService(s.Service)
+	call := jobService.List(projectID)
+	call.MinCreationTime(uint64(minCreateTime.Unix() * 1000))
+	call.MaxCreationTime(uint64(maxCreateTime.Unix() * 1000))
+	call.StateFilter(stateFilter...)
+	call.Context(ctx)
+	if pageToken != "" {
+		call.PageToken(pageToken)
+	}
+	list, err := call.Do()
+	if err != nil {
+		return nil, "", err
+	}
+	return list.Jobs, list.NextPageToken, nil
+}
@@ -3,6 +3,7 @@ package bq
 import (
 	"context"
 	"fmt"
+	"github.com/pkg/errors"
 	"github.com/viant/bqtail/base"
 	"github.com/viant/bqtail/shared"
 	"github.com/viant/bqtail/task"
@@ -17,8 +18,6 @@ type LoadRequest struct {
 	DMLAppend bool
 }
 
-const maxJobLoadURIs = 10000
-
 //Load loads data into BigQuery
 func (s *service) Load(ctx context.Context, request *LoadRequest, action *task.Action) (job *bigquery.Job, err error) {
 	projectID := action.Meta.ProjectID
@@ -42,7 +41,7 @@ func (s *service) Load(ctx context.Context, request *LoadRequest, action *task.A
 	if shared.IsInfoLoggingLevel() {
 		shared.LogF("[%v] loading %v datafile(s) into %v", action.Meta.DestTable, datafileCount, base.EncodeTableReference(job.Configuration.Load.DestinationTable, true))
 	}
-	if datafileCount <= maxJobLoadURIs {
+	if datafileCount <= shared.MaxLoadURIs {
 		return s.Post(ctx, job, action)
 	}
 	postJob, err := s.loadInParts(ctx, job, request, action)
@@ -67,22 +66,30 @@ func (s *service) getUniqueURIs(ctx context.Context, candidates []string) []stri
 	return result
 }
 
-func (s *service) loadInParts(ctx context.Context, job *bigquery.Job, request *LoadRequest, Action *task.Action) (postJob *bigquery.Job, err error) {
+//loadInParts loads data files exceeding load job limit sequentially, action post actions run once the last part completes
+func (s *service) loadInParts(ctx context.Context, job *bigquery.Job, request *LoadRequest, action *task.Action) (postJob *bigquery.Job, err error) {
 	URIs := job.Configuration.Load.SourceUris
-	parts := len(URIs) / maxJobLoadURIs
-	offset := 0
-	jobID := job.JobReference.JobId
-	job.Configuration.Load.WriteDisposition = "WRITE_APPEND"
-	for i := 0; i < parts; i++ {
-		limit := offset + maxJobLoadURIs
-		if limit >= len(URIs) {
-			limit = len(URIs) - 1
+	for offset, part := 0, 0; offset < len(URIs); offset, part = offset+shared.MaxLoadURIs, part+1 {
+		limit := offset + shared.MaxLoadURIs
+		if limit > len(URIs) {
+			limit = len(URIs)
 		}
-
-		job.Configuration.Load.SourceUris = URIs[offset:limit]
-		job.JobReference.JobId = fmt.Sprintf("j%03d_%v", i, jobID)
-		if postJob, err = s.Post(ctx, job, Action); err != nil {
-			return nil, err
+		load := *job.Configuration.Load
+		load.SourceUris = URIs[offset:limit]
+		if part > 0 {
+			load.WriteDisposition = shared.WriteDispositionAppend
+		}
+		partJob := &bigquery.Job{Configuration: &bigquery.JobConfiguration{Load: &load}}
+		if limit == len(URIs) {
+			partJob.JobReference = job.JobReference
+			return s.Post(ctx, partJob, action)
+		}
+		meta := *action.Meta
+		meta.EventID = fmt.Sprintf("%vp%v", meta.EventID, part)
+		meta.Async = false
+		partAction := &task.Action{Action: action.Action, Meta: &meta, Actions: &task.Actions{}}
+		if postJob, err = s.Post(ctx, partJob, partAction); err != nil {
+			return postJob, errors.Wrapf(err, "failed to load part %v", part)
 		}
 	}
 	return postJob, err
@@ -34,7 +34,7 @@ func (s *service) schedulePostTask(ctx context.Context, job *bigquery.Job, actio
 	if err != nil {
 		return errors.Wrapf(err, "failed to encode actions: %v", action)
 	}
-	filename := action.Meta.JobFilename()
+	filename := action.Meta.TaskFilename()
 	URL := url.Join(s.Config.AsyncTaskURL, filename)
 	return base.RunWithRetries(func() error {
 		return s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data))
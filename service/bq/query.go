@@ -81,6 +81,7 @@ type QueryRequest struct {
 	Append           bool
 	Dest             string
 	Template         string
+	Transactional    bool `json:",omitempty"` //generated DML statement that group commit runs within its transaction
 	destinationTable *bigquery.TableReference
 }
 
@@ -127,3 +128,18 @@ func NewQueryAction(SQL string, dest *bigquery.TableReference, template string,
 	_ = result.SetRequest(query)
 	return result
 }
+
+//NewDMLAction creates a new DML statement query request, transactional statement is deferred to group commit transaction
+func NewDMLAction(SQL string, transactional bool, finally *task.Actions) *task.Action {
+	query := &QueryRequest{
+		SQL:           SQL,
+		Append:        true,
+		Transactional: transactional,
+	}
+	result := &task.Action{
+		Action:  shared.ActionQuery,
+		Actions: finally,
+	}
+	_ = result.SetRequest(query)
+	return result
+}
@@ -17,6 +17,8 @@ type Service interface {
 
 	ListJob(ctx context.Context, projectID string, minCreateTime, maxCreateTime time.Time, stateFilter ...string) ([]*bigquery.JobListJobs, error)
 
+	ListJobPage(ctx context.Context, projectID string, minCreateTime, maxCreateTime time.Time, pageToken string, stateFilter ...string) ([]*bigquery.JobListJobs, string, error)
+
 	Table(ctx context.Context, reference *bigquery.TableReference) (*bigquery.Table, error)
 
 	Load(ctx context.Context, request *LoadRequest, Action *task.Action) (*bigquery.Job, error)
@@ -30,6 +32,10 @@ type Service interface {
 	Patch(ctx context.Context, request *PatchRequest) (*bigquery.Table, error)
 
 	CreateTableIfNotExist(ctx context.Context, table *bigquery.Table, patchIfDifferent bool) error
+
+	InsertAll(ctx context.Context, reference *bigquery.TableReference, request *bigquery.TableDataInsertAllRequest) (*bigquery.TableDataInsertAllResponse, error)
+
+	DryRun(ctx context.Context, projectID, SQL string) (*bigquery.Job, error)
 }
 
 type service struct {
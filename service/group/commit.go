@@ -0,0 +1,228 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/task"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+const commitLock = "commit" + shared.LockExt
+
+//CommitRequest represents a group member commit request
+type CommitRequest struct {
+	URL      string
+	Member   string
+	Expected []string
+	Error    string
+}
+
+//Validate checks if request is valid
+func (r CommitRequest) Validate() error {
+	if r.URL == "" {
+		return fmt.Errorf("URL was empty")
+	}
+	if r.Member == "" {
+		return fmt.Errorf("member was empty")
+	}
+	if len(r.Expected) == 0 {
+		return fmt.Errorf("expected members were empty")
+	}
+	return nil
+}
+
+//Member represents group member status with deferred actions
+type Member struct {
+	Name   string
+	Error  string       `json:",omitempty"`
+	Action *task.Action `json:",omitempty"`
+}
+
+//Commit records group member status, the last completed member commits the whole group
+func (s *service) Commit(ctx context.Context, request *CommitRequest, action *task.Action) error {
+	return s.complete(ctx, request, action, true)
+}
+
+//Barrier records member status, the last completed member runs all members deferred actions as they are, without a transaction
+func (s *service) Barrier(ctx context.Context, request *BarrierRequest, action *task.Action) error {
+	commitRequest := CommitRequest(*request)
+	return s.complete(ctx, &commitRequest, action, false)
+}
+
+//complete records member status, the last completed member commits the group
+func (s *service) complete(ctx context.Context, request *CommitRequest, action *task.Action, transactional bool) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	member := &Member{Name: request.Member, Error: request.Error, Action: action}
+	if err := s.putMember(ctx, request.URL, member); err != nil {
+		return err
+	}
+	members, err := s.members(ctx, request.URL)
+	if err != nil {
+		return err
+	}
+	for _, name := range request.Expected {
+		if _, ok := members[name]; !ok {
+			if shared.IsDebugLoggingLevel() {
+				shared.LogF("[%v] group member %v waiting for: %v\n", request.URL, request.Member, name)
+			}
+			return nil
+		}
+	}
+	taken, err := s.acquireLock(ctx, url.Join(request.URL, commitLock), request.Member)
+	if err != nil || !taken {
+		return err //other member already committing the group
+	}
+	err = s.commit(ctx, request, members, transactional)
+	if e := s.fs.Delete(ctx, request.URL); e != nil && shared.IsDebugLoggingLevel() {
+		shared.LogF("failed to clean up group %v: %v\n", request.URL, e)
+	}
+	return err
+}
+
+//commit runs all members deferred OnSuccess actions (DML within one transaction if transactional) if all members succeeded or each member OnFailure otherwise
+func (s *service) commit(ctx context.Context, request *CommitRequest, members map[string]*Member, transactional bool) error {
+	var failures = make([]string, 0)
+	for _, name := range request.Expected {
+		if member := members[name]; member.Error != "" {
+			failures = append(failures, fmt.Sprintf("%v: %v", name, member.Error))
+		}
+	}
+	var groupErr error
+	if len(failures) > 0 {
+		groupErr = errors.Errorf("group %v failed: %v", request.URL, strings.Join(failures, "; "))
+	}
+	if shared.IsInfoLoggingLevel() {
+		shared.LogF("[%v] committing group with %v member(s), failed: %v\n", request.URL, len(request.Expected), len(failures))
+	}
+	if groupErr == nil && transactional {
+		return s.commitTransaction(ctx, request, members)
+	}
+	var errs = make([]string, 0)
+	for _, name := range request.Expected {
+		member := members[name]
+		if member.Action == nil || member.Action.Actions == nil {
+			continue
+		}
+		toRun, err := member.Action.ToRun(groupErr, &base.Job{})
+		if _, runErr := task.RunAll(ctx, s.Registry, toRun); runErr != nil {
+			err = runErr
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to commit group %v: %v", request.URL, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+//commitTransaction runs all members deferred statements (copy DML, MERGE, split) as one multi statement transaction,
+//so that either all or none of the group destination tables are modified, remaining actions run once the transaction commits
+func (s *service) commitTransaction(ctx context.Context, request *CommitRequest, members map[string]*Member) error {
+	tx := newTransaction()
+	var errs = make([]string, 0)
+	for _, name := range request.Expected {
+		member := members[name]
+		if member.Action == nil || member.Action.Actions == nil {
+			continue
+		}
+		toRun, err := member.Action.ToRun(nil, &base.Job{})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", name, err))
+		}
+		for _, action := range toRun {
+			tx.add(action)
+		}
+	}
+	if _, err := task.RunAll(ctx, s.Registry, tx.actions()); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to commit group %v: %v", request.URL, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *service) putMember(ctx context.Context, baseURL string, member *Member) error {
+	data, err := json.Marshal(member)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode group member: %v", member.Name)
+	}
+	URL := url.Join(baseURL, member.Name+shared.GroupMemberExt)
+	return base.RunWithRetries(func() error {
+		return s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data))
+	})
+}
+
+func (s *service) members(ctx context.Context, baseURL string) (map[string]*Member, error) {
+	objects, err := s.fs.List(ctx, baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list group members: %v", baseURL)
+	}
+	var result = make(map[string]*Member)
+	for _, object := range objects {
+		if object.IsDir() || path.Ext(object.Name()) != shared.GroupMemberExt {
+			continue
+		}
+		reader, err := s.fs.Download(ctx, object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download group member: %v", object.URL())
+		}
+		data, err := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read group member: %v", object.URL())
+		}
+		member := &Member{}
+		if err = json.Unmarshal(data, member); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode group member: %v", object.URL())
+		}
+		result[member.Name] = member
+	}
+	return result, nil
+}
+
+//BarrierRequest represents a barrier member completion request
+type BarrierRequest CommitRequest
+
+//NewBarrierAction creates a barrier action, finally actions are deferred till all members complete and then run as they are
+func NewBarrierAction(URL, member string, expected []string, finally *task.Actions) *task.Action {
+	barrierRequest := &BarrierRequest{
+		URL:      URL,
+		Member:   member,
+		Expected: expected,
+	}
+	result := &task.Action{
+		Action:  shared.ActionBarrier,
+		Actions: finally,
+	}
+	_ = result.SetRequest(barrierRequest)
+	return result
+}
+
+//NewCommitAction creates a group commit action, finally actions are deferred till all group members complete
+func NewCommitAction(URL, member string, expected []string, finally *task.Actions) *task.Action {
+	commitRequest := &CommitRequest{
+		URL:      URL,
+		Member:   member,
+		Expected: expected,
+	}
+	result := &task.Action{
+		Action:  shared.ActionCommit,
+		Actions: finally,
+	}
+	_ = result.SetRequest(commitRequest)
+	return result
+}
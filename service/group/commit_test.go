@@ -0,0 +1,278 @@
+package group
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/task"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	actions []string
+}
+
+func (r *recorder) Run(ctx context.Context, request *task.Action) (task.Response, error) {
+	r.actions = append(r.actions, request.RequestStringValue("Name"))
+	return nil, nil
+}
+
+type recorderRequest struct {
+	Name string
+}
+
+func TestService_Commit(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+
+	useCases := []struct {
+		description string
+		URL         string
+		members     []string
+		errors      map[string]string
+		expect      []string
+	}{
+		{
+			description: "all members succeeded",
+			URL:         "mem://localhost/group/case001/",
+			members:     []string{"db.fact", "db.dim"},
+			expect:      []string{"db.fact:success", "db.dim:success"},
+		},
+		{
+			description: "one member failed",
+			URL:         "mem://localhost/group/case002/",
+			members:     []string{"db.fact", "db.dim"},
+			errors:      map[string]string{"db.dim": "load failed"},
+			expect:      []string{"db.fact:failure", "db.dim:failure"},
+		},
+		{
+			description: "single member",
+			URL:         "mem://localhost/group/case003/",
+			members:     []string{"db.fact"},
+			expect:      []string{"db.fact:success"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		registry := task.NewRegistry()
+		recorder := &recorder{}
+		registry.RegisterService("recorder", recorder)
+		registry.RegisterAction("record", task.NewServiceAction("recorder", recorderRequest{}))
+		srv := New(registry, fs)
+
+		for i, member := range useCase.members {
+			onSuccess, _ := task.NewAction("record", map[string]interface{}{"Name": member + ":success"})
+			onFailure, _ := task.NewAction("record", map[string]interface{}{"Name": member + ":failure"})
+			action := NewCommitAction(useCase.URL, member, useCase.members, task.NewActions([]*task.Action{onSuccess}, []*task.Action{onFailure}))
+			request := &CommitRequest{URL: useCase.URL, Member: member, Expected: useCase.members, Error: useCase.errors[member]}
+			err := srv.Commit(ctx, request, action)
+			if !assert.Nil(t, err, useCase.description) {
+				continue
+			}
+			if i < len(useCase.members)-1 {
+				assert.Equal(t, 0, len(recorder.actions), useCase.description+" - pending commit")
+			}
+		}
+		assert.EqualValues(t, useCase.expect, recorder.actions, useCase.description)
+		exists, _ := fs.Exists(ctx, useCase.URL)
+		assert.False(t, exists, useCase.description+" - clean up")
+	}
+}
+
+type queryRecorder struct {
+	actions []*task.Action
+}
+
+func (r *queryRecorder) Run(ctx context.Context, request *task.Action) (task.Response, error) {
+	r.actions = append(r.actions, request)
+	return nil, nil
+}
+
+func TestService_Commit_Transaction(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+
+	useCases := []struct {
+		description string
+		URL         string
+		members     []string
+		queries     map[string][]*task.Action
+		expectSQL   string
+		expectDone  []string
+	}{
+		{
+			description: "members DML in one transaction",
+			URL:         "mem://localhost/group/tx001/",
+			members:     []string{"db.fact", "db.dim"},
+			queries: map[string][]*task.Action{
+				"db.fact": {bq.NewDMLAction("INSERT INTO `p.db.fact`(id) SELECT id FROM `p.temp.fact_1`", true, nil)},
+				"db.dim":  {bq.NewDMLAction("MERGE `p.db.dim` d USING `p.temp.dim_1` t ON d.id = t.id WHEN NOT MATCHED THEN INSERT ROW", true, nil)},
+			},
+			expectSQL: "BEGIN TRANSACTION;\n" +
+				"INSERT INTO `p.db.fact`(id) SELECT id FROM `p.temp.fact_1`;\n" +
+				"MERGE `p.db.dim` d USING `p.temp.dim_1` t ON d.id = t.id WHEN NOT MATCHED THEN INSERT ROW;\n" +
+				"COMMIT TRANSACTION;",
+			expectDone: []string{"db.fact:done", "db.dim:done"},
+		},
+		{
+			description: "member script unwrapped with DDL before transaction",
+			URL:         "mem://localhost/group/tx002/",
+			members:     []string{"db.fact", "db.dim"},
+			queries: map[string][]*task.Action{
+				"db.fact": {bq.NewDMLAction("CREATE TABLE IF NOT EXISTS `p.db.fact_x` LIKE `p.db.fact`;\nBEGIN TRANSACTION;\nINSERT INTO `p.db.fact_x`(id) SELECT id FROM `p.temp.fact_1`;\nCOMMIT TRANSACTION;", true, nil)},
+				"db.dim": {bq.NewDMLAction("SELECT IF(COUNT(1) > 0, TRUE, ERROR('empty')) FROM `p.temp.dim_1`", true,
+					task.NewActions([]*task.Action{bq.NewDMLAction("INSERT INTO `p.db.dim`(id) SELECT id FROM `p.temp.dim_1`", true, nil)}, nil))},
+			},
+			expectSQL: "CREATE TABLE IF NOT EXISTS `p.db.fact_x` LIKE `p.db.fact`;\n" +
+				"BEGIN TRANSACTION;\n" +
+				"INSERT INTO `p.db.fact_x`(id) SELECT id FROM `p.temp.fact_1`;\n" +
+				"SELECT IF(COUNT(1) > 0, TRUE, ERROR('empty')) FROM `p.temp.dim_1`;\n" +
+				"INSERT INTO `p.db.dim`(id) SELECT id FROM `p.temp.dim_1`;\n" +
+				"COMMIT TRANSACTION;",
+			expectDone: []string{"db.fact:done", "db.dim:done"},
+		},
+		{
+			description: "user queries run after commit",
+			URL:         "mem://localhost/group/tx003/",
+			members:     []string{"db.fact", "db.dim"},
+			queries: map[string][]*task.Action{
+				"db.fact": {bq.NewDMLAction("INSERT INTO `p.db.fact`(id) SELECT id FROM `p.temp.fact_1`", true,
+					task.NewActions([]*task.Action{
+						bq.NewQueryAction("SELECT COUNT(1) AS cnt FROM `p.db.fact`", &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "fact_summary"}, "", false, nil),
+						bq.NewQueryAction("DELETE FROM `p.db.fact_staging` WHERE TRUE", nil, "", true, nil),
+					}, nil))},
+				"db.dim": {bq.NewDMLAction("INSERT INTO `p.db.dim`(id) SELECT id FROM `p.temp.dim_1`", true, nil)},
+			},
+			expectSQL: "BEGIN TRANSACTION;\n" +
+				"INSERT INTO `p.db.fact`(id) SELECT id FROM `p.temp.fact_1`;\n" +
+				"INSERT INTO `p.db.dim`(id) SELECT id FROM `p.temp.dim_1`;\n" +
+				"COMMIT TRANSACTION;",
+			expectDone: []string{"SELECT COUNT(1) AS cnt FROM `p.db.fact`", "DELETE FROM `p.db.fact_staging` WHERE TRUE", "db.fact:done", "db.dim:done"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		registry := task.NewRegistry()
+		queries := &queryRecorder{}
+		registry.RegisterService("bq", queries)
+		registry.RegisterAction(shared.ActionQuery, task.NewServiceAction("bq", bq.QueryRequest{}))
+		srv := New(registry, fs)
+
+		for _, member := range useCase.members {
+			done, _ := task.NewAction("record", map[string]interface{}{"Name": member + ":done"})
+			failed, _ := task.NewAction("record", map[string]interface{}{"Name": member + ":failure"})
+			for _, query := range useCase.queries[member] {
+				if query.Actions == nil {
+					query.Actions = task.NewActions(nil, nil)
+				}
+				query.AddOnFailure(failed)
+			}
+			onSuccess := append(useCase.queries[member], done)
+			action := NewCommitAction(useCase.URL, member, useCase.members, task.NewActions(onSuccess, []*task.Action{failed}))
+			request := &CommitRequest{URL: useCase.URL, Member: member, Expected: useCase.members}
+			err := srv.Commit(ctx, request, action)
+			assert.Nil(t, err, useCase.description)
+		}
+		if !assert.Equal(t, 1, len(queries.actions), useCase.description) {
+			continue
+		}
+		query := queries.actions[0]
+		assert.EqualValues(t, useCase.expectSQL, query.RequestStringValue("SQL"), useCase.description)
+		var done = make([]string, 0)
+		for _, action := range query.OnSuccess {
+			if action.Action == shared.ActionQuery {
+				done = append(done, action.RequestStringValue("SQL"))
+				continue
+			}
+			done = append(done, action.RequestStringValue("Name"))
+		}
+		assert.EqualValues(t, useCase.expectDone, done, useCase.description)
+		assert.Equal(t, len(useCase.members), len(query.OnFailure), useCase.description+" - unique failure actions")
+	}
+}
+
+func TestService_Commit_Lock(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+
+	useCases := []struct {
+		description string
+		URL         string
+		lock        string
+		ttl         time.Duration
+		expect      []string
+	}{
+		{
+			description: "crashed commit lock taken over",
+			URL:         "mem://localhost/group/lock001/",
+			lock:        `{"Member":"db.dim","Taken":"2020-04-20T10:00:00Z"}`,
+			ttl:         time.Minute,
+			expect:      []string{"db.fact:success", "db.dim:success"},
+		},
+		{
+			description: "legacy lock taken over",
+			URL:         "mem://localhost/group/lock002/",
+			lock:        "db.dim",
+			expect:      []string{"db.fact:success", "db.dim:success"},
+		},
+		{
+			description: "active commit lock",
+			URL:         "mem://localhost/group/lock003/",
+			lock:        `{"Member":"db.dim","Taken":"` + time.Now().Format(time.RFC3339) + `"}`,
+			ttl:         time.Hour,
+			expect:      []string{},
+		},
+	}
+
+	for _, useCase := range useCases {
+		registry := task.NewRegistry()
+		recorder := &recorder{actions: []string{}}
+		registry.RegisterService("recorder", recorder)
+		registry.RegisterAction("record", task.NewServiceAction("recorder", recorderRequest{}))
+		srv := &service{Registry: registry, fs: fs, lockTTL: useCase.ttl}
+		members := []string{"db.fact", "db.dim"}
+		err := fs.Upload(ctx, url.Join(useCase.URL, commitLock), file.DefaultFileOsMode, strings.NewReader(useCase.lock))
+		assert.Nil(t, err, useCase.description)
+		for _, member := range members {
+			onSuccess, _ := task.NewAction("record", map[string]interface{}{"Name": member + ":success"})
+			action := NewCommitAction(useCase.URL, member, members, task.NewActions([]*task.Action{onSuccess}, nil))
+			err := srv.Commit(ctx, &CommitRequest{URL: useCase.URL, Member: member, Expected: members}, action)
+			assert.Nil(t, err, useCase.description)
+		}
+		assert.EqualValues(t, useCase.expect, recorder.actions, useCase.description)
+	}
+}
+
+func TestService_Barrier(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	URL := "mem://localhost/group/barrier001/"
+	members := []string{"000", "001"}
+	registry := task.NewRegistry()
+	queries := &queryRecorder{}
+	registry.RegisterService("bq", queries)
+	registry.RegisterAction(shared.ActionQuery, task.NewServiceAction("bq", bq.QueryRequest{}))
+	srv := New(registry, fs)
+	query := bq.NewQueryAction("SELECT id FROM `p.temp.fact_1`", &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "fact"}, "", false, nil)
+	for i, member := range members {
+		var deferred *task.Actions
+		if i == 0 {
+			deferred = task.NewActions([]*task.Action{query}, nil)
+		}
+		action := NewBarrierAction(URL, member, members, deferred)
+		err := srv.Barrier(ctx, &BarrierRequest{URL: URL, Member: member, Expected: members}, action)
+		assert.Nil(t, err)
+	}
+	if !assert.Equal(t, 1, len(queries.actions)) {
+		return
+	}
+	assert.Equal(t, "SELECT id FROM `p.temp.fact_1`", queries.actions[0].RequestStringValue("SQL"))
+	assert.Equal(t, "p:db.fact", queries.actions[0].RequestStringValue("Dest"))
+}
@@ -0,0 +1,15 @@
+package group
+
+import (
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/task"
+)
+
+const id = "group"
+
+//InitRegistry initialises registry with group actions
+func InitRegistry(registry task.Registry, service Service) {
+	registry.RegisterService(id, service)
+	registry.RegisterAction(shared.ActionCommit, task.NewServiceAction(id, CommitRequest{}))
+	registry.RegisterAction(shared.ActionBarrier, task.NewServiceAction(id, BarrierRequest{}))
+}
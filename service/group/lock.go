@@ -0,0 +1,88 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"io/ioutil"
+	"time"
+)
+
+//commitLockTTL exceeds max cloud function execution time, so that only lock of a crashed commit expires
+const commitLockTTL = 10 * time.Minute
+
+//lock represents group commit lock
+type lock struct {
+	Member string
+	Taken  time.Time
+}
+
+//IsExpired returns true if lock taken before ttl elapsed
+func (l *lock) IsExpired(now time.Time, ttl time.Duration) bool {
+	return !now.Before(l.Taken.Add(ttl))
+}
+
+//acquireLock takes group commit lock, lock of a crashed commit is taken over once expired, it returns false if other member commits the group
+func (s *service) acquireLock(ctx context.Context, URL, member string) (bool, error) {
+	taken, err := s.uploadLock(ctx, URL, member, option.NewGeneration(true, 0))
+	if err != nil || taken {
+		return taken, err
+	}
+	object, err := s.fs.Object(ctx, URL, option.NewObjectKind(true))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get group commit lock: %v", URL)
+	}
+	holder, err := s.loadLock(ctx, object)
+	if err != nil {
+		return false, err
+	}
+	if !holder.IsExpired(time.Now(), s.lockTTL) {
+		return false, nil
+	}
+	if shared.IsInfoLoggingLevel() {
+		shared.LogF("[%v] taking over expired group commit lock of %v taken at %v\n", URL, holder.Member, holder.Taken)
+	}
+	return s.uploadLock(ctx, URL, member, base.GenerationMatch(object))
+}
+
+//uploadLock uploads lock with generation precondition, it returns false if other member uploaded the lock first
+func (s *service) uploadLock(ctx context.Context, URL, member string, generation *option.Generation) (bool, error) {
+	data, err := json.Marshal(&lock{Member: member, Taken: time.Now()})
+	if err != nil {
+		return false, err
+	}
+	err = s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data), generation)
+	if base.IsPreConditionError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to acquire group commit lock: %v", URL)
+	}
+	return true, nil
+}
+
+//loadLock loads a lock, lock without taken time (created by previous version) is timed with its modification time
+func (s *service) loadLock(ctx context.Context, object storage.Object) (*lock, error) {
+	reader, err := s.fs.Download(ctx, object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download group commit lock: %v", object.URL())
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read group commit lock: %v", object.URL())
+	}
+	result := &lock{}
+	if err = json.Unmarshal(data, result); err != nil || result.Taken.IsZero() {
+		result = &lock{Member: string(data), Taken: object.ModTime()}
+	}
+	return result, nil
+}
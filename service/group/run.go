@@ -0,0 +1,18 @@
+package group
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/task"
+)
+
+//Run handles group request
+func (s *service) Run(ctx context.Context, request *task.Action) (task.Response, error) {
+	switch req := request.ServiceRequest().(type) {
+	case *CommitRequest:
+		return nil, s.Commit(ctx, req, request)
+	case *BarrierRequest:
+		return nil, s.Barrier(ctx, req, request)
+	}
+	return nil, errors.Errorf("unsupported request type:%T", request)
+}
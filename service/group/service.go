@@ -0,0 +1,34 @@
+package group
+
+import (
+	"context"
+	"github.com/viant/afs"
+	"github.com/viant/bqtail/task"
+	"time"
+)
+
+//Service represents group commit service
+type Service interface {
+	task.Service
+
+	//Commit records group member status, the last completed member commits the whole group
+	Commit(ctx context.Context, request *CommitRequest, action *task.Action) error
+
+	//Barrier records member status, the last completed member runs all members deferred actions
+	Barrier(ctx context.Context, request *BarrierRequest, action *task.Action) error
+}
+
+type service struct {
+	task.Registry
+	fs      afs.Service
+	lockTTL time.Duration
+}
+
+//New creates a group service
+func New(registry task.Registry, fs afs.Service) Service {
+	return &service{
+		Registry: registry,
+		fs:       fs,
+		lockTTL:  commitLockTTL,
+	}
+}
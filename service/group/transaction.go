@@ -0,0 +1,128 @@
+package group
+
+import (
+	"encoding/json"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage/activity"
+	"github.com/viant/bqtail/task"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+const (
+	beginTransaction  = "BEGIN TRANSACTION;"
+	commitTransaction = "COMMIT TRANSACTION;"
+)
+
+//transaction represents group members statements combined into one multi statement transaction
+type transaction struct {
+	meta      *activity.Meta
+	prelude   []string
+	body      []string
+	onSuccess []*task.Action
+	onFailure []*task.Action
+	failures  map[string]bool
+}
+
+//add adds generated DML statements with its OnSuccess statements chain to the transaction,
+//any other action (copy, user query, conditional action) runs with its follow up actions once the transaction commits
+func (t *transaction) add(action *task.Action) {
+	prelude, body, ok := statements(action)
+	if !ok {
+		t.onSuccess = append(t.onSuccess, action)
+		return
+	}
+	if t.meta == nil {
+		t.meta = action.Meta
+	}
+	t.prelude = append(t.prelude, prelude...)
+	t.body = append(t.body, body...)
+	if action.Actions == nil {
+		return
+	}
+	t.addOnFailure(action.OnFailure...)
+	for _, child := range action.OnSuccess {
+		t.add(child)
+	}
+}
+
+//addOnFailure adds unique failure actions, each group member propagates the same OnFailure actions down its chain
+func (t *transaction) addOnFailure(actions ...*task.Action) {
+	for _, action := range actions {
+		key, err := json.Marshal(action)
+		if err == nil && t.failures[string(key)] {
+			continue
+		}
+		t.failures[string(key)] = true
+		t.onFailure = append(t.onFailure, action)
+	}
+}
+
+//script returns multi statement script, DDL statements run before the transaction as BigQuery does not allow them within a transaction
+func (t *transaction) script() string {
+	var script = make([]string, 0)
+	for _, statement := range t.prelude {
+		script = append(script, statement+";")
+	}
+	script = append(script, beginTransaction)
+	for _, statement := range t.body {
+		script = append(script, statement+";")
+	}
+	script = append(script, commitTransaction)
+	return strings.Join(script, "\n")
+}
+
+//actions returns actions to run, a single transaction query when members have any statement, OnSuccess follow up actions otherwise
+func (t *transaction) actions() []*task.Action {
+	if len(t.body) == 0 && len(t.prelude) == 0 {
+		return t.onSuccess
+	}
+	result := bq.NewQueryAction(t.script(), nil, "", true, task.NewActions(t.onSuccess, t.onFailure))
+	result.Meta = t.meta
+	return []*task.Action{result}
+}
+
+//statements returns generated DML action prelude (DDL) and transaction body statements, ok is false if action is not a transactional statement
+func statements(action *task.Action) (prelude, body []string, ok bool) {
+	if action.Action != shared.ActionQuery || action.When != "" {
+		return nil, nil, false
+	}
+	request := &bq.QueryRequest{}
+	if err := toolbox.DefaultConverter.AssignConverted(request, action.Request); err != nil {
+		return nil, nil, false
+	}
+	if !request.Transactional || request.SQL == "" || request.UseLegacy || request.DatasetID != "" || request.Dest != "" {
+		return nil, nil, false
+	}
+	SQL := strings.TrimSpace(request.SQL)
+	begin := strings.Index(SQL, beginTransaction)
+	if begin == -1 {
+		return nil, []string{trimStatement(SQL)}, true
+	}
+	end := strings.LastIndex(SQL, commitTransaction)
+	if end < begin {
+		return nil, nil, false
+	}
+	if statement := trimStatement(SQL[:begin]); statement != "" {
+		prelude = append(prelude, statement)
+	}
+	if statement := trimStatement(SQL[begin+len(beginTransaction) : end]); statement != "" {
+		body = append(body, statement)
+	}
+	return prelude, body, true
+}
+
+func trimStatement(SQL string) string {
+	return strings.TrimSuffix(strings.TrimSpace(SQL), ";")
+}
+
+func newTransaction() *transaction {
+	return &transaction{
+		prelude:   make([]string, 0),
+		body:      make([]string, 0),
+		onSuccess: make([]*task.Action, 0),
+		onFailure: make([]*task.Action, 0),
+		failures:  make(map[string]bool),
+	}
+}
@@ -0,0 +1,55 @@
+package pubsub
+
+import (
+	"context"
+	"github.com/viant/bqtail/base"
+	"google.golang.org/api/pubsub/v1"
+)
+
+//Pull pulls messages from subscription, returned messages need to be acknowledged
+func (s *service) Pull(ctx context.Context, subscription string, maxMessages int) ([]*pubsub.ReceivedMessage, error) {
+	call := pubsub.NewProjectsService(s.Service).Subscriptions.Pull(s.subscriptionInProject(subscription), &pubsub.PullRequest{
+		MaxMessages: int64(maxMessages),
+	})
+	call.Context(ctx)
+	var response *pubsub.PullResponse
+	err := base.RunWithRetries(func() (err error) {
+		response, err = call.Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.ReceivedMessages, nil
+}
+
+//Acknowledge acknowledges pulled messages
+func (s *service) Acknowledge(ctx context.Context, subscription string, ackIDs []string) error {
+	if len(ackIDs) == 0 {
+		return nil
+	}
+	call := pubsub.NewProjectsService(s.Service).Subscriptions.Acknowledge(s.subscriptionInProject(subscription), &pubsub.AcknowledgeRequest{
+		AckIds: ackIDs,
+	})
+	call.Context(ctx)
+	return base.RunWithRetries(func() error {
+		_, err := call.Do()
+		return err
+	})
+}
+
+//Nack releases pulled messages for immediate redelivery
+func (s *service) Nack(ctx context.Context, subscription string, ackIDs []string) error {
+	if len(ackIDs) == 0 {
+		return nil
+	}
+	call := pubsub.NewProjectsService(s.Service).Subscriptions.ModifyAckDeadline(s.subscriptionInProject(subscription), &pubsub.ModifyAckDeadlineRequest{
+		AckIds:          ackIDs,
+		ForceSendFields: []string{"AckDeadlineSeconds"},
+	})
+	call.Context(ctx)
+	return base.RunWithRetries(func() error {
+		_, err := call.Do()
+		return err
+	})
+}
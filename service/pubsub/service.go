@@ -6,14 +6,24 @@ import (
 	"github.com/viant/bqtail/task"
 	"google.golang.org/api/option"
 	"google.golang.org/api/pubsub/v1"
+	"os"
 	"strings"
 )
 
+//EmulatorHostEnvKey Pub/Sub emulator host env key
+const EmulatorHostEnvKey = "PUBSUB_EMULATOR_HOST"
+
 //Service represents big query service
 type Service interface {
 	task.Service
 
 	Publish(ctx context.Context, request *PushRequest, action *task.Action) (task.Response, error)
+
+	Pull(ctx context.Context, subscription string, maxMessages int) ([]*pubsub.ReceivedMessage, error)
+
+	Acknowledge(ctx context.Context, subscription string, ackIDs []string) error
+
+	Nack(ctx context.Context, subscription string, ackIDs []string) error
 }
 
 type service struct {
@@ -28,6 +38,22 @@ func (s *service) topicInProject(request *PushRequest) string {
 	return fmt.Sprintf("projects/%s/topics/%s", request.ProjectID, request.Topic)
 }
 
+func (s *service) subscriptionInProject(subscription string) string {
+	if strings.Count(subscription, "/") > 0 {
+		return subscription
+	}
+	return fmt.Sprintf("projects/%s/subscriptions/%s", s.ProjectID, subscription)
+}
+
+//EmulatorOptions returns Pub/Sub emulator client options if emulator host env is set
+func EmulatorOptions() []option.ClientOption {
+	host := os.Getenv(EmulatorHostEnvKey)
+	if host == "" {
+		return nil
+	}
+	return []option.ClientOption{option.WithEndpoint("http://" + host + "/"), option.WithoutAuthentication()}
+}
+
 //New creates a service
 func New(ctx context.Context, projectID string, options ...option.ClientOption) (Service, error) {
 	srv, err := pubsub.NewService(ctx, options...)
@@ -33,8 +33,14 @@ const (
 	WindowExt = ".win"
 	//LocationExt location extension
 	LocationExt = ".loc"
+	//ClosedWindowExt early closed batch window extension
+	ClosedWindowExt = ".cwin"
 	//CounterExt counter file extension
 	CounterExt = ".cnt"
+	//GroupMemberExt group member commit status extension
+	GroupMemberExt = ".grp"
+	//LockExt lock file extension
+	LockExt = ".lck"
 )
 
 //Process action
@@ -61,18 +67,24 @@ const (
 	ActionCall = "call"
 	//ActionPush action pubusb push
 	ActionPush = "push"
+	//ActionCommit group commit action
+	ActionCommit = "commit"
+	//ActionBarrier barrier action deferring actions till all members complete
+	ActionBarrier = "barrier"
 )
 
 //Actionable  action with action meta
 var Actionable = map[string]bool{
-	ActionLoad:   true,
-	ActionReload: true,
-	ActionCopy:   true,
-	ActionQuery:  true,
-	ActionExport: true,
-	ActionDrop:   true,
-	ActionCall:   true,
-	ActionPush:   true,
+	ActionLoad:    true,
+	ActionReload:  true,
+	ActionCopy:    true,
+	ActionQuery:   true,
+	ActionExport:  true,
+	ActionDrop:    true,
+	ActionCall:    true,
+	ActionPush:    true,
+	ActionCommit:  true,
+	ActionBarrier: true,
 }
 
 const (
@@ -137,6 +149,10 @@ const (
 	BatchPrefix = "/_batch_/"
 	//InvalidSchemaLocation invalid schema
 	InvalidSchemaLocation = "invalid_schema"
+	//LateLocation late arrival data files journal location
+	LateLocation = "late"
+	//QuarantineLocation bad records journal location
+	QuarantineLocation = "quarantine"
 	//DoneLoadSuffix load done suffix
 	DoneLoadSuffix = "Done"
 	//ActiveLoadSuffix active done suffix
@@ -159,6 +175,29 @@ const (
 //PerformanceFile defines job performance file
 const PerformanceFile = "performance.json"
 
+//LeaseFile defines dispatch daemon leader lease file
+const LeaseFile = "dispatch.lease"
+
+//CursorLocation defines dispatch job listing cursor location
+const CursorLocation = "cursor"
+
+//ManifestFile defines batch window data files manifest
+const ManifestFile = "manifest.json"
+
+//ProcessedFile defines processed batch window data files record
+const ProcessedFile = "processed.json"
+
+//LateArrivalHorizonInSec defines default late arrival horizon, processed batch window record is deleted afterwards
+const LateArrivalHorizonInSec = 24 * 3600
+
+//BigQuery load job limits
+const (
+	//MaxLoadURIs max source URIs per load job
+	MaxLoadURIs = 10000
+	//MaxLoadBytes max data files size per load job (15 TB)
+	MaxLoadBytes = int64(15) << 40
+)
+
 //DateLayout represents a date layout
 const DateLayout = "2006-01-02_15"
 
@@ -207,9 +246,30 @@ const (
 	WriteDispositionAppend = "WRITE_APPEND"
 )
 
+const (
+	//AllowFieldAddition load schema update option allowing new fields
+	AllowFieldAddition = "ALLOW_FIELD_ADDITION"
+	//AllowFieldRelaxation load schema update option allowing REQUIRED to NULLABLE mode change
+	AllowFieldRelaxation = "ALLOW_FIELD_RELAXATION"
+)
+
 //ClientSecretURL client secret
 const ClientSecretURL = "mem://github.com/viant/bqtail/auth/key.json"
 
+const (
+	//RuleModeLoad rule ingesting data with load jobs
+	RuleModeLoad = "load"
+	//RuleModeStream rule ingesting data with streaming inserts
+	RuleModeStream = "stream"
+)
+
+//Streaming insert limits, request payload is kept below 10MB HTTP request limit
+const (
+	MaxStreamRows    = 500
+	MaxStreamBytes   = 9 << 20
+	MaxStreamRetries = 3
+)
+
 //CopyMethodCopy represents a copy with Copy job
 var CopyMethodCopy = "COPY"
 
@@ -218,3 +278,33 @@ var CopyMethodQuery = "QUERY"
 
 //CopyMethodDML represents a copy with Query job and INSERT INTO AS SELECT DML
 var CopyMethodDML = "DML"
+
+//CopyMethodMerge represents a copy with Query job and MERGE DML keyed on unique columns
+var CopyMethodMerge = "MERGE"
+
+//CopyMethodReplace represents a copy with Query job script replacing destination partitions present in transient table
+var CopyMethodReplace = "REPLACE"
+
+const (
+	//MergeMatchedUpdate updates matched destination rows
+	MergeMatchedUpdate = "UPDATE"
+	//MergeMatchedIgnore keeps matched destination rows intact
+	MergeMatchedIgnore = "IGNORE"
+)
+
+const (
+	//SplitExecutionSequential runs split mapping queries one after another
+	SplitExecutionSequential = "sequential"
+	//SplitExecutionParallel runs split mapping queries concurrently, split completes once all queries completed
+	SplitExecutionParallel = "parallel"
+	//SplitExecutionScript runs split mapping statements as one multi statement transaction
+	SplitExecutionScript = "script"
+	//SplitUnmatchedDrop ignores rows not matching any split mapping
+	SplitUnmatchedDrop = "drop"
+	//SplitUnmatchedFail fails split when a row does not match any split mapping
+	SplitUnmatchedFail = "fail"
+	//SplitLocation parallel split group commit location
+	SplitLocation = "split"
+	//FanOutLocation multiple destinations barrier location
+	FanOutLocation = "fanout"
+)
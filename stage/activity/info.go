@@ -14,6 +14,8 @@ const (
 
 	//URLsKey URLs keys
 	URLsKey = "URLs"
+	//priorityPrefix job ID priority element prefix
+	priorityPrefix = "p"
 )
 
 //Meta represents processing stage meta data
@@ -27,7 +29,15 @@ type Meta struct {
 
 //ID returns stage ID
 func (i *Meta) ID() string {
-	return path.Join(i.DestTable, fmt.Sprintf("%v_%05d_%v", i.EventID, i.Step%99999, i.Action)+shared.PathElementSeparator+i.Mode)
+	return path.Join(i.DestTable, fmt.Sprintf("%v_%05d_%v", i.EventID, i.Step%99999, i.Action)+i.prioritySuffix()+shared.PathElementSeparator+i.Mode)
+}
+
+//prioritySuffix returns priority job ID element, empty for default priority
+func (i *Meta) prioritySuffix() string {
+	if i.Priority <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("_%v%v", priorityPrefix, i.Priority)
 }
 
 //JobFilename returns job filename
@@ -40,7 +50,16 @@ func (i *Meta) JobFilename() string {
 	if i.ProjectID != "" {
 		baseLocation = shared.TempProjectPrefix + i.ProjectID + ":" + i.Region + "/"
 	}
-	return baseLocation + dest + fmt.Sprintf("%v_%05d_%v", i.EventID, i.Step%99999, i.Action) + shared.PathElementSeparator + i.Mode
+	return baseLocation + dest + fmt.Sprintf("%v_%05d_%v", i.EventID, i.Step%99999, i.Action) + i.prioritySuffix() + shared.PathElementSeparator + i.Mode
+}
+
+//TaskFilename returns async job task filename, unlike JobFilename it uses encoded job ID, so that task can be located by BigQuery job ID
+func (i *Meta) TaskFilename() string {
+	baseLocation := ""
+	if i.ProjectID != "" {
+		baseLocation = shared.TempProjectPrefix + i.ProjectID + ":" + i.Region + "/"
+	}
+	return baseLocation + i.GetJobID()
 }
 
 //Sequence returns step sequence
@@ -116,6 +135,9 @@ func Parse(encoded string) *Meta {
 			result.Step = toolbox.AsInt(eventElements[1])
 			result.Action = eventElements[2]
 		}
+		if len(eventElements) > 3 && strings.HasPrefix(eventElements[3], priorityPrefix) {
+			result.Priority = toolbox.AsInt(eventElements[3][len(priorityPrefix):])
+		}
 	}
 	result.Async = strings.HasSuffix(result.Mode, shared.StepModeDispach)
 	return result
@@ -3,6 +3,7 @@ package activity
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/assertly"
+	"github.com/viant/bqtail/stage"
 	"github.com/viant/toolbox"
 	"testing"
 )
@@ -29,6 +30,11 @@ func TestParse(t *testing.T) {
 			encoded:     "github.com/viant/bqtail:dummy/869694905034386_0004_load/dispatch",
 			expect:      `{"DestTable":"github.com/viant/bqtail:dummy","EventID":"869694905034386","Action":"load","Mode":"dispatch","Meta":4}`,
 		},
+		{
+			description: "info style priority",
+			encoded:     "github.com/viant/bqtail_dummy--869694905034386_00004_load_p5--dispatch",
+			expect:      `{"DestTable":"github.com/viant/bqtail_dummy","EventID":"869694905034386","Action":"load","Mode":"dispatch","Priority":5}`,
+		},
 		{
 			description: "invalid",
 			encoded:     "github.com/viant/bqtail869694905034386--tail",
@@ -105,3 +111,29 @@ func TestInfo_ChildInfo(t *testing.T) {
 		assert.Equal(t, useCase.expect, child.ID(), useCase.description)
 	}
 }
+
+func TestMeta_TaskFilename(t *testing.T) {
+	var useCases = []struct {
+		description string
+		meta        *Meta
+		expect      string
+	}{
+		{
+			description: "project task",
+			meta:        &Meta{Process: stage.Process{DestTable: "mydataset.mytable$20200101", EventID: "123", ProjectID: "p1", Region: "US"}, Action: "load", Mode: "dispatch", Step: 2},
+			expect:      "proj:p1:US/mydataset_mytable_20200101--123_00002_load--dispatch",
+		},
+		{
+			description: "default project task",
+			meta:        &Meta{Process: stage.Process{DestTable: "mydataset.mytable", EventID: "124", Priority: 3}, Action: "query", Mode: "dispatch", Step: 3},
+			expect:      "mydataset_mytable--124_00003_query_p3--dispatch",
+		},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expect, useCase.meta.TaskFilename(), useCase.description)
+		parsed := Parse(useCase.meta.GetJobID())
+		parsed.ProjectID = useCase.meta.ProjectID
+		parsed.Region = useCase.meta.Region
+		assert.EqualValues(t, useCase.expect, parsed.TaskFilename(), useCase.description)
+	}
+}
@@ -13,7 +13,11 @@ func (j *Job) buildActions() (*task.Actions, error) {
 	}
 	j.buildProcessActions(actions)
 	result, err := j.buildTransientActions(actions)
-	return result, err
+	if err != nil {
+		return nil, err
+	}
+	result = j.buildAssertionActions(result)
+	return j.buildGroupActions(result), nil
 }
 
 //buildDoneProcessAction append track action
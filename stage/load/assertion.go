@@ -0,0 +1,35 @@
+package load
+
+import (
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/service/storage"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/sql"
+	"github.com/viant/bqtail/task"
+	"path"
+	"strings"
+)
+
+//buildAssertionActions runs dest assertions against loaded transient table before supplied copy actions,
+//failed assertion skips copy, moves process to failed location and runs copy failure actions
+func (j *Job) buildAssertionActions(actions *task.Actions) *task.Actions {
+	dest := j.Rule.Dest
+	if len(dest.Assertions) == 0 || dest.Transient == nil {
+		return actions
+	}
+	next := task.NewActions(nil, nil)
+	next.AddOnSuccess(actions.OnSuccess...)
+	next.AddOnFailure(actions.OnFailure...)
+	if j.FailedURL != "" {
+		moveRequest := storage.MoveRequest{SourceURL: j.ProcessURL, DestURL: url.Join(j.FailedURL, path.Base(j.ProcessURL)), IsDestAbsoluteURL: true}
+		moveAction, _ := task.NewAction(shared.ActionMove, moveRequest)
+		next.AddOnFailure(moveAction)
+	}
+	SQL := sql.BuildAssertion(j.Load.DestinationTable, dest.Transient.Alias, dest.Assertions)
+	SQL = strings.Replace(SQL, "$WHERE", j.getDMLWhereClause(), 1)
+	result := task.NewActions(nil, nil)
+	result.AddOnSuccess(bq.NewQueryAction(SQL, nil, "", true, next))
+	result.AddOnFailure(actions.OnFailure...)
+	return result
+}
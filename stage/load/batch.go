@@ -17,6 +17,9 @@ func buildBatchActions(window *batch.Window, actions *task.Actions) {
 	if len(window.Locations) > 0 {
 		URLsToDelete = append(URLsToDelete, window.Locations...)
 	}
+	if len(window.TrackingURLs) > 0 {
+		URLsToDelete = append(URLsToDelete, window.TrackingURLs...)
+	}
 	deleteReq := storage.DeleteRequest{URLs: URLsToDelete}
 	deleteAction, _ := task.NewAction(shared.ActionDelete, deleteReq)
 	actions.AddOnSuccess(deleteAction)
@@ -0,0 +1,33 @@
+package load
+
+import (
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/service/group"
+	"github.com/viant/bqtail/task"
+)
+
+//Group represents grouped batch member info
+type Group struct {
+	URL      string
+	Member   string
+	Expected []string
+}
+
+//buildGroupActions defers supplied actions till all group members load completes
+func (j *Job) buildGroupActions(actions *task.Actions) *task.Actions {
+	if j.Group == nil {
+		return actions
+	}
+	result := task.NewActions(nil, nil)
+	result.AddOnSuccess(group.NewCommitAction(j.Group.URL, j.Group.Member, j.Group.Expected, actions))
+	result.AddOnFailure(group.NewCommitAction(j.Group.URL, j.Group.Member, j.Group.Expected, actions))
+	return result
+}
+
+//barrierURL returns job barrier location within journal
+func (j *Job) barrierURL(location string) string {
+	activeURL, _ := url.Split(j.ProcessURL, file.Scheme)
+	journalURL, _ := url.Split(activeURL, file.Scheme)
+	return url.Join(journalURL, location, j.DestTable, j.EventID)
+}
@@ -23,6 +23,10 @@ func (j *Job) Init(ctx context.Context, service bq.Service) error {
 		return err
 	}
 
+	if err = j.initDestsSchema(ctx, service); err != nil {
+		return err
+	}
+
 	if j.Rule.Dest.HasSplit() {
 		if err = j.initTableSplit(ctx, service); err != nil {
 			return errors.Wrapf(err, "failed to apply split schema optimization: %+v", j.Rule.Dest.Schema.Split)
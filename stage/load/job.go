@@ -21,6 +21,8 @@ type Job struct {
 	Rule               *config.Rule                   `json:"-"`
 	Status             string                         `json:",omitempty"`
 	Window             *batch.Window                  `json:",omitempty"`
+	Group              *Group                         `json:",omitempty"`
+	Part               *Part                          `json:",omitempty"`
 	Statistics         *bigquery.JobStatistics        `json:"statistics,omitempty"`
 	JobStatus          *bigquery.JobStatus            `json:"jobStatus,omitempty"`
 	Load               *bigquery.JobConfigurationLoad `json:"load,ommittempty"`
@@ -28,6 +30,7 @@ type Job struct {
 	SplitSchema        *bigquery.Table                `json:",omitempty"`
 	IsTablePartitioned bool                           `json:",omitempty"`
 	DestSchema         *bigquery.Table                `json:",omitempty"`
+	DestSchemas        []*bigquery.Table              `json:",omitempty"`
 	Actions            *task.Actions                  `json:",omitempty"`
 	BqJob              *bigquery.Job                  `json:"-"`
 	splitColumns       []*bigquery.TableFieldSchema
@@ -0,0 +1,107 @@
+package load
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/service/group"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/batch"
+	"github.com/viant/bqtail/task"
+	"strings"
+)
+
+const partsFolder = "parts"
+
+//Part represents batch load job part, window data files exceeding load job limits are loaded by multiple jobs
+type Part struct {
+	*Group
+	//URIs all parts data files, used to expand deferred actions
+	URIs []string `json:",omitempty"`
+}
+
+//Split splits window job exceeding load job limits into parts loading into the same table,
+//init initialises each additional part process, job actions are deferred till all parts complete
+func (j *Job) Split(maxURIs int, maxBytes int64, init func(process *stage.Process)) ([]*Job, error) {
+	if j.Window == nil {
+		return []*Job{j}, nil
+	}
+	partURIs := partition(j.Load.SourceUris, j.Window.Resources, maxURIs, maxBytes)
+	if len(partURIs) <= 1 {
+		return []*Job{j}, nil
+	}
+	if j.Rule.Dest.Transient == nil && !j.Rule.IsAppend() {
+		return nil, errors.Errorf("failed to split %v data files into %v load jobs: Dest.Transient or append mode is required", len(j.Load.SourceUris), len(partURIs))
+	}
+	URL := strings.Replace(j.Window.URL, shared.WindowExt, "/"+partsFolder+"/", 1)
+	var expected = make([]string, len(partURIs))
+	for i := range partURIs {
+		expected[i] = fmt.Sprintf("%03d", i)
+	}
+	var result = make([]*Job, len(partURIs))
+	for i, URIs := range partURIs {
+		part := &Part{Group: &Group{URL: URL, Member: expected[i], Expected: expected}}
+		load := *j.Load
+		load.SourceUris = URIs
+		//parts run concurrently, none of them can truncate the table
+		load.WriteDisposition = shared.WriteDispositionAppend
+		if i == 0 {
+			part.URIs = j.Load.SourceUris
+			j.Load = &load
+			j.Part = part
+			j.Actions = j.buildPartActions(j.Actions)
+			result[i] = j
+			continue
+		}
+		process := *j.Process
+		process.EventID = fmt.Sprintf("%vp%v", j.EventID, i)
+		process.StepCount = 0
+		init(&process)
+		job := &Job{
+			Status:             shared.StatusOK,
+			Rule:               j.Rule,
+			Process:            &process,
+			Window:             j.Window,
+			Part:               part,
+			Load:               &load,
+			IsTablePartitioned: j.IsTablePartitioned,
+		}
+		actions := task.NewActions(nil, nil)
+		job.buildProcessActions(actions)
+		job.Actions = job.buildPartActions(actions)
+		result[i] = job
+	}
+	return result, nil
+}
+
+//buildPartActions defers supplied actions till all job parts load completes, then they run as they are
+func (j *Job) buildPartActions(actions *task.Actions) *task.Actions {
+	result := task.NewActions(nil, nil)
+	result.AddOnSuccess(group.NewBarrierAction(j.Part.URL, j.Part.Member, j.Part.Expected, actions))
+	result.AddOnFailure(group.NewBarrierAction(j.Part.URL, j.Part.Member, j.Part.Expected, actions))
+	return result
+}
+
+//partition splits URIs into parts, each part does not exceed max URIs and max bytes limits
+func partition(URIs []string, resources []*batch.Resource, maxURIs int, maxBytes int64) [][]string {
+	var sizes = make(map[string]int64)
+	for _, resource := range resources {
+		sizes[resource.URL] = resource.Size
+	}
+	var result = make([][]string, 0)
+	var part = make([]string, 0)
+	partSize := int64(0)
+	for _, URI := range URIs {
+		size := sizes[URI]
+		if len(part) > 0 && (len(part) >= maxURIs || (maxBytes > 0 && partSize+size > maxBytes)) {
+			result = append(result, part)
+			part, partSize = make([]string, 0), 0
+		}
+		part = append(part, URI)
+		partSize += size
+	}
+	if len(part) > 0 {
+		result = append(result, part)
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package load
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/batch"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/task"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestJob_Split(t *testing.T) {
+	var useCases = []struct {
+		description string
+		URIs        int
+		size        int64
+		maxURIs     int
+		maxBytes    int64
+		transient   bool
+		expectParts []int
+		hasError    bool
+	}{
+		{
+			description: "within limits",
+			URIs:        5,
+			size:        10,
+			maxURIs:     10,
+			maxBytes:    1000,
+			transient:   true,
+			expectParts: []int{5},
+		},
+		{
+			description: "URIs limit with remainder",
+			URIs:        7,
+			size:        10,
+			maxURIs:     3,
+			maxBytes:    1000,
+			transient:   true,
+			expectParts: []int{3, 3, 1},
+		},
+		{
+			description: "bytes limit",
+			URIs:        5,
+			size:        40,
+			maxURIs:     10,
+			maxBytes:    100,
+			transient:   true,
+			expectParts: []int{2, 2, 1},
+		},
+		{
+			description: "truncate destination without transient",
+			URIs:        4,
+			size:        10,
+			maxURIs:     2,
+			maxBytes:    1000,
+			hasError:    true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		override := true
+		rule := &config.Rule{Dest: &config.Destination{Table: "mydataset.mytable", Override: &override}}
+		if useCase.transient {
+			rule.Dest.Transient = &config.Transient{Dataset: "temp"}
+		}
+		window := &batch.Window{URL: "mem://localhost/tasks/mytable_123.win"}
+		var URIs = make([]string, 0)
+		for i := 0; i < useCase.URIs; i++ {
+			URI := fmt.Sprintf("gs://bucket/data/%03d.json", i)
+			URIs = append(URIs, URI)
+			window.Resources = append(window.Resources, &batch.Resource{URL: URI, Size: useCase.size})
+		}
+		window.URIs = URIs
+		job := &Job{
+			Rule:    rule,
+			Window:  window,
+			Process: &stage.Process{EventID: "123", DestTable: "mydataset.mytable", ProcessURL: "mem://localhost/active/mydataset.mytable--123.run"},
+			Load:    &bigquery.JobConfigurationLoad{SourceUris: URIs, WriteDisposition: shared.WriteDispositionTruncate},
+			Actions: task.NewActions(nil, nil),
+		}
+		parts, err := job.Split(useCase.maxURIs, useCase.maxBytes, func(process *stage.Process) {
+			process.ProcessURL = "mem://localhost/active/" + process.DestTable + "--" + process.EventID + ".run"
+		})
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) || !assert.Equal(t, len(useCase.expectParts), len(parts), useCase.description) {
+			continue
+		}
+		if len(parts) == 1 {
+			assert.Nil(t, parts[0].Part, useCase.description)
+			assert.Equal(t, shared.WriteDispositionTruncate, parts[0].Load.WriteDisposition, useCase.description)
+			continue
+		}
+		var loaded = make([]string, 0)
+		for i, part := range parts {
+			assert.Equal(t, useCase.expectParts[i], len(part.Load.SourceUris), useCase.description)
+			assert.Equal(t, shared.WriteDispositionAppend, part.Load.WriteDisposition, useCase.description)
+			assert.Equal(t, "mem://localhost/tasks/mytable_123/parts/", part.Part.URL, useCase.description)
+			assert.Equal(t, len(parts), len(part.Part.Expected), useCase.description)
+			if assert.Equal(t, 1, len(part.Actions.OnSuccess), useCase.description) {
+				assert.Equal(t, shared.ActionBarrier, part.Actions.OnSuccess[0].Action, useCase.description)
+			}
+			loaded = append(loaded, part.Load.SourceUris...)
+			if i == 0 {
+				assert.Equal(t, "123", part.EventID, useCase.description)
+				assert.Equal(t, URIs, part.Part.URIs, useCase.description)
+				continue
+			}
+			assert.Equal(t, fmt.Sprintf("123p%v", i), part.EventID, useCase.description)
+			assert.Equal(t, "mem://localhost/active/mydataset.mytable--"+part.EventID+".run", part.ProcessURL, useCase.description)
+			deferred := part.Actions.OnSuccess[0].Actions
+			if assert.Equal(t, 1, len(deferred.OnSuccess), useCase.description) {
+				assert.Equal(t, shared.ActionMove, deferred.OnSuccess[0].Action, useCase.description)
+			}
+		}
+		assert.Equal(t, URIs, loaded, useCase.description)
+		_, request := parts[0].NewLoadRequest()
+		barrier := request.Actions.OnSuccess[0]
+		assert.Equal(t, shared.ActionBarrier, barrier.Action, useCase.description)
+		assert.Equal(t, "000", barrier.Request["Member"], useCase.description)
+	}
+}
@@ -16,7 +16,11 @@ func (j *Job) NewLoadRequest() (*bq.LoadRequest, *task.Action) {
 		JobConfigurationLoad: &load,
 	}
 	meta := activity.New(root, shared.ActionLoad, root.Mode(shared.ActionLoad), root.IncStepCount())
-	actions := j.Actions.Expand(root, shared.ActionLoad, load.SourceUris)
+	sourceURIs := load.SourceUris
+	if j.Part != nil && len(j.Part.URIs) > 0 {
+		sourceURIs = j.Part.URIs
+	}
+	actions := j.Actions.Expand(root, shared.ActionLoad, sourceURIs)
 	action := &task.Action{
 		Action:  shared.ActionLoad,
 		Actions: actions,
@@ -105,6 +105,46 @@ func (j *Job) applyDestTemplate(table *bigquery.Table, service bq.Service, ctx c
 	return table, nil
 }
 
+//initDestsSchema gets rule additional destinations tables, tables created from schema template if needed, missing table schema is left empty
+func (j *Job) initDestsSchema(ctx context.Context, service bq.Service) error {
+	if len(j.Rule.Dests) == 0 {
+		return nil
+	}
+	j.DestSchemas = make([]*bigquery.Table, len(j.Rule.Dests))
+	for i, dest := range j.Rule.Dests {
+		tableReference, err := dest.TableReference(j.Source)
+		if err != nil {
+			return errors.Wrapf(err, "invalid dests[%v].Table: %v", i, dest.Table)
+		}
+		if dest.Schema.Template != "" {
+			templateRef, err := base.NewTableReference(dest.Schema.Template)
+			if err != nil {
+				return errors.Wrapf(err, "invalid dests[%v] template: %v", i, dest.Schema.Template)
+			}
+			table, err := service.Table(ctx, templateRef)
+			if err != nil {
+				return errors.Wrapf(err, "fail to get template table: %v", dest.Schema.Template)
+			}
+			table.TableReference = tableReference
+			resetExpiryTime(table)
+			if err = service.CreateTableIfNotExist(ctx, table, true); err != nil {
+				return errors.Wrapf(err, "failed to create table: %v", base.EncodeTableReference(tableReference, false))
+			}
+			j.DestSchemas[i] = table
+			continue
+		}
+		table, err := service.Table(ctx, tableReference)
+		if err != nil {
+			if base.IsNotFoundError(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get table: %v", base.EncodeTableReference(tableReference, false))
+		}
+		j.DestSchemas[i] = table
+	}
+	return nil
+}
+
 func resetExpiryTime(table *bigquery.Table) {
 	if table.ExpirationTime > 0 {
 		table.ExpirationTime = 0
@@ -2,9 +2,14 @@ package load
 
 import (
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/schema"
 	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/service/group"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/config"
 	"github.com/viant/bqtail/tail/sql"
 	"github.com/viant/bqtail/task"
 	"google.golang.org/api/bigquery/v2"
@@ -28,30 +33,30 @@ func (j *Job) addSplitActions(selectSQL string, result, onDone *task.Actions) er
 		destTemplate = dest.Schema.Template
 	}
 	clusterColumnMap := j.clusterColumnMap()
-
-	for i := range split.Mapping {
-		mapping := split.Mapping[i]
-		destTable, _ := dest.CustomTableReference(mapping.Then, j.Process.Source)
-		where := replaceWithMap(mapping.When, clusterColumnMap)
-		var query *task.Action
-
-		tempRef, _ := base.NewTableReference(j.TempTable)
-		if j.Rule.IsDMLCopy() {
-			SQL := sql.BuildAppendDML(tempRef, destTable, j.Load.Schema, dest, j.getDestTableSchema())
-			SQL = strings.Replace(SQL, "$WHERE", " WHERE  "+where+" ", 1)
-			query = bq.NewQueryAction(SQL, nil, "", true, next)
-		} else {
-			SQL := strings.Replace(selectSQL, "$WHERE", " WHERE  "+where+" ", 1)
-			query = bq.NewQueryAction(SQL, destTable, destTemplate, j.Rule.IsAppend(), next)
+	targets := j.splitTargets(split, dest, clusterColumnMap)
+	var err error
+	switch {
+	case split.IsScript():
+		if next, err = j.buildSplitScript(targets, dest, destTemplate, onDone); err != nil {
+			return err
+		}
+	case split.IsParallel():
+		next = j.buildParallelSplit(targets, selectSQL, dest, destTemplate, onDone)
+	default:
+		for _, target := range targets {
+			group := task.NewActions(nil, nil)
+			group.AddOnSuccess(j.splitQuery(target, selectSQL, dest, destTemplate, next))
+			group.AddOnFailure(onDone.OnFailure...)
+			next = group
 		}
-		group := task.NewActions(nil, nil)
-		group.AddOnSuccess(query)
-		next = group
+	}
+	if split.Exclusive || split.FailUnmatched() {
+		next = j.buildSplitCheck(targets, split, dest, next, onDone)
 	}
 
 	if len(j.splitColumns) == 0 {
 		result.AddOnSuccess(next.OnSuccess...)
-		result.AddOnSuccess(next.OnFailure...)
+		result.AddOnFailure(next.OnFailure...)
 		return nil
 	}
 	if len(dest.Transform) == 0 {
@@ -91,6 +96,108 @@ func (j *Job) addSplitActions(selectSQL string, result, onDone *task.Actions) er
 	return nil
 }
 
+//splitTarget represents split mapping destination table with criteria
+type splitTarget struct {
+	table *bigquery.TableReference
+	where string
+}
+
+//splitTargets returns split mapping targets, rows not matching any mapping criteria target split else table
+func (j *Job) splitTargets(split *config.Split, dest *config.Destination, clusterColumnMap map[string]string) []*splitTarget {
+	var result = make([]*splitTarget, 0)
+	var criteria = make([]string, 0)
+	for _, mapping := range split.Mapping {
+		destTable, _ := dest.CustomTableReference(mapping.Then, j.Process.Source)
+		where := replaceWithMap(mapping.When, clusterColumnMap)
+		criteria = append(criteria, where)
+		result = append(result, &splitTarget{table: destTable, where: where})
+	}
+	if split.Else != "" {
+		destTable, _ := dest.CustomTableReference(split.Else, j.Process.Source)
+		result = append(result, &splitTarget{table: destTable, where: sql.BuildElseCriteria(criteria)})
+	}
+	return result
+}
+
+//splitStatement returns split target DML statement
+func (j *Job) splitStatement(target *splitTarget, dest *config.Destination) string {
+	tempRef, _ := base.NewTableReference(j.TempTable)
+	var SQL string
+	if dest.IsCopyMethodMerge() {
+		SQL = sql.BuildMergeDML(tempRef, target.table, j.Load.Schema, dest, j.getDestTableSchema())
+	} else {
+		SQL = sql.BuildAppendDML(tempRef, target.table, j.Load.Schema, dest, j.getDestTableSchema())
+	}
+	return strings.Replace(SQL, "$WHERE", " WHERE  "+target.where+" ", 1)
+}
+
+//splitQuery returns split target query action
+func (j *Job) splitQuery(target *splitTarget, selectSQL string, dest *config.Destination, destTemplate string, finally *task.Actions) *task.Action {
+	if j.Rule.IsDMLCopy() || dest.IsCopyMethodMerge() || j.Group != nil {
+		return bq.NewDMLAction(j.splitStatement(target, dest), j.Group != nil, finally)
+	}
+	SQL := strings.Replace(selectSQL, "$WHERE", " WHERE  "+target.where+" ", 1)
+	return bq.NewQueryAction(SQL, target.table, destTemplate, j.Rule.IsAppend(), finally)
+}
+
+//buildSplitScript returns actions running all split targets statements with one multi statement transaction
+func (j *Job) buildSplitScript(targets []*splitTarget, dest *config.Destination, destTemplate string, onDone *task.Actions) (*task.Actions, error) {
+	templateRef, err := dest.CustomTableReference(destTemplate, j.Process.Source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid split template: %v", destTemplate)
+	}
+	template := base.EncodeTableReference(templateRef, true)
+	var ddl = make([]string, 0)
+	var statements = make([]string, 0)
+	for _, target := range targets {
+		if table := base.EncodeTableReference(target.table, true); table != template {
+			ddl = append(ddl, fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%v` LIKE `%v`", table, template))
+		}
+		statements = append(statements, j.splitStatement(target, dest))
+	}
+	result := task.NewActions(nil, nil)
+	result.AddOnSuccess(bq.NewDMLAction(sql.BuildSplitScript(ddl, statements), j.Group != nil, onDone))
+	result.AddOnFailure(onDone.OnFailure...)
+	return result, nil
+}
+
+//buildParallelSplit returns actions running split targets queries concurrently, supplied actions are deferred till all queries complete
+func (j *Job) buildParallelSplit(targets []*splitTarget, selectSQL string, dest *config.Destination, destTemplate string, onDone *task.Actions) *task.Actions {
+	URL := j.barrierURL(shared.SplitLocation)
+	var expected = make([]string, len(targets))
+	for i := range targets {
+		expected[i] = fmt.Sprintf("%03d", i)
+	}
+	result := task.NewActions(nil, nil)
+	for i, target := range targets {
+		//only the first member defers supplied actions, so that they run once group is committed
+		var deferred *task.Actions
+		if i == 0 {
+			deferred = onDone
+		}
+		finally := task.NewActions(nil, nil)
+		finally.AddOnSuccess(group.NewCommitAction(URL, expected[i], expected, deferred))
+		finally.AddOnFailure(group.NewCommitAction(URL, expected[i], expected, deferred))
+		result.AddOnSuccess(j.splitQuery(target, selectSQL, dest, destTemplate, finally))
+	}
+	result.AddOnFailure(onDone.OnFailure...)
+	return result
+}
+
+//buildSplitCheck returns actions checking split mappings exclusiveness and coverage before running split
+func (j *Job) buildSplitCheck(targets []*splitTarget, split *config.Split, dest *config.Destination, next, onDone *task.Actions) *task.Actions {
+	tempRef, _ := base.NewTableReference(j.TempTable)
+	var criteria = make([]string, 0)
+	for i := range split.Mapping {
+		criteria = append(criteria, targets[i].where)
+	}
+	SQL := sql.BuildSplitCheck(tempRef, dest.Transient.Alias, criteria, split.Exclusive, split.FailUnmatched())
+	result := task.NewActions(nil, nil)
+	result.AddOnSuccess(bq.NewDMLAction(SQL, j.Group != nil, next))
+	result.AddOnFailure(onDone.OnFailure...)
+	return result
+}
+
 func (j *Job) getDestTableSchema() *bigquery.TableSchema {
 	var destSchema *bigquery.TableSchema
 	if j.DestSchema != nil {
@@ -196,19 +303,5 @@ func (j *Job) initTableSplit(ctx context.Context, service bq.Service) error {
 }
 
 func getColumn(fields []*bigquery.TableFieldSchema, column string) *bigquery.TableFieldSchema {
-	column = strings.ToLower(column)
-	if index := strings.Index(column, "."); index != -1 {
-		parent := string(column[:index])
-		for i := range fields {
-			if parent == strings.ToLower(fields[i].Name) {
-				return getColumn(fields[i].Fields, column[index+1:])
-			}
-		}
-	}
-	for i := range fields {
-		if column == strings.ToLower(fields[i].Name) {
-			return fields[i]
-		}
-	}
-	return nil
+	return schema.Field(fields, column)
 }
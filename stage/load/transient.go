@@ -1,9 +1,13 @@
 package load
 
 import (
+	"fmt"
+	"github.com/pkg/errors"
 	"github.com/viant/bqtail/base"
 	"github.com/viant/bqtail/schema"
 	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/service/group"
+	"github.com/viant/bqtail/shared"
 	"github.com/viant/bqtail/tail/config"
 	"github.com/viant/bqtail/tail/sql"
 	"github.com/viant/bqtail/task"
@@ -22,6 +26,10 @@ func (j Job) buildTransientActions(actions *task.Actions) (*task.Actions, error)
 	tempRef, _ := base.NewTableReference(j.TempTable)
 	dropAction := bq.NewDropAction(j.ProjectID, base.EncodeTableReference(tempRef, false))
 	actions.AddOnSuccess(dropAction)
+	actions, fanOut, err := j.buildFanOutActions(actions)
+	if err != nil {
+		return nil, err
+	}
 	dest := j.Rule.Dest
 	load := j.Load
 
@@ -31,7 +39,7 @@ func (j Job) buildTransientActions(actions *task.Actions) (*task.Actions, error)
 		source := base.EncodeTableReference(load.DestinationTable, false)
 		destRef := base.EncodeTableReference(destinationTable, false)
 		if j.Rule.IsDMLCopy() && load.Schema != nil {
-			j.addDMLCopy(load, destinationTable, dest, actions, result)
+			j.addDMLCopy(load, destinationTable, dest, j.DestSchema, actions, result)
 			return result, nil
 		}
 		copyRequest := bq.NewCopyAction(source, destRef, j.Rule.IsAppend(), actions)
@@ -39,14 +47,61 @@ func (j Job) buildTransientActions(actions *task.Actions) (*task.Actions, error)
 		return result, nil
 	}
 
-	selectAll := sql.BuildSelect(load.DestinationTable, load.Schema, dest, j.getDestTableSchema())
 	if dest.HasSplit() {
 		tempRef, _ := base.NewTableReference(j.TempTable)
 		selectAll := sql.BuildSelect(tempRef, load.Schema, dest, j.getDestTableSchema())
 		return result, j.addSplitActions(selectAll, result, actions)
 	}
+	if err = j.addCopy(load, destinationTable, dest, j.DestSchema, actions, result); err != nil {
+		return nil, err
+	}
+	result.AddOnSuccess(fanOut...)
+	return result, nil
+}
 
-	selectAll = strings.Replace(selectAll, "$WHERE", j.getDMLWhereClause(), 1)
+//buildFanOutActions returns main destination copy follow up actions and rule additional destinations copies from transient table,
+//all destinations copies run independently, supplied actions (including transient table drop) are deferred till all of them complete,
+//if any copy fails, supplied OnFailure actions run with failed destinations errors
+func (j Job) buildFanOutActions(actions *task.Actions) (*task.Actions, []*task.Action, error) {
+	if len(j.Rule.Dests) == 0 {
+		return actions, nil, nil
+	}
+	var tables = make([]*bigquery.TableReference, len(j.Rule.Dests))
+	var expected = []string{fmt.Sprintf("%03d_%v", 0, j.DestTable)}
+	for i, dest := range j.Rule.Dests {
+		destinationTable, err := dest.TableReference(j.Source)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid dests[%v].Table: %v", i, dest.Table)
+		}
+		tables[i] = destinationTable
+		expected = append(expected, fmt.Sprintf("%03d_%v", i+1, base.EncodeTableReference(destinationTable, false)))
+	}
+	URL := j.barrierURL(shared.FanOutLocation)
+	result := task.NewActions(nil, nil)
+	result.AddOnSuccess(group.NewBarrierAction(URL, expected[0], expected, actions))
+	result.AddOnFailure(group.NewBarrierAction(URL, expected[0], expected, actions))
+	var fanOut = make([]*task.Action, 0)
+	for i, dest := range j.Rule.Dests {
+		var destSchema *bigquery.Table
+		if i < len(j.DestSchemas) {
+			destSchema = j.DestSchemas[i]
+		}
+		finally := task.NewActions(nil, nil)
+		finally.AddOnSuccess(group.NewBarrierAction(URL, expected[i+1], expected, nil))
+		finally.AddOnFailure(group.NewBarrierAction(URL, expected[i+1], expected, nil))
+		branch := task.NewActions(nil, nil)
+		if err := j.addCopy(j.Load, tables[i], dest, destSchema, finally, branch); err != nil {
+			return nil, nil, err
+		}
+		fanOut = append(fanOut, branch.OnSuccess...)
+	}
+	return result, fanOut, nil
+}
+
+//addCopy adds transient to destination table copy with destination copy method
+func (j Job) addCopy(load *bigquery.JobConfigurationLoad, destinationTable *bigquery.TableReference, dest *config.Destination, destSchema *bigquery.Table, actions *task.Actions, result *task.Actions) error {
+	selectAll := sql.BuildSelect(load.DestinationTable, load.Schema, dest, tableSchema(destSchema))
+	selectAll = strings.Replace(selectAll, "$WHERE", dmlWhereClause(dest), 1)
 	partition := base.TablePartition(destinationTable.TableId)
 	destTemplate := ""
 
@@ -54,35 +109,86 @@ func (j Job) buildTransientActions(actions *task.Actions) (*task.Actions, error)
 		destTemplate = dest.Schema.Template
 	}
 
-	if j.Rule.IsDMLCopy() {
-		j.addDMLCopy(load, destinationTable, dest, actions, result)
-		return result, nil
+	if dest.IsCopyMethodMerge() {
+		j.addMergeCopy(load, destinationTable, dest, destSchema, actions, result)
+		return nil
+	}
+	if dest.IsCopyMethodReplace() {
+		return j.addReplaceCopy(load, destinationTable, dest, destSchema, actions, result)
+	}
+	if j.Group != nil {
+		//grouped copy runs as DML statement within group commit transaction
+		if partition != "" {
+			return errors.Errorf("partition decorator is not supported for group %v: %v", j.Rule.Group, base.EncodeTableReference(destinationTable, false))
+		}
+		j.addDMLCopy(load, destinationTable, dest, destSchema, actions, result)
+		return nil
+	}
+	if dest.IsCopyMethodDML() {
+		j.addDMLCopy(load, destinationTable, dest, destSchema, actions, result)
+		return nil
 	}
-	canCopy := schema.CanCopy(j.TempSchema, j.DestSchema)
+	canCopy := schema.CanCopy(j.TempSchema, destSchema)
 
-	if j.Rule.Dest.IsCopyMethodQuery() || partition != "" || !canCopy {
-		query := bq.NewQueryAction(selectAll, destinationTable, destTemplate, j.Rule.IsAppend(), actions)
+	if dest.IsCopyMethodQuery() || partition != "" || !canCopy {
+		query := bq.NewQueryAction(selectAll, destinationTable, destTemplate, dest.IsAppend(), actions)
 		result.AddOnSuccess(query)
 	} else {
 		source := base.EncodeTableReference(load.DestinationTable, false)
-		dest := base.EncodeTableReference(destinationTable, false)
-		copyRequest := bq.NewCopyAction(source, dest, j.Rule.IsAppend(), actions)
+		destRef := base.EncodeTableReference(destinationTable, false)
+		copyRequest := bq.NewCopyAction(source, destRef, dest.IsAppend(), actions)
 		result.AddOnSuccess(copyRequest)
 	}
-	return result, nil
+	return nil
+}
+
+func (j Job) addDMLCopy(load *bigquery.JobConfigurationLoad, destinationTable *bigquery.TableReference, dest *config.Destination, destSchema *bigquery.Table, actions *task.Actions, result *task.Actions) {
+	SQL := sql.BuildAppendDML(load.DestinationTable, destinationTable, load.Schema, dest, tableSchema(destSchema))
+	SQL = strings.Replace(SQL, "$WHERE", dmlWhereClause(dest), 1)
+	if j.Group != nil && !dest.IsAppend() {
+		SQL = fmt.Sprintf("DELETE FROM `%v` WHERE TRUE;\n%v", base.EncodeTableReference(destinationTable, true), SQL)
+	}
+	query := bq.NewDMLAction(SQL, j.Group != nil, actions)
+	result.AddOnSuccess(query)
+}
+
+func (j Job) addMergeCopy(load *bigquery.JobConfigurationLoad, destinationTable *bigquery.TableReference, dest *config.Destination, destSchema *bigquery.Table, actions *task.Actions, result *task.Actions) {
+	SQL := sql.BuildMergeDML(load.DestinationTable, destinationTable, load.Schema, dest, tableSchema(destSchema))
+	SQL = strings.Replace(SQL, "$WHERE", dmlWhereClause(dest), 1)
+	query := bq.NewDMLAction(SQL, j.Group != nil, actions)
+	result.AddOnSuccess(query)
 }
 
-func (j Job) addDMLCopy(load *bigquery.JobConfigurationLoad, destinationTable *bigquery.TableReference, dest *config.Destination, actions *task.Actions, result *task.Actions) {
-	SQL := sql.BuildAppendDML(load.DestinationTable, destinationTable, load.Schema, dest, j.getDestTableSchema())
-	SQL = strings.Replace(SQL, "$WHERE", j.getDMLWhereClause(), 1)
-	query := bq.NewQueryAction(SQL, nil, "", true, actions)
+func (j Job) addReplaceCopy(load *bigquery.JobConfigurationLoad, destinationTable *bigquery.TableReference, dest *config.Destination, destSchema *bigquery.Table, actions *task.Actions, result *task.Actions) error {
+	timePartitioning, rangePartitioning := load.TimePartitioning, load.RangePartitioning
+	if destSchema != nil && (destSchema.TimePartitioning != nil || destSchema.RangePartitioning != nil) {
+		timePartitioning, rangePartitioning = destSchema.TimePartitioning, destSchema.RangePartitioning
+	}
+	SQL, err := sql.BuildReplaceDML(load.DestinationTable, destinationTable, load.Schema, dest, tableSchema(destSchema), timePartitioning, rangePartitioning)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build %v copy into %v", shared.CopyMethodReplace, base.EncodeTableReference(destinationTable, false))
+	}
+	SQL = strings.Replace(SQL, "$WHERE", dmlWhereClause(dest), 1)
+	query := bq.NewDMLAction(SQL, j.Group != nil, actions)
 	result.AddOnSuccess(query)
+	return nil
 }
 
 func (j Job) getDMLWhereClause() string {
+	return dmlWhereClause(j.Rule.Dest)
+}
+
+func dmlWhereClause(dest *config.Destination) string {
 	where := ""
-	if j.Rule.Dest.Transient.Criteria != "" {
-		where = " WHERE " + j.Rule.Dest.Transient.Criteria
+	if dest.Transient.Criteria != "" {
+		where = " WHERE " + dest.Transient.Criteria
 	}
 	return where
 }
+
+func tableSchema(table *bigquery.Table) *bigquery.TableSchema {
+	if table == nil {
+		return nil
+	}
+	return table.Schema
+}
@@ -0,0 +1,140 @@
+package load
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/service/group"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/task"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+	"time"
+)
+
+func TestJob_BuildTransientActions(t *testing.T) {
+	query, dml := shared.CopyMethodQuery, shared.CopyMethodDML
+	schema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{{Name: "id", Type: "INTEGER"}, {Name: "name", Type: "STRING"}}}
+	rule := &config.Rule{
+		Dest: &config.Destination{Table: "mydataset.events", Transient: &config.Transient{Dataset: "temp"}},
+		Dests: []*config.Destination{
+			{Table: "mydataset.events_query", Transient: &config.Transient{Dataset: "temp", CopyMethod: &query}},
+			{Table: "mydataset.events_dml", Transient: &config.Transient{Dataset: "temp", CopyMethod: &dml}},
+		},
+	}
+	process := stage.NewProcess("123", stage.NewSource("gs://bucket/data/a.json", time.Now()), "mem://localhost/rules/events.yaml", true)
+	process.DestTable = "mydataset.events"
+	process.ProjectID = "myproject"
+	job := &Job{
+		Rule:       rule,
+		Process:    process,
+		Load:       &bigquery.JobConfigurationLoad{DestinationTable: &bigquery.TableReference{ProjectId: "myproject", DatasetId: "temp", TableId: "events_123"}, Schema: schema},
+		TempSchema: &bigquery.Table{Schema: schema},
+	}
+	job.TempTable = "myproject:temp.events_123"
+	onFailure, _ := task.NewAction(shared.ActionNotify, map[string]interface{}{"Message": "failed"})
+	done, _ := task.NewAction(shared.ActionMove, map[string]interface{}{"DestURL": "mem://localhost/done"})
+	actions := task.NewActions([]*task.Action{done}, []*task.Action{onFailure})
+
+	result, err := job.buildTransientActions(actions)
+	if !assert.Nil(t, err) || !assert.Equal(t, 3, len(result.OnSuccess)) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		action      string
+		dest        string
+		SQL         string
+		member      string
+	}{
+		{description: "main dest copy", action: shared.ActionCopy, dest: "mydataset.events", member: "000_mydataset.events"},
+		{description: "query dest", action: shared.ActionQuery, dest: "mydataset.events_query", member: "001_mydataset.events_query"},
+		{description: "DML dest", action: shared.ActionQuery, SQL: "INSERT INTO mydataset.events_dml(id,name)", member: "002_mydataset.events_dml"},
+	}
+	for i, useCase := range useCases {
+		action := result.OnSuccess[i]
+		assert.Equal(t, useCase.action, action.Action, useCase.description)
+		if useCase.dest != "" {
+			assert.Equal(t, useCase.dest, action.RequestStringValue("Dest"), useCase.description)
+		}
+		if useCase.SQL != "" {
+			assert.Contains(t, action.RequestStringValue("SQL"), useCase.SQL, useCase.description)
+		}
+		if !assert.NotNil(t, action.Actions, useCase.description) || !assert.Equal(t, 1, len(action.OnSuccess), useCase.description) {
+			return
+		}
+		barrier := action.OnSuccess[0]
+		assert.Equal(t, shared.ActionBarrier, barrier.Action, useCase.description)
+		assert.Equal(t, useCase.member, barrier.RequestStringValue("Member"), useCase.description)
+		assert.Equal(t, shared.ActionBarrier, action.OnFailure[0].Action, useCase.description+" - OnFailure")
+		if i == 0 {
+			assert.Equal(t, []*task.Action{done, onFailure}, []*task.Action{barrier.OnSuccess[0], barrier.OnFailure[0]}, useCase.description+" - deferred rule actions")
+			assert.Equal(t, shared.ActionDrop, barrier.OnSuccess[1].Action, useCase.description+" - transient table drop deferred")
+			continue
+		}
+		assert.Nil(t, barrier.Actions, useCase.description+" - no deferred actions")
+	}
+	assert.Equal(t, []*task.Action{onFailure}, result.OnFailure)
+}
+
+func TestJob_BuildTransientActions_FanOutFailure(t *testing.T) {
+	ctx := context.Background()
+	schema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{{Name: "id", Type: "INTEGER"}}}
+	query := shared.CopyMethodQuery
+	rule := &config.Rule{
+		Dest:  &config.Destination{Table: "mydataset.events", Transient: &config.Transient{Dataset: "temp"}},
+		Dests: []*config.Destination{{Table: "mydataset.events_query", Transient: &config.Transient{Dataset: "temp", CopyMethod: &query}}},
+	}
+	process := stage.NewProcess("124", stage.NewSource("gs://bucket/data/b.json", time.Now()), "mem://localhost/rules/events.yaml", true)
+	process.DestTable = "mydataset.events"
+	process.ProjectID = "myproject"
+	process.ProcessURL = "mem://localhost/journal/Running/mydataset.events--124.run"
+	job := &Job{
+		Rule:       rule,
+		Process:    process,
+		Load:       &bigquery.JobConfigurationLoad{DestinationTable: &bigquery.TableReference{ProjectId: "myproject", DatasetId: "temp", TableId: "events_124"}, Schema: schema},
+		TempSchema: &bigquery.Table{Schema: schema},
+	}
+	job.TempTable = "myproject:temp.events_124"
+	onFailure, _ := task.NewAction(shared.ActionNotify, map[string]interface{}{"Message": "failed"})
+	done, _ := task.NewAction(shared.ActionMove, map[string]interface{}{"DestURL": "mem://localhost/done"})
+	result, err := job.buildTransientActions(task.NewActions([]*task.Action{done}, []*task.Action{onFailure}))
+	if !assert.Nil(t, err) || !assert.Equal(t, 2, len(result.OnSuccess)) {
+		return
+	}
+
+	registry := task.NewRegistry()
+	recorder := &actionRecorder{}
+	registry.RegisterService("recorder", recorder)
+	for _, action := range []string{shared.ActionNotify, shared.ActionMove, shared.ActionDrop} {
+		registry.RegisterAction(action, task.NewServiceAction("recorder", map[string]interface{}{}))
+	}
+	group.InitRegistry(registry, group.New(registry, afs.New()))
+
+	//main dest copy succeeded, query dest failed
+	toRun, _ := result.OnSuccess[0].ToRun(nil, &base.Job{})
+	_, err = task.RunAll(ctx, registry, toRun)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(recorder.actions), "deferred till all dests complete")
+	toRun, _ = result.OnSuccess[1].ToRun(errors.New("query failed"), &base.Job{})
+	_, err = task.RunAll(ctx, registry, toRun)
+	assert.Nil(t, err)
+	if !assert.Equal(t, 1, len(recorder.actions)) {
+		return
+	}
+	assert.Equal(t, shared.ActionNotify, recorder.actions[0].Action, "transient table is kept, OnFailure runs")
+	assert.Contains(t, recorder.actions[0].RequestStringValue(shared.ErrorKey), "001_mydataset.events_query: query failed")
+}
+
+type actionRecorder struct {
+	actions []*task.Action
+}
+
+func (r *actionRecorder) Run(ctx context.Context, request *task.Action) (task.Response, error) {
+	r.actions = append(r.actions, request)
+	return nil, nil
+}
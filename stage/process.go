@@ -24,6 +24,11 @@ type Process struct {
 	TempTable      string                 `json:",omitempty"`
 	DestTable      string                 `json:",omitempty"`
 	StepCount      int                    `json:",omitempty"`
+	//MaxConcurrentLoad and MaxConcurrentSQL carry rule per destination limits to dispatcher
+	MaxConcurrentLoad int `json:",omitempty"`
+	MaxConcurrentSQL  int `json:",omitempty"`
+	//Priority rule dispatch priority, encoded in job ID
+	Priority int `json:",omitempty"`
 }
 
 func (p *Process) SplitTable() string {
@@ -9,6 +9,7 @@ import (
 type Source struct {
 	URL    string    `json:",omitempty"`
 	Time   time.Time `json:",omitempty"`
+	Size   int64     `json:",omitempty"`
 	Status string    `json:",omitempty"`
 }
 
@@ -0,0 +1,84 @@
+package batch
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"strings"
+)
+
+const lateSuffix = "late"
+
+//ProcessedURL returns processed window record URL, record keeps window data files matched by window listing
+func ProcessedURL(windowURL string) string {
+	return url.Join(trackingURL(windowURL), shared.ProcessedFile)
+}
+
+//loadProcessed returns processed window record or nil if window has not been processed yet
+func (s *service) loadProcessed(ctx context.Context, windowURL string) (*Manifest, error) {
+	URL := ProcessedURL(windowURL)
+	if ok, _ := s.fs.Exists(ctx, URL, option.NewObjectKind(true)); !ok {
+		return nil, nil
+	}
+	manifest, _, err := s.loadManifest(ctx, URL)
+	return manifest, err
+}
+
+//reserveProcessed creates empty processed window record before window data files are listed, so that data file event
+//checking processed record after window listing started is routed to catch-up window, existing record is kept
+func (s *service) reserveProcessed(ctx context.Context, windowURL string) error {
+	URL := ProcessedURL(windowURL)
+	err := s.fs.Upload(ctx, URL, file.DefaultFileOsMode, strings.NewReader("{}"), option.NewGeneration(true, 0))
+	if err == nil || isPreConditionError(err) {
+		return nil
+	}
+	return errors.Wrapf(err, "failed to reserve processed window record: %v", URL)
+}
+
+//recordProcessed records window matched data files, data files already loaded by other window event are excluded,
+//data files with modification time within processed window are routed to catch-up window afterwards
+func (s *service) recordProcessed(ctx context.Context, window *Window) error {
+	var resources []*Resource
+	_, err := s.update(ctx, ProcessedURL(window.URL), func(manifest *Manifest) bool {
+		var loaded = make(map[string]bool)
+		for _, resource := range manifest.Resources {
+			loaded[resource.URL] = resource.EventID != window.EventID
+		}
+		resources = make([]*Resource, 0)
+		for _, resource := range window.Resources {
+			if loaded[resource.URL] {
+				continue
+			}
+			resources = append(resources, resource)
+			if _, ok := loaded[resource.URL]; !ok {
+				manifest.Resources = append(manifest.Resources, &Resource{URL: resource.URL, ModTime: resource.ModTime, Size: resource.Size, EventID: window.EventID})
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	window.Resources = resources
+	window.URIs = make([]string, 0)
+	for _, resource := range resources {
+		window.URIs = append(window.URIs, resource.URL)
+	}
+	return nil
+}
+
+//recordLate records late data file routed to catch-up window, so that processed window rerun excludes it
+func (s *service) recordLate(ctx context.Context, windowURL string, process *stage.Process) error {
+	_, err := s.update(ctx, ProcessedURL(windowURL), func(manifest *Manifest) bool {
+		if manifest.Has(process.Source.URL) {
+			return false
+		}
+		manifest.Resources = append(manifest.Resources, &Resource{URL: process.Source.URL, ModTime: process.Source.Time, Size: process.Source.Size, EventID: process.EventID})
+		return true
+	})
+	return err
+}
@@ -0,0 +1,180 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_TryAcquireWindow_Late(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	rule := &config.Rule{Batch: &config.Batch{Window: &config.Window{DurationInSec: 120}}, Dest: &config.Destination{Table: "mydataset.mytable"}}
+	rule.Batch.Init()
+	rule.Info.URL = "mem://localhost/rules/late.yaml"
+	rule.When.Prefix = "/data/late/"
+	srv := New(func(rule *config.Rule) string {
+		return "mem://localhost/tasks/late"
+	}, time.Hour, fs)
+	//data file events are processed past window end
+	sourceTime := time.Now().UTC().Add(-10 * time.Minute)
+	acquire := func(URL, eventID string) *Info {
+		_ = fs.Upload(ctx, URL, file.DefaultFileOsMode, strings.NewReader("{}"))
+		object, err := fs.Object(ctx, URL, option.NewObjectKind(true))
+		if !assert.Nil(t, err, URL) {
+			return nil
+		}
+		process := stage.NewProcess(eventID, stage.NewSource(URL, sourceTime), rule.Info.URL, true)
+		process.DestTable = "mydataset.mytable"
+		process.Source.Size = object.Size()
+		info, err := srv.TryAcquireWindow(ctx, process, rule)
+		assert.Nil(t, err, URL)
+		return info
+	}
+
+	owner := acquire("mem://localhost/data/late/0.json", "1")
+	if !assert.NotNil(t, owner.Window) {
+		return
+	}
+	assert.Nil(t, acquire("mem://localhost/data/late/1.json", "2").Window)
+	owner.Window.Start = sourceTime.Add(-time.Hour)
+	owner.Window.End = time.Now().Add(time.Hour)
+	err := srv.MatchWindowDataURLs(ctx, rule, owner.Window)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"mem://localhost/data/late/0.json", "mem://localhost/data/late/1.json"}, owner.Window.URIs)
+
+	late := acquire("mem://localhost/data/late/2.json", "3")
+	if !assert.NotNil(t, late.Window, "late data file opens catch-up window") {
+		return
+	}
+	assert.True(t, late.Late)
+	assert.True(t, late.Window.Late)
+	assert.Equal(t, "3", late.Window.EventID)
+	assert.NotEqual(t, owner.Window.URL, late.Window.URL)
+
+	lateJoin := acquire("mem://localhost/data/late/3.json", "4")
+	assert.True(t, lateJoin.Late)
+	assert.Nil(t, lateJoin.Window)
+	assert.Equal(t, late.Window.URL, lateJoin.WindowURL)
+
+	duplicate := acquire("mem://localhost/data/late/1.json", "2")
+	assert.False(t, duplicate.Late, "already processed data file")
+	assert.Nil(t, duplicate.Window)
+
+	err = srv.MatchWindowDataURLs(ctx, rule, late.Window)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"mem://localhost/data/late/2.json", "mem://localhost/data/late/3.json"}, late.Window.URIs)
+
+	rerun := NewWindow(owner.Window.Process, owner.Window.Start, owner.Window.End, owner.Window.URL)
+	err = srv.MatchWindowDataURLs(ctx, rule, rerun)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"mem://localhost/data/late/0.json", "mem://localhost/data/late/1.json"}, rerun.URIs, "rerun excludes late data files")
+}
+
+func TestService_TryAcquireWindow_LateListing(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	rule := &config.Rule{Batch: &config.Batch{Window: &config.Window{DurationInSec: 120}}, Dest: &config.Destination{Table: "mydataset.mytable"}}
+	rule.Batch.Init()
+	rule.Info.URL = "mem://localhost/rules/listing.yaml"
+	rule.When.Prefix = "/data/listing/"
+	srv := New(func(rule *config.Rule) string {
+		return "mem://localhost/tasks/listing"
+	}, time.Hour, fs)
+	acquire := func(URL, eventID string, sourceTime time.Time) *Info {
+		_ = fs.Upload(ctx, URL, file.DefaultFileOsMode, strings.NewReader("{}"))
+		process := stage.NewProcess(eventID, stage.NewSource(URL, sourceTime), rule.Info.URL, true)
+		process.DestTable = "mydataset.mytable"
+		info, err := srv.TryAcquireWindow(ctx, process, rule)
+		assert.Nil(t, err, URL)
+		return info
+	}
+
+	sourceTime := time.Now().UTC().Add(-10 * time.Minute)
+	owner := acquire("mem://localhost/data/listing/0.json", "1", sourceTime)
+	if !assert.NotNil(t, owner.Window) {
+		return
+	}
+	//window listing has started, but matched data files have not been recorded yet
+	if !assert.Nil(t, srv.(*service).reserveProcessed(ctx, owner.Window.URL)) {
+		return
+	}
+	late := acquire("mem://localhost/data/listing/1.json", "2", sourceTime)
+	if !assert.NotNil(t, late.Window, "data file arriving during window listing opens catch-up window") {
+		return
+	}
+	assert.True(t, late.Late)
+
+	owner.Window.Start = sourceTime.Add(-time.Hour)
+	owner.Window.End = time.Now().Add(time.Hour)
+	err := srv.MatchWindowDataURLs(ctx, rule, owner.Window)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"mem://localhost/data/listing/0.json"}, owner.Window.URIs, "catch-up window data file is excluded")
+
+	current := time.Now().UTC()
+	currentOwner := acquire("mem://localhost/data/listing/2.json", "3", current)
+	if !assert.NotNil(t, currentOwner.Window) {
+		return
+	}
+	if !assert.Nil(t, srv.(*service).reserveProcessed(ctx, currentOwner.Window.URL)) {
+		return
+	}
+	member := acquire("mem://localhost/data/listing/3.json", "4", current)
+	assert.False(t, member.Late, "processed record is not checked before window end")
+	assert.Equal(t, currentOwner.Window.URL, member.WindowURL)
+}
+
+func TestService_TryAcquireWindow_LateHorizon(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	rule := &config.Rule{Batch: &config.Batch{Window: &config.Window{DurationInSec: 120}}, Dest: &config.Destination{Table: "mydataset.mytable"}}
+	rule.Batch.Init()
+	rule.Info.URL = "mem://localhost/rules/horizon.yaml"
+	rule.When.Prefix = "/data/horizon/"
+	baseURL := "mem://localhost/tasks/horizon"
+	srv := New(func(rule *config.Rule) string {
+		return baseURL
+	}, time.Hour, fs)
+
+	var useCases = []struct {
+		description string
+		URL         string
+		sourceTime  time.Time
+		expectLate  bool
+	}{
+		{
+			description: "data file within horizon joins its window",
+			URL:         "mem://localhost/data/horizon/0.json",
+			sourceTime:  time.Now().UTC().Add(-time.Minute * 30),
+		},
+		{
+			description: "data file past horizon goes to catch-up window",
+			URL:         "mem://localhost/data/horizon/1.json",
+			sourceTime:  time.Now().UTC().Add(-2 * time.Hour),
+			expectLate:  true,
+		},
+	}
+
+	for i, useCase := range useCases {
+		_ = fs.Upload(ctx, useCase.URL, file.DefaultFileOsMode, strings.NewReader("{}"))
+		process := stage.NewProcess(fmt.Sprintf("%v", i), stage.NewSource(useCase.URL, useCase.sourceTime), rule.Info.URL, true)
+		process.DestTable = "mydataset.mytable"
+		info, err := srv.TryAcquireWindow(ctx, process, rule)
+		if !assert.Nil(t, err, useCase.description) || !assert.NotNil(t, info.Window, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expectLate, info.Late, useCase.description)
+		windowURL := rule.Batch.WindowURL(baseURL, windowDest(process, rule, process.DestTable+rule.When.Suffix+".json"+"mem://localhost/data/horizon", 0), useCase.sourceTime)
+		assert.Equal(t, !useCase.expectLate, info.Window.URL == windowURL, useCase.description)
+		exists, _ := fs.Exists(ctx, ProcessedURL(windowURL))
+		assert.False(t, exists, useCase.description+" - no processed record")
+	}
+}
@@ -0,0 +1,214 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/config"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const maxManifestUpdateAttempts = 10
+
+//Manifest represents batch window data files manifest
+type Manifest struct {
+	Resources []*Resource `json:",omitempty"`
+	//Closed is set once window stops accepting data files
+	Closed bool `json:",omitempty"`
+}
+
+//Has returns true if manifest has data file
+func (m *Manifest) Has(URL string) bool {
+	for _, resource := range m.Resources {
+		if resource.URL == URL {
+			return true
+		}
+	}
+	return false
+}
+
+//Size returns manifest data files total size
+func (m *Manifest) Size() int64 {
+	result := int64(0)
+	for _, resource := range m.Resources {
+		result += resource.Size
+	}
+	return result
+}
+
+//URIs returns manifest data files URIs
+func (m *Manifest) URIs() []string {
+	var result = make([]string, 0)
+	for _, resource := range m.Resources {
+		result = append(result, resource.URL)
+	}
+	return result
+}
+
+//windowDest returns window destination, sequence distinguishes windows opened after closed window within the same time window
+func windowDest(process *stage.Process, rule *config.Rule, suffixRaw string, sequence int) string {
+	dest, key := process.DestTable, suffixRaw
+	if rule.Group != "" {
+		dest, key = rule.Group, rule.Group
+	}
+	if sequence > 0 {
+		key += fmt.Sprintf("_%v", sequence)
+	}
+	return fmt.Sprintf("%v_%v", dest, base.Hash(key))
+}
+
+//ClosedWindowURL returns closed window marker URL
+func ClosedWindowURL(windowURL string) string {
+	return strings.Replace(windowURL, shared.WindowExt, shared.ClosedWindowExt, 1)
+}
+
+//ManifestURL returns window manifest URL
+func ManifestURL(windowURL string) string {
+	return url.Join(trackingURL(windowURL), shared.ManifestFile)
+}
+
+//trackingURL returns window meta files location
+func trackingURL(windowURL string) string {
+	return strings.Replace(windowURL, shared.WindowExt, "/", 1)
+}
+
+//IsClosed returns true if window manifest was closed
+func (s *service) IsClosed(ctx context.Context, window *Window) bool {
+	return s.isClosed(ctx, window.URL)
+}
+
+func (s *service) isClosed(ctx context.Context, windowURL string) bool {
+	exists, _ := s.fs.Exists(ctx, ClosedWindowURL(windowURL), option.NewObjectKind(true))
+	return exists
+}
+
+//join appends data file to window manifest, manifest is closed once batch limit is reached,
+//it returns false if window had been closed without the data file
+func (s *service) join(ctx context.Context, process *stage.Process, rule *config.Rule, windowURL string) (bool, error) {
+	resource := &Resource{URL: process.Source.URL, ModTime: process.Source.Time, Size: process.Source.Size}
+	var joined bool
+	err := s.updateManifest(ctx, windowURL, func(manifest *Manifest) bool {
+		if joined = manifest.Has(resource.URL); joined || manifest.Closed {
+			return false
+		}
+		manifest.Resources = append(manifest.Resources, resource)
+		manifest.Closed = rule.Batch.IsFull(len(manifest.Resources), manifest.Size())
+		joined = true
+		return true
+	})
+	return joined, err
+}
+
+//closeManifest closes window manifest, data files joining window afterwards open a new window
+func (s *service) closeManifest(ctx context.Context, windowURL string) (*Manifest, error) {
+	var result *Manifest
+	err := s.updateManifest(ctx, windowURL, func(manifest *Manifest) bool {
+		result = manifest
+		if manifest.Closed {
+			return false
+		}
+		manifest.Closed = true
+		return true
+	})
+	return result, err
+}
+
+//updateManifest applies update to window manifest, closed manifest marks window closed
+func (s *service) updateManifest(ctx context.Context, windowURL string, update func(manifest *Manifest) bool) error {
+	manifest, err := s.update(ctx, ManifestURL(windowURL), update)
+	if err == nil && manifest.Closed {
+		return s.markClosed(ctx, windowURL)
+	}
+	return err
+}
+
+//update applies update to manifest, storage generation precondition is used to avoid lost updates
+func (s *service) update(ctx context.Context, URL string, update func(manifest *Manifest) bool) (*Manifest, error) {
+	var manifest *Manifest
+	var generation *option.Generation
+	var err error
+	for i := 0; i < maxManifestUpdateAttempts; i++ {
+		if manifest, generation, err = s.loadManifest(ctx, URL); err != nil {
+			return nil, err
+		}
+		if !update(manifest) {
+			return manifest, nil
+		}
+		data, _ := json.Marshal(manifest)
+		var options = make([]storage.Option, 0)
+		if generation != nil {
+			options = append(options, generation)
+		}
+		if err = s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data), options...); err == nil {
+			return manifest, nil
+		}
+		if !isPreConditionError(err) && !isRateError(err) && !base.IsRetryError(err) {
+			return nil, errors.Wrapf(err, "failed to update batch window manifest: %v", URL)
+		}
+		time.Sleep(time.Duration(50+rand.Intn(250*(i+1))) * time.Millisecond)
+	}
+	return nil, errors.Wrapf(err, "failed to update batch window manifest: %v, exceeded max attempts", URL)
+}
+
+//markClosed creates closed window marker, so that dispatcher can schedule window before its end time
+func (s *service) markClosed(ctx context.Context, windowURL string) error {
+	URL := ClosedWindowURL(windowURL)
+	if s.isClosed(ctx, windowURL) {
+		return nil
+	}
+	if err := s.fs.Upload(ctx, URL, file.DefaultFileOsMode, strings.NewReader("")); err != nil {
+		return errors.Wrapf(err, "failed to mark batch window closed: %v", URL)
+	}
+	return nil
+}
+
+//loadManifest returns window manifest with generation precondition, empty manifest is returned if it does not exists yet
+func (s *service) loadManifest(ctx context.Context, URL string) (*Manifest, *option.Generation, error) {
+	if ok, _ := s.fs.Exists(ctx, URL, option.NewObjectKind(true)); !ok {
+		return &Manifest{}, option.NewGeneration(true, 0), nil
+	}
+	object, err := s.fs.Object(ctx, URL, option.NewObjectKind(true))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get batch window manifest: %v", URL)
+	}
+	reader, err := s.fs.Download(ctx, object)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to download batch window manifest: %v", URL)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read batch window manifest: %v", URL)
+	}
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to decode batch window manifest: %v", URL)
+	}
+	return manifest, base.GenerationMatch(object), nil
+}
+
+//matchManifestURLs sets window data URLs with manifest data files, manifest is closed if it has not been closed yet
+func (s *service) matchManifestURLs(ctx context.Context, window *Window) error {
+	manifest, err := s.closeManifest(ctx, window.URL)
+	if err != nil {
+		return err
+	}
+	window.Resources = manifest.Resources
+	window.URIs = manifest.URIs()
+	window.TrackingURLs = []string{ClosedWindowURL(window.URL), ManifestURL(window.URL)}
+	return nil
+}
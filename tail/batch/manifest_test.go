@@ -0,0 +1,132 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_TryAcquireWindow_Limit(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	sourceTime := time.Unix(1590000010, 0).UTC()
+
+	var useCases = []struct {
+		description string
+		batch       *config.Batch
+		sizes       []int64
+		expectURIs  [][]string
+	}{
+		{
+			description: "max files",
+			batch:       &config.Batch{MaxFiles: 2},
+			sizes:       []int64{1, 1, 1, 1, 1},
+			expectURIs: [][]string{
+				{"mem://localhost/data/limit1/0.json", "mem://localhost/data/limit1/1.json"},
+				{"mem://localhost/data/limit1/2.json", "mem://localhost/data/limit1/3.json"},
+				{"mem://localhost/data/limit1/4.json"},
+			},
+		},
+		{
+			description: "max bytes",
+			batch:       &config.Batch{MaxBytes: 100},
+			sizes:       []int64{60, 30, 20, 120, 10},
+			expectURIs: [][]string{
+				{"mem://localhost/data/limit2/0.json", "mem://localhost/data/limit2/1.json", "mem://localhost/data/limit2/2.json"},
+				{"mem://localhost/data/limit2/3.json"},
+				{"mem://localhost/data/limit2/4.json"},
+			},
+		},
+		{
+			description: "no limit",
+			batch:       &config.Batch{},
+			sizes:       []int64{1, 1, 1},
+			expectURIs:  [][]string{nil},
+		},
+	}
+
+	for i, useCase := range useCases {
+		useCase.batch.Init()
+		rule := &config.Rule{Batch: useCase.batch}
+		rule.Info.URL = fmt.Sprintf("mem://localhost/rules/limit%v.yaml", i+1)
+		srv := New(func(rule *config.Rule) string {
+			return fmt.Sprintf("mem://localhost/tasks/limit%v", i+1)
+		}, time.Hour, fs)
+		var windows = make([]*Window, 0)
+		for j, size := range useCase.sizes {
+			source := stage.NewSource(fmt.Sprintf("mem://localhost/data/limit%v/%v.json", i+1, j), sourceTime)
+			source.Size = size
+			process := stage.NewProcess(fmt.Sprintf("%v%v", i, j), source, rule.Info.URL, true)
+			process.DestTable = "mydataset.mytable"
+			info, err := srv.TryAcquireWindow(ctx, process, rule)
+			if !assert.Nil(t, err, useCase.description) {
+				continue
+			}
+			if info.Window != nil {
+				windows = append(windows, info.Window)
+			}
+		}
+		if !assert.Equal(t, len(useCase.expectURIs), len(windows), useCase.description) {
+			continue
+		}
+		for j, window := range windows {
+			if !rule.Batch.HasLimit() {
+				assert.False(t, srv.IsClosed(ctx, window), useCase.description)
+				continue
+			}
+			closed := j < len(windows)-1
+			assert.Equal(t, closed, srv.IsClosed(ctx, window), useCase.description)
+			err := srv.MatchWindowDataURLs(ctx, rule, window)
+			assert.Nil(t, err, useCase.description)
+			assert.EqualValues(t, useCase.expectURIs[j], window.URIs, useCase.description)
+			assert.True(t, srv.IsClosed(ctx, window), useCase.description)
+		}
+	}
+}
+
+func TestService_MatchWindowDataURLs_Manifest(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	sourceTime := time.Unix(1590000010, 0).UTC()
+	rule := &config.Rule{Batch: &config.Batch{Manifest: true, MultiPath: true}}
+	rule.Batch.Init()
+	rule.Info.URL = "mem://localhost/rules/manifest.yaml"
+	srv := New(func(rule *config.Rule) string {
+		return "mem://localhost/tasks/manifest"
+	}, time.Hour, fs)
+	_ = fs.Upload(ctx, "mem://localhost/data/manifest/other/unrelated.json", file.DefaultFileOsMode, strings.NewReader("{}"))
+
+	acquire := func(URL string) *Info {
+		process := stage.NewProcess(URL, stage.NewSource(URL, sourceTime), rule.Info.URL, true)
+		process.DestTable = "mydataset.mytable"
+		info, err := srv.TryAcquireWindow(ctx, process, rule)
+		assert.Nil(t, err, URL)
+		return info
+	}
+	owner := acquire("mem://localhost/data/manifest/p1/0.json")
+	if !assert.NotNil(t, owner.Window) {
+		return
+	}
+	assert.Nil(t, acquire("mem://localhost/data/manifest/p2/1.json").Window)
+	assert.Nil(t, acquire("mem://localhost/data/manifest/p1/0.json").Window, "duplicated event")
+
+	err := srv.MatchWindowDataURLs(ctx, rule, owner.Window)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"mem://localhost/data/manifest/p1/0.json", "mem://localhost/data/manifest/p2/1.json"}, owner.Window.URIs)
+	assert.True(t, srv.IsClosed(ctx, owner.Window))
+
+	late := acquire("mem://localhost/data/manifest/p1/2.json")
+	if assert.NotNil(t, late.Window, "late data file opens a new window") {
+		assert.NotEqual(t, owner.Window.URL, late.Window.URL)
+		err = srv.MatchWindowDataURLs(ctx, rule, late.Window)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"mem://localhost/data/manifest/p1/2.json"}, late.Window.URIs)
+	}
+}
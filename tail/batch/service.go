@@ -18,7 +18,9 @@ import (
 	"github.com/viant/bqtail/tail/config"
 	"io/ioutil"
 	"path"
+	"sort"
 	"strings"
+	"time"
 )
 
 //Service representa a batch service
@@ -28,11 +30,18 @@ type Service interface {
 
 	//MatchWindowDataURLs returns matching data URLs
 	MatchWindowDataURLs(ctx context.Context, rule *config.Rule, window *Window) error
+
+	//MatchGroupWindows returns a member window for each group rule destination table with matching data URLs
+	MatchGroupWindows(ctx context.Context, rules []*config.Rule, window *Window) ([]*Window, error)
+
+	//IsClosed returns true if window manifest was closed
+	IsClosed(ctx context.Context, window *Window) bool
 }
 
 type service struct {
-	batchURLProvider func(rule *config.Rule) string
-	fs               afs.Service
+	batchURLProvider   func(rule *config.Rule) string
+	lateArrivalHorizon time.Duration
+	fs                 afs.Service
 }
 
 //addLocationFile tracks parent locations for a batch
@@ -62,38 +71,90 @@ func (s *service) TryAcquireWindow(ctx context.Context, process *stage.Process,
 //TryAcquireWindow try to acquire window for batched transfer, only one cloud function can acquire window
 func (s *service) tryAcquireWindow(ctx context.Context, process *stage.Process, rule *config.Rule) (*Info, error) {
 	parentURL, _ := url.Split(process.Source.URL, gs.Scheme)
-	windowDest := process.DestTable
 	ext := path.Ext(process.Source.URL)
 	suffixRaw := process.DestTable + rule.When.Suffix + ext
 
 	if !rule.Batch.MultiPath {
 		suffixRaw += parentURL
 	}
-	windowDest = fmt.Sprintf("%v_%v", process.DestTable, base.Hash(suffixRaw))
+	if rule.Batch.UseManifest() {
+		return s.acquireManifestWindow(ctx, process, rule, suffixRaw, parentURL, process.Source.Time, false)
+	}
+	dest := windowDest(process, rule, suffixRaw, 0)
+	//window data files are listed after window end time, so processed record is checked only by data file event past window end
+	if endTime := rule.Batch.WindowEndTime(process.Source.Time); rule.Group == "" && time.Now().After(endTime) {
+		if time.Since(endTime) > s.lateArrivalHorizon {
+			//processed window record has expired, data file goes to catch-up window right away
+			return s.acquireManifestWindow(ctx, process, rule, suffixRaw+lateSuffix, parentURL, time.Now().UTC(), true)
+		}
+		windowURL := rule.Batch.WindowURL(s.batchURLProvider(rule), dest, process.Source.Time)
+		processed, err := s.loadProcessed(ctx, windowURL)
+		if err != nil {
+			return nil, err
+		}
+		if processed != nil {
+			if processed.Has(process.Source.URL) {
+				return &Info{WindowURL: windowURL}, nil
+			}
+			info, err := s.acquireManifestWindow(ctx, process, rule, suffixRaw+lateSuffix, parentURL, time.Now().UTC(), true)
+			if err == nil {
+				err = s.recordLate(ctx, windowURL, process)
+			}
+			return info, err
+		}
+	}
+	return s.acquireWindow(ctx, process, rule, dest, parentURL, process.Source.Time, false)
+}
+
+//acquireManifestWindow acquires window for supplied time and joins its manifest, late catch-up window uses current time rather than data file modification time
+func (s *service) acquireManifestWindow(ctx context.Context, process *stage.Process, rule *config.Rule, suffixRaw, parentURL string, at time.Time, late bool) (*Info, error) {
+	taskURL := s.batchURLProvider(rule)
+	for sequence := 0; ; sequence++ {
+		dest := windowDest(process, rule, suffixRaw, sequence)
+		windowURL := rule.Batch.WindowURL(taskURL, dest, at)
+		if s.isClosed(ctx, windowURL) {
+			continue
+		}
+		info, err := s.acquireWindow(ctx, process, rule, dest, parentURL, at, late)
+		if err != nil {
+			return nil, err
+		}
+		info.Late = late
+		joined, err := s.join(ctx, process, rule, windowURL)
+		if err != nil || joined {
+			return info, err
+		}
+	}
+}
+
+//acquireWindow try to acquire window with supplied window destination
+func (s *service) acquireWindow(ctx context.Context, process *stage.Process, rule *config.Rule, windowDest, parentURL string, at time.Time, late bool) (*Info, error) {
 	taskURL := s.batchURLProvider(rule)
 	batch := rule.Batch
-	windowURL := batch.WindowURL(taskURL, windowDest, process.Source.Time)
+	windowURL := batch.WindowURL(taskURL, windowDest, at)
+	hasLocations := rule.IsMultiPath() && !batch.UseManifest() && !late
 	exists, _ := s.fs.Exists(ctx, windowURL, option.NewObjectKind(true))
 
-	endTime := batch.WindowEndTime(process.Source.Time)
+	endTime := batch.WindowEndTime(at)
 	startTime := endTime.Add(-batch.Window.Duration)
 	var err error
 	var window *Window
 	if exists {
 		window = NewWindow(process, startTime, endTime, windowURL)
-		if rule.Batch.MultiPath {
+		if hasLocations {
 			err = s.addLocationFile(ctx, window, parentURL)
 		}
 		return &Info{OwnerEventID: window.EventID, WindowURL: windowURL}, err
 	}
 
-	if batch.RollOver && !batch.IsWithinFirstHalf(process.Source.Time) {
-		prevWindowURL := batch.WindowURL(taskURL, windowDest, process.Source.Time.Add(-(1 + batch.Window.Duration)))
+	if batch.RollOver && !batch.IsWithinFirstHalf(at) {
+		prevWindowURL := batch.WindowURL(taskURL, windowDest, at.Add(-(1 + batch.Window.Duration)))
 		if exists, _ := s.fs.Exists(ctx, prevWindowURL, option.NewObjectKind(true)); !exists {
 			startTime = startTime.Add(-batch.Window.Duration)
 		}
 	}
 	window = NewWindow(process, startTime, endTime, windowURL)
+	window.Late = late
 	windowData, _ := json.Marshal(window)
 	err = s.fs.Upload(ctx, windowURL, file.DefaultFileOsMode, bytes.NewReader(windowData), option.NewGeneration(true, 0))
 
@@ -101,14 +162,14 @@ func (s *service) tryAcquireWindow(ctx context.Context, process *stage.Process,
 	//if there is a race condition ignore precondition or rate limit it means batch file exists, - ignore error and quite
 	if isPreConditionError(err) || isRateError(err) {
 		window := NewWindow(process, startTime, endTime, windowURL)
-		if rule.Batch.MultiPath {
+		if hasLocations {
 			if err = s.addLocationFile(ctx, window, parentURL); err != nil {
 				return nil, err
 			}
 		}
 		return &Info{OwnerEventID: window.EventID, WindowURL: windowURL}, nil
 	}
-	if rule.Batch.MultiPath {
+	if hasLocations {
 		err = s.addLocationFile(ctx, window, parentURL)
 	}
 	return &Info{Window: window}, err
@@ -134,7 +195,7 @@ func (s *service) getBaseURLS(ctx context.Context, rule *config.Rule, window *Wi
 	baseURL, _ := url.Split(window.Source.URL, gs.Scheme)
 	baseURLs[baseURL] = true
 
-	if rule.Batch.MultiPath {
+	if rule.IsMultiPath() {
 		window.Locations = make([]string, 0)
 		URL := strings.Replace(window.URL, shared.WindowExt, "/", 1)
 		objects, err := s.fs.List(ctx, URL)
@@ -162,11 +223,18 @@ func (s *service) getBaseURLS(ctx context.Context, rule *config.Rule, window *Wi
 
 //MatchWindowData matches window data, it waits for window to ends if needed
 func (s *service) MatchWindowDataURLs(ctx context.Context, rule *config.Rule, window *Window) (err error) {
+	if rule.Batch.UseManifest() || window.Late {
+		return s.matchManifestURLs(ctx, window)
+	}
 	before := window.End          //inclusive
 	after := window.Start.Add(-1) //exclusive
 	modFilter := matcher.NewModification(&before, &after)
 	window.Resources = make([]*Resource, 0)
-
+	if rule.Group == "" {
+		if err = s.reserveProcessed(ctx, window.URL); err != nil {
+			return err
+		}
+	}
 	var baseURLS []string
 	err = base.RunWithRetries(func() error {
 		baseURLS, err = s.getBaseURLS(ctx, rule, window)
@@ -182,7 +250,74 @@ func (s *service) MatchWindowDataURLs(ctx context.Context, rule *config.Rule, wi
 		}
 	}
 	window.URIs = result
-	return nil
+	if rule.Group != "" {
+		return nil
+	}
+	return s.recordProcessed(ctx, window)
+}
+
+//MatchGroupWindows returns a member window for each group rule destination table with matching data URLs
+func (s *service) MatchGroupWindows(ctx context.Context, rules []*config.Rule, window *Window) ([]*Window, error) {
+	if len(rules) == 0 {
+		return nil, errors.Errorf("group rules were empty for %v", window.URL)
+	}
+	var baseURLS []string
+	var err error
+	err = base.RunWithRetries(func() error {
+		baseURLS, err = s.getBaseURLS(ctx, rules[0], window)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get group batch location: %v", window.URL)
+	}
+	var members = make(map[string]*Window)
+	for _, baseURL := range baseURLS {
+		objects, err := s.fs.List(ctx, baseURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list group batch %v data files", baseURL)
+		}
+		for _, object := range objects {
+			if object.IsDir() || !object.ModTime().Before(window.End) || object.ModTime().Before(window.Start) {
+				continue
+			}
+			for _, rule := range rules {
+				if !rule.HasMatch(object.URL()) {
+					continue
+				}
+				source := stage.NewSource(object.URL(), object.ModTime())
+				table, err := rule.Dest.ExpandTable(rule.Dest.Table, source)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to expand table: %v", rule.Dest.Table)
+				}
+				member, ok := members[table]
+				if !ok {
+					process := *window.Process
+					process.Source = source
+					process.RuleURL = rule.Info.URL
+					process.DestTable = table
+					process.Params = nil
+					process.StepCount = 0
+					member = NewWindow(&process, window.Start, window.End, window.URL)
+					member.Locations = window.Locations
+					member.URIs = make([]string, 0)
+					member.Resources = make([]*Resource, 0)
+					members[table] = member
+				} else if member.RuleURL != rule.Info.URL {
+					return nil, errors.Errorf("group %v rules %v and %v share the same destination table: %v", rule.Group, member.RuleURL, rule.Info.URL, table)
+				}
+				member.URIs = append(member.URIs, object.URL())
+				member.Resources = append(member.Resources, &Resource{URL: object.URL(), ModTime: object.ModTime(), Size: object.Size()})
+			}
+		}
+	}
+	var result = make([]*Window, 0)
+	for _, member := range members {
+		result = append(result, member)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DestTable < result[j].DestTable
+	})
+	return result, nil
 }
 
 func (s *service) matchData(ctx context.Context, window *Window, rule *config.Rule, baseURL string, matcher option.Matcher, result *[]string) error {
@@ -208,16 +343,17 @@ func (s *service) matchData(ctx context.Context, window *Window, rule *config.Ru
 				continue
 			}
 			*result = append(*result, object.URL())
-			window.Resources = append(window.Resources, &Resource{URL: object.URL(), ModTime: object.ModTime()})
+			window.Resources = append(window.Resources, &Resource{URL: object.URL(), ModTime: object.ModTime(), Size: object.Size()})
 		}
 	}
 	return nil
 }
 
 //New create stage service
-func New(batchURLProvider func(rule *config.Rule) string, storageService afs.Service) Service {
+func New(batchURLProvider func(rule *config.Rule) string, lateArrivalHorizon time.Duration, storageService afs.Service) Service {
 	return &service{
-		batchURLProvider: batchURLProvider,
-		fs:               storageService,
+		batchURLProvider:   batchURLProvider,
+		lateArrivalHorizon: lateArrivalHorizon,
+		fs:                 storageService,
 	}
 }
@@ -15,11 +15,16 @@ type Info struct {
 	*Window
 	WindowURL    string
 	OwnerEventID string
+	//Late is set when data file belongs to already processed window
+	Late bool `json:",omitempty"`
 }
 
 type Resource struct {
 	URL     string
 	ModTime time.Time
+	Size    int64 `json:",omitempty"`
+	//EventID event ID of window that loaded resource, set for processed window record
+	EventID string `json:",omitempty"`
 }
 
 //Window represent batching window
@@ -31,6 +36,10 @@ type Window struct {
 	URIs      []string    `json:",omitempty"`
 	Resources []*Resource `json:",omitempty"`
 	Locations []string    `json:",omitempty"`
+	//TrackingURLs window manifest and closed window marker URLs
+	TrackingURLs []string `json:",omitempty"`
+	//Late catch-up window collecting late data files of already processed windows
+	Late bool `json:",omitempty"`
 }
 
 
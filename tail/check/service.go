@@ -0,0 +1,201 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/schema"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/tail/sql"
+	"google.golang.org/api/bigquery/v2"
+	"regexp"
+	"strings"
+)
+
+//aliasExpr matches leading identifier of qualified column reference
+var aliasExpr = regexp.MustCompile(`(?:^|[^\w.])([A-Za-z_]\w*)\.[A-Za-z_]`)
+
+//literalExpr matches quoted SQL literals and identifiers
+var literalExpr = regexp.MustCompile("`[^`]*`|'[^']*'|\"[^\"]*\"")
+
+//Service represents rule check service
+type Service interface {
+	//Check checks rule Transform, Split and SideInputs against destination and transient template schemas
+	Check(ctx context.Context, rule *config.Rule) error
+}
+
+type service struct {
+	bq bq.Service
+}
+
+//Check checks rule destinations, SQL produced for each destination is verified with BigQuery dry run
+func (s *service) Check(ctx context.Context, rule *config.Rule) error {
+	if rule.Dest == nil || rule.Dest.Transient == nil || rule.Dest.Schema.Autodetect {
+		return nil
+	}
+	sourceRef, source, err := s.source(ctx, rule.Dest)
+	if err != nil || source == nil || source.Schema == nil {
+		return err
+	}
+	var problems = make([]string, 0)
+	for i, dest := range append([]*config.Destination{rule.Dest}, rule.Dests...) {
+		name := "dest"
+		if i > 0 {
+			name = fmt.Sprintf("dests[%v]", i-1)
+		}
+		destination, err := s.destination(ctx, dest)
+		if err != nil {
+			return err
+		}
+		destProblems, err := s.checkDest(ctx, dest, sourceRef, source, destination)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check %v", name)
+		}
+		for _, problem := range destProblems {
+			problems = append(problems, name+"."+problem)
+		}
+	}
+	if len(problems) > 0 {
+		return &config.InvalidRuleError{URL: rule.Info.URL, Problems: problems}
+	}
+	return nil
+}
+
+//checkDest returns destination problems, dry run error other than SQL or schema problem (i.e. rate limit, backend error) is returned as error
+func (s *service) checkDest(ctx context.Context, dest *config.Destination, sourceRef *bigquery.TableReference, source, destination *bigquery.Table) ([]string, error) {
+	var problems = make([]string, 0)
+	if destination != nil && destination.Schema != nil {
+		for key := range dest.Transform {
+			if schema.Field(destination.Schema.Fields, key) == nil {
+				problems = append(problems, fmt.Sprintf("transform: %v column not found in %v", key, base.EncodeTableReference(destination.TableReference, false)))
+			}
+		}
+	}
+	if split := dest.Schema.Split; split != nil {
+		for _, column := range split.ClusterColumns {
+			if schema.Field(source.Schema.Fields, column) == nil {
+				problems = append(problems, fmt.Sprintf("schema.split.clusterColumns: %v column not found in transient schema", column))
+			}
+		}
+		if split.TimeColumn != "" && schema.Field(source.Schema.Fields, split.TimeColumn) == nil {
+			problems = append(problems, fmt.Sprintf("schema.split.timeColumn: %v column not found in transient schema", split.TimeColumn))
+		}
+	}
+	problems = append(problems, checkSideInputs(dest, source.Schema)...)
+	if len(problems) > 0 {
+		return problems, nil
+	}
+	var destSchema *bigquery.TableSchema
+	if destination != nil {
+		destSchema = destination.Schema
+	}
+	selectSQL := sql.BuildSelect(sourceRef, source.Schema, dest, destSchema)
+	where := ""
+	if dest.Transient != nil && dest.Transient.Criteria != "" {
+		where = " WHERE " + dest.Transient.Criteria
+	}
+	if err := s.dryRun(ctx, sourceRef.ProjectId, strings.Replace(selectSQL, "$WHERE", where, 1)); err != nil {
+		if isTransient(err) {
+			return nil, err
+		}
+		problems = append(problems, fmt.Sprintf("transform: %v", err))
+	}
+	if split := dest.Schema.Split; split != nil {
+		for i, mapping := range split.Mapping {
+			if err := s.dryRun(ctx, sourceRef.ProjectId, strings.Replace(selectSQL, "$WHERE", " WHERE "+mapping.When, 1)); err != nil {
+				if isTransient(err) {
+					return nil, err
+				}
+				problems = append(problems, fmt.Sprintf("schema.split.mapping[%v].when: %v", i, err))
+			}
+		}
+	}
+	return problems, nil
+}
+
+//dryRun runs SQL dry run, retrying on backend unavailability
+func (s *service) dryRun(ctx context.Context, projectID, SQL string) error {
+	return base.RunWithRetries(func() error {
+		_, err := s.bq.DryRun(ctx, projectID, SQL)
+		return err
+	})
+}
+
+//isTransient returns true if error is not caused by the checked SQL, i.e. rate limit or backend error
+func isTransient(err error) bool {
+	return base.IsRetryError(err) || base.IsBackendError(err) || base.IsInternalError(err) || base.IsRateLimitError(err)
+}
+
+//checkSideInputs returns side inputs join criteria referencing undefined alias
+func checkSideInputs(dest *config.Destination, source *bigquery.TableSchema) []string {
+	var problems = make([]string, 0)
+	var aliases = map[string]bool{strings.ToLower(dest.Transient.Alias): true}
+	for i, sideInput := range dest.SideInputs {
+		aliases[strings.ToLower(sideInput.Alias)] = true
+		criteria := literalExpr.ReplaceAllString(sideInput.On, "")
+		for _, match := range aliasExpr.FindAllStringSubmatch(criteria, -1) {
+			alias := strings.ToLower(match[1])
+			if aliases[alias] || schema.Field(source.Fields, alias) != nil {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("sideInputs[%v].on: undefined alias: %v", i, match[1]))
+		}
+	}
+	return problems
+}
+
+//source returns transient table source schema, transient template or destination schema
+func (s *service) source(ctx context.Context, dest *config.Destination) (*bigquery.TableReference, *bigquery.Table, error) {
+	if dest.Transient.Template != "" {
+		return s.table(ctx, dest.Transient.Template)
+	}
+	if dest.Schema.Template != "" {
+		return s.table(ctx, dest.Schema.Template)
+	}
+	if strings.Contains(dest.Table, "$") {
+		return nil, nil, nil
+	}
+	tableRef, table, err := s.table(ctx, dest.Table)
+	if base.IsNotFoundError(err) {
+		return nil, nil, nil
+	}
+	return tableRef, table, err
+}
+
+//destination returns destination table or template, nil if table is dynamic or does not exist yet
+func (s *service) destination(ctx context.Context, dest *config.Destination) (*bigquery.Table, error) {
+	if dest.Schema.Template != "" {
+		_, table, err := s.table(ctx, dest.Schema.Template)
+		return table, err
+	}
+	if strings.Contains(dest.Table, "$") {
+		return nil, nil
+	}
+	_, table, err := s.table(ctx, dest.Table)
+	if base.IsNotFoundError(err) {
+		return nil, nil
+	}
+	return table, err
+}
+
+func (s *service) table(ctx context.Context, table string) (*bigquery.TableReference, *bigquery.Table, error) {
+	tableRef, err := base.NewTableReference(table)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid table: %v", table)
+	}
+	result, err := s.bq.Table(ctx, tableRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.TableReference == nil {
+		result.TableReference = tableRef
+	}
+	return tableRef, result, nil
+}
+
+//New creates rule check service
+func New(bqService bq.Service) Service {
+	return &service{bq: bqService}
+}
@@ -0,0 +1,135 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestService_Check(t *testing.T) {
+	events := &bigquery.Table{Schema: &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "type_id", Type: "INTEGER"},
+		{Name: "event_type", Type: "STRING"},
+		{Name: "info", Type: "RECORD", Fields: []*bigquery.TableFieldSchema{{Name: "key", Type: "STRING"}}},
+	}}}
+	types := &bigquery.Table{Schema: &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "name", Type: "STRING"},
+	}}}
+	srv := New(bq.NewFakerWithTables(map[string]*bigquery.Table{
+		"mydataset.events": events,
+		"mydataset.types":  types,
+	}))
+	sideInputs := []*config.SideInput{{Table: "mydataset.types", Alias: "et", On: "t.type_id = et.id"}}
+
+	var useCases = []struct {
+		description string
+		dest        *config.Destination
+		expect      string
+	}{
+		{
+			description: "valid transform with side input",
+			dest: &config.Destination{
+				Table:      "mydataset.events",
+				Transient:  &config.Transient{Dataset: "temp", Alias: "t"},
+				Transform:  map[string]string{"event_type": "et.name"},
+				SideInputs: sideInputs,
+			},
+		},
+		{
+			description: "transform key missing in destination",
+			dest: &config.Destination{
+				Table:     "mydataset.events",
+				Transient: &config.Transient{Dataset: "temp", Alias: "t"},
+				Transform: map[string]string{"evnt_type": "'x'"},
+			},
+			expect: "dest.transform: evnt_type column not found in mydataset.events",
+		},
+		{
+			description: "transform expression typo",
+			dest: &config.Destination{
+				Table:      "mydataset.events",
+				Transient:  &config.Transient{Dataset: "temp", Alias: "t"},
+				Transform:  map[string]string{"event_type": "et.nme"},
+				SideInputs: sideInputs,
+			},
+			expect: "Unrecognized name: et.nme",
+		},
+		{
+			description: "split cluster column missing",
+			dest: &config.Destination{
+				Table:     "mydataset.events",
+				Transient: &config.Transient{Dataset: "temp", Alias: "t"},
+				Schema: config.Schema{Split: &config.Split{
+					ClusterColumns: []string{"info.key", "info.value"},
+					Mapping:        []*config.TableMapping{{When: "t.id > 0", Then: "mydataset.events_a"}},
+				}},
+			},
+			expect: "dest.schema.split.clusterColumns: info.value column not found in transient schema",
+		},
+		{
+			description: "split mapping typo",
+			dest: &config.Destination{
+				Table:     "mydataset.events",
+				Transient: &config.Transient{Dataset: "temp", Alias: "t"},
+				Schema: config.Schema{Split: &config.Split{
+					Mapping: []*config.TableMapping{{When: "t.idd > 0", Then: "mydataset.events_a"}},
+				}},
+			},
+			expect: "dest.schema.split.mapping[0].when",
+		},
+		{
+			description: "side input undefined alias",
+			dest: &config.Destination{
+				Table:      "mydataset.events",
+				Transient:  &config.Transient{Dataset: "temp", Alias: "t"},
+				SideInputs: []*config.SideInput{{Table: "mydataset.types", Alias: "et", On: "t.type_id = e.id AND et.id > 0"}},
+			},
+			expect: "dest.sideInputs[0].on: undefined alias: e",
+		},
+	}
+
+	for _, useCase := range useCases {
+		err := srv.Check(context.Background(), &config.Rule{Dest: useCase.dest})
+		if useCase.expect == "" {
+			assert.Nil(t, err, useCase.description)
+			continue
+		}
+		if assert.NotNil(t, err, useCase.description) {
+			assert.Contains(t, err.Error(), useCase.expect, useCase.description)
+		}
+	}
+}
+
+type rateLimitedBq struct {
+	bq.Service
+}
+
+func (s *rateLimitedBq) DryRun(ctx context.Context, projectID, SQL string) (*bigquery.Job, error) {
+	return nil, errors.New("googleapi: Error 429: Exceeded rate limits, rateLimitExceeded")
+}
+
+func TestService_Check_Transient(t *testing.T) {
+	events := &bigquery.Table{Schema: &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{{Name: "id", Type: "INTEGER"}}}}
+	srv := New(&rateLimitedBq{Service: bq.NewFakerWithTables(map[string]*bigquery.Table{"mydataset.events": events})})
+	err := srv.Check(context.Background(), &config.Rule{Dest: &config.Destination{
+		Table:     "mydataset.events",
+		Transient: &config.Transient{Dataset: "temp", Alias: "t"},
+	}})
+	if assert.NotNil(t, err) {
+		assert.False(t, config.IsInvalidRuleError(err), "rate limited dry run is not a rule problem")
+	}
+	err = New(bq.NewFakerWithTables(map[string]*bigquery.Table{"mydataset.events": events})).Check(context.Background(), &config.Rule{Dest: &config.Destination{
+		Table:     "mydataset.events",
+		Transient: &config.Transient{Dataset: "temp", Alias: "t"},
+		Transform: map[string]string{"idd": "1"},
+	}})
+	if assert.NotNil(t, err) {
+		assert.True(t, config.IsInvalidRuleError(err), "schema problem")
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+//Assertion represents transient table data quality check, a failed check stops copying data to destination
+type Assertion struct {
+	//Name assertion name reported on failure
+	Name string `json:",omitempty"`
+	//Expr aggregate or predicate SQL evaluated against transient table, i.e. COUNT(*) > 0, COUNTIF(id IS NULL) = 0
+	Expr string `json:",omitempty"`
+	//Observed optional SQL expression reported on failure, defaults to left operand of Expr comparison
+	Observed string `json:",omitempty"`
+}
+
+//Init initialises assertion
+func (a *Assertion) Init(index int) {
+	if a.Name == "" {
+		a.Name = fmt.Sprintf("assertion%v", index)
+	}
+}
+
+//Validate checks if assertion is valid
+func (a Assertion) Validate() error {
+	if a.Expr == "" {
+		return errors.Errorf("assertion %v expr was empty", a.Name)
+	}
+	return nil
+}
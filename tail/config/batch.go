@@ -22,6 +22,15 @@ type Batch struct {
 	//MaxDelayInSec delay before collecting batch file. to randomly distribute workload,
 	// when a table has 40 shards, 40 batches would start exactly at the same time unless this parameter is specified
 	MaxDelayInSec int `json:",omitempty"`
+
+	//MaxFiles closes batch window before its end time once it tracks that many data files, subsequent files open a new window
+	MaxFiles int `json:",omitempty"`
+
+	//MaxBytes closes batch window before its end time once tracked data files total size reaches that many bytes
+	MaxBytes int64 `json:",omitempty"`
+
+	//Manifest if set, data files joining batch window are appended to the window manifest, window loads manifest data files without storage listing
+	Manifest bool `json:",omitempty"`
 }
 
 //Init initialises batch mode
@@ -42,6 +51,21 @@ func (b *Batch) MaxDelayMs(minInMs int) int {
 	return maxDelayMs
 }
 
+//HasLimit returns true if batch window can be closed early by data files count or size
+func (b *Batch) HasLimit() bool {
+	return b.MaxFiles > 0 || b.MaxBytes > 0
+}
+
+//UseManifest returns true if window data files are tracked with manifest, batch limit requires manifest
+func (b *Batch) UseManifest() bool {
+	return b.Manifest || b.HasLimit()
+}
+
+//IsFull returns true if tracked data files count or size reached batch limit
+func (b *Batch) IsFull(files int, size int64) bool {
+	return (b.MaxFiles > 0 && files >= b.MaxFiles) || (b.MaxBytes > 0 && size >= b.MaxBytes)
+}
+
 //Validate checks if batch configuration is valid
 func (b *Batch) Validate() error {
 	return b.Window.Validate()
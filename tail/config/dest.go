@@ -9,6 +9,7 @@ import (
 	"github.com/viant/bqtail/shared"
 	"github.com/viant/bqtail/stage"
 	"github.com/viant/bqtail/tail/config/pattern"
+	"github.com/viant/bqtail/tail/config/transient"
 	"github.com/viant/toolbox/data"
 	"google.golang.org/api/bigquery/v2"
 	"regexp"
@@ -40,9 +41,11 @@ type Destination struct {
 	UniqueColumns    []string          `json:",omitempty"`
 	Transform        map[string]string `json:",omitempty" description:"optional map of the source column to dest expression"`
 	SideInputs       []*SideInput      `json:",omitempty"`
+	Assertions       []*Assertion      `json:",omitempty" description:"optional transient table data quality checks run before copy"`
 	Override         *bool
 
 	AllowFieldAddition bool   `json:",omitempty"`
+	AllowTypeWidening  bool   `json:",omitempty" description:"widens INT64 to NUMERIC/FLOAT64 and relaxes REQUIRED to NULLABLE on load type mismatch"`
 	Expiry             string `json:",omitempty"`
 }
 
@@ -89,6 +92,7 @@ func (d Destination) Clone() *Destination {
 		Transient:        d.Transient,
 		UniqueColumns:    d.UniqueColumns,
 		SideInputs:       d.SideInputs,
+		Assertions:       d.Assertions,
 		Override:         d.Override,
 	}
 
@@ -115,6 +119,9 @@ func (d Destination) Validate() error {
 		if err := d.Schema.Split.Validate(); err != nil {
 			return err
 		}
+		if d.Schema.Split.IsScript() && !d.IsAppend() {
+			return fmt.Errorf("dest.Schema.Split execution %v requires append mode", shared.SplitExecutionScript)
+		}
 	}
 
 	if len(d.SideInputs) > 0 {
@@ -127,6 +134,16 @@ func (d Destination) Validate() error {
 			}
 		}
 	}
+	if len(d.Assertions) > 0 {
+		if d.Transient == nil || d.Transient.Dataset == "" {
+			return errors.Errorf("assertion %v requires transient.dataset", d.Assertions[0].Name)
+		}
+		for _, assertion := range d.Assertions {
+			if err := assertion.Validate(); err != nil {
+				return err
+			}
+		}
+	}
 	if d.Transient != nil {
 		if err := d.Transient.Validate(); err != nil {
 			return err
@@ -137,13 +154,31 @@ func (d Destination) Validate() error {
 
 		if d.Transient.CopyMethod != nil {
 			switch *d.Transient.CopyMethod {
-			case shared.CopyMethodCopy, shared.CopyMethodDML, shared.CopyMethodQuery:
+			case shared.CopyMethodCopy, shared.CopyMethodDML, shared.CopyMethodQuery, shared.CopyMethodMerge, shared.CopyMethodReplace:
 			default:
-				return errors.Errorf("invalid Transient.CopyMethod: %v, valid:[]", *d.Transient.CopyMethod, []string{
-					shared.CopyMethodCopy, shared.CopyMethodDML, shared.CopyMethodQuery,
+				return errors.Errorf("invalid Transient.CopyMethod: %v, valid:%v", *d.Transient.CopyMethod, []string{
+					shared.CopyMethodCopy, shared.CopyMethodDML, shared.CopyMethodQuery, shared.CopyMethodMerge, shared.CopyMethodReplace,
 				})
 			}
 		}
+		if d.IsCopyMethodMerge() {
+			if len(d.UniqueColumns) == 0 {
+				return errors.Errorf("Transient.CopyMethod %v requires dest.UniqueColumns", shared.CopyMethodMerge)
+			}
+			if d.Transient.Merge != nil {
+				if err := d.Transient.Merge.Validate(); err != nil {
+					return err
+				}
+			}
+		}
+		if d.IsCopyMethodReplace() {
+			if d.Partition != "" {
+				return errors.Errorf("Transient.CopyMethod %v can not be used with dest.Partition: %v", shared.CopyMethodReplace, d.Partition)
+			}
+			if d.HasSplit() || d.Schema.Autodetect {
+				return errors.Errorf("Transient.CopyMethod %v can not be used with dest.Schema.Split or dest.Schema.Autodetect", shared.CopyMethodReplace)
+			}
+		}
 	}
 
 	if d.Table != "" {
@@ -184,9 +219,12 @@ func (d *Destination) Init() error {
 	if len(d.Transform) == 0 {
 		d.Transform = make(map[string]string)
 	}
+	for i, assertion := range d.Assertions {
+		assertion.Init(i)
+	}
 	if d.AllowFieldAddition && (d.SourceFormat == "AVRO" || d.SourceFormat == "PARQUET") {
 		if len(d.SchemaUpdateOptions) == 0 {
-			d.SchemaUpdateOptions = []string{"ALLOW_FIELD_ADDITION", "ALLOW_FIELD_RELAXATION"}
+			d.SchemaUpdateOptions = []string{shared.AllowFieldAddition, shared.AllowFieldRelaxation}
 		}
 		d.WriteDisposition = shared.WriteDispositionAppend
 	}
@@ -198,6 +236,12 @@ func (d *Destination) Init() error {
 	} else if d.Transient != nil && d.Transient.CopyMethod == nil {
 		d.Transient.CopyMethod = &shared.CopyMethodCopy
 	}
+	if d.IsCopyMethodMerge() {
+		if d.Transient.Merge == nil {
+			d.Transient.Merge = &transient.Merge{}
+		}
+		d.Transient.Merge.Init()
+	}
 	return nil
 }
 
@@ -221,6 +265,38 @@ func (d *Destination) IsCopyMethodQuery() bool {
 	return false
 }
 
+//IsCopyMethodDML returns true if DML copy method
+func (d *Destination) IsCopyMethodDML() bool {
+	if d.Transient == nil || d.Transient.CopyMethod == nil {
+		return false
+	}
+	return strings.ToUpper(*d.Transient.CopyMethod) == shared.CopyMethodDML
+}
+
+//IsAppend returns true if destination table is appended
+func (d *Destination) IsAppend() bool {
+	if d.Override != nil {
+		return !*d.Override
+	}
+	return d.WriteDisposition == "" || d.WriteDisposition == "WRITE_APPEND"
+}
+
+//IsCopyMethodMerge returns true if MERGE copy method
+func (d *Destination) IsCopyMethodMerge() bool {
+	if d.Transient == nil || d.Transient.CopyMethod == nil {
+		return false
+	}
+	return strings.ToUpper(*d.Transient.CopyMethod) == shared.CopyMethodMerge
+}
+
+//IsCopyMethodReplace returns true if REPLACE copy method
+func (d *Destination) IsCopyMethodReplace() bool {
+	if d.Transient == nil || d.Transient.CopyMethod == nil {
+		return false
+	}
+	return strings.ToUpper(*d.Transient.CopyMethod) == shared.CopyMethodReplace
+}
+
 //HasTransformation returns true if dest requires transformation
 func (d *Destination) HasTransformation() bool {
 	return len(d.SideInputs) > 0 || len(d.Transform) > 0 || d.Schema.Split != nil || len(d.UniqueColumns) > 0
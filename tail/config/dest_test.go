@@ -153,3 +153,27 @@ func TestDestination_TableReference(t *testing.T) {
 	}
 
 }
+
+func TestDestination_IsCopyMethod(t *testing.T) {
+	var useCases = []struct {
+		description   string
+		method        string
+		expectMerge   bool
+		expectReplace bool
+		expectDML     bool
+	}{
+		{description: "upper merge", method: "MERGE", expectMerge: true},
+		{description: "lower merge", method: "merge", expectMerge: true},
+		{description: "mixed replace", method: "Replace", expectReplace: true},
+		{description: "lower dml", method: "dml", expectDML: true},
+		{description: "copy", method: "COPY"},
+	}
+
+	for _, useCase := range useCases {
+		method := useCase.method
+		dest := &Destination{Transient: &Transient{CopyMethod: &method}}
+		assert.EqualValues(t, useCase.expectMerge, dest.IsCopyMethodMerge(), useCase.description)
+		assert.EqualValues(t, useCase.expectReplace, dest.IsCopyMethodReplace(), useCase.description)
+		assert.EqualValues(t, useCase.expectDML, dest.IsCopyMethodDML(), useCase.description)
+	}
+}
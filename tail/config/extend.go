@@ -0,0 +1,167 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/url"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+const extendsKey = "Extends"
+
+//Extend returns rule document with deep merged base rules
+func (r *Ruleset) Extend(ctx context.Context, fs afs.Service, URL string, data []byte) ([]byte, error) {
+	data, _, err := r.extend(ctx, fs, URL, data)
+	return data, err
+}
+
+//extend returns rule document with deep merged base rules and base rules URLs
+func (r *Ruleset) extend(ctx context.Context, fs afs.Service, URL string, data []byte) ([]byte, []string, error) {
+	document, err := decodeDocument(data, path.Ext(URL))
+	if err != nil {
+		return data, nil, nil //decoding error is reported by loadRules
+	}
+	document = normalize(document)
+	var bases = make([]string, 0)
+	var extended bool
+	switch actual := document.(type) {
+	case map[string]interface{}:
+		if document, extended, err = r.resolve(ctx, fs, URL, actual, nil, &bases); err != nil {
+			return nil, bases, err
+		}
+	case []interface{}:
+		for i, item := range actual {
+			ruleMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			resolved, ok, err := r.resolve(ctx, fs, URL, ruleMap, nil, &bases)
+			if err != nil {
+				return nil, bases, errors.Wrapf(err, "failed to extend rule[%v]", i)
+			}
+			actual[i] = resolved
+			extended = extended || ok
+		}
+	}
+	if !extended {
+		return data, nil, nil
+	}
+	data, err = json.Marshal(document)
+	return data, bases, err
+}
+
+//resolve merges rule with its base rules chain, child rule values override base values
+func (r *Ruleset) resolve(ctx context.Context, fs afs.Service, URL string, rule map[string]interface{}, chain []string, bases *[]string) (map[string]interface{}, bool, error) {
+	_, value := lookupKey(rule, extendsKey)
+	baseURL, _ := value.(string)
+	if baseURL == "" {
+		return rule, false, nil
+	}
+	if url.IsRelative(baseURL) {
+		parent, _ := url.Split(URL, "")
+		baseURL = url.JoinUNC(parent, baseURL)
+	}
+	chain = append(chain, URL)
+	for _, visited := range chain {
+		if visited == baseURL {
+			return nil, false, errors.Errorf("circular %v: %v", extendsKey, strings.Join(append(chain, baseURL), " -> "))
+		}
+	}
+	*bases = append(*bases, baseURL)
+	base, err := r.loadBase(ctx, fs, baseURL)
+	if err != nil {
+		return nil, false, err
+	}
+	if base, _, err = r.resolve(ctx, fs, baseURL, base, chain, bases); err != nil {
+		return nil, false, err
+	}
+	result := merge(base, rule)
+	key, _ := lookupKey(result, extendsKey)
+	result[key] = baseURL //resolved URL keeps extended document relocatable
+	return result, true, nil
+}
+
+//loadBase loads a base rule document
+func (r *Ruleset) loadBase(ctx context.Context, fs afs.Service, URL string) (map[string]interface{}, error) {
+	reader, err := fs.DownloadWithURL(ctx, URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load base rule: %v", URL)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read base rule: %v", URL)
+	}
+	if err = r.verify(URL, data); err != nil {
+		return nil, err
+	}
+	document, err := decodeDocument(data, path.Ext(URL))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode base rule: %v", URL)
+	}
+	result, ok := normalize(document).(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("invalid base rule: %v, expected a single rule", URL)
+	}
+	return result, nil
+}
+
+//merge deep merges override into base, maps are merged by case insensitive key, other values are replaced
+func merge(base, override map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{})
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range override {
+		baseKey, baseValue := lookupKey(result, k)
+		if baseKey != "" {
+			delete(result, baseKey)
+		}
+		baseMap, isBaseMap := baseValue.(map[string]interface{})
+		overrideMap, isOverrideMap := v.(map[string]interface{})
+		if isBaseMap && isOverrideMap {
+			v = merge(baseMap, overrideMap)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func lookupKey(aMap map[string]interface{}, key string) (string, interface{}) {
+	for k, v := range aMap {
+		if strings.EqualFold(k, key) {
+			return k, v
+		}
+	}
+	return "", nil
+}
+
+//normalize converts decoded YAML maps to string keyed maps
+func normalize(value interface{}) interface{} {
+	switch actual := value.(type) {
+	case map[interface{}]interface{}:
+		var result = make(map[string]interface{})
+		for k, v := range actual {
+			result[fmt.Sprint(k)] = normalize(v)
+		}
+		return result
+	case map[string]interface{}:
+		for k, v := range actual {
+			actual[k] = normalize(v)
+		}
+		return actual
+	case []interface{}:
+		for i, v := range actual {
+			actual[i] = normalize(v)
+		}
+		return actual
+	}
+	return value
+}
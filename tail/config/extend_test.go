@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleset_Extend(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/extend/base"
+	assets := map[string]string{
+		"root.yaml": `Dest:
+  Transient:
+    Dataset: temp
+  UniqueColumns:
+    - id
+Batch:
+  Window:
+    DurationInSec: 120
+CorruptedFileURL: gs://ops/corrupted
+OnFailure:
+  - Action: notify
+`,
+		"transient.yaml": `Extends: root.yaml
+Dest:
+  Transient:
+    Alias: t
+Batch:
+  Window:
+    DurationInSec: 90
+`,
+		"cycle1.yaml": `Extends: cycle2.yaml`,
+		"cycle2.yaml": `Extends: cycle1.yaml`,
+	}
+	for name, content := range assets {
+		err := fs.Upload(ctx, url.Join(baseURL, name), file.DefaultFileOsMode, strings.NewReader(content))
+		assert.Nil(t, err)
+	}
+
+	useCases := []struct {
+		description string
+		URL         string
+		rule        string
+		expect      map[string]interface{}
+		hasError    bool
+	}{
+		{
+			description: "base chain deep merge",
+			URL:         "mem://localhost/extend/rules/rule1.yaml",
+			rule: `Extends: ../base/transient.yaml
+When:
+  Prefix: /data/
+Dest:
+  Table: db.events
+  UniqueColumns:
+    - event_id
+`,
+			expect: map[string]interface{}{
+				"Extends":          "mem://localhost/extend/base/transient.yaml",
+				"Table":            "db.events",
+				"Dataset":          "temp",
+				"Alias":            "t",
+				"UniqueColumns":    []string{"event_id"},
+				"DurationInSec":    90,
+				"CorruptedFileURL": "gs://ops/corrupted",
+				"OnFailure":        1,
+			},
+		},
+		{
+			description: "json rule with absolute base",
+			URL:         "mem://localhost/extend/rules/rule2.json",
+			rule:        `{"Extends": "mem://localhost/extend/base/root.yaml", "When": {"Prefix": "/data/"}, "Dest": {"Table": "db.clicks"}, "CorruptedFileURL": "gs://ops/bad"}`,
+			expect: map[string]interface{}{
+				"Extends":          "mem://localhost/extend/base/root.yaml",
+				"Table":            "db.clicks",
+				"Dataset":          "temp",
+				"Alias":            "t",
+				"UniqueColumns":    []string{"id"},
+				"DurationInSec":    120,
+				"CorruptedFileURL": "gs://ops/bad",
+				"OnFailure":        1,
+			},
+		},
+		{
+			description: "circular extends",
+			URL:         "mem://localhost/extend/rules/rule3.yaml",
+			rule:        `Extends: ../base/cycle1.yaml`,
+			hasError:    true,
+		},
+		{
+			description: "missing base",
+			URL:         "mem://localhost/extend/rules/rule4.yaml",
+			rule:        `Extends: ../base/missing.yaml`,
+			hasError:    true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		err := fs.Upload(ctx, useCase.URL, file.DefaultFileOsMode, strings.NewReader(useCase.rule))
+		assert.Nil(t, err, useCase.description)
+		ruleset := &Ruleset{}
+		rules, err := ruleset.loadRule(ctx, fs, useCase.URL)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) || !assert.Equal(t, 1, len(rules), useCase.description) {
+			continue
+		}
+		rule := rules[0]
+		actual := map[string]interface{}{
+			"Extends":          rule.Extends,
+			"Table":            rule.Dest.Table,
+			"Dataset":          rule.Dest.Transient.Dataset,
+			"Alias":            rule.Dest.Transient.Alias,
+			"UniqueColumns":    rule.Dest.UniqueColumns,
+			"DurationInSec":    rule.Batch.Window.DurationInSec,
+			"CorruptedFileURL": rule.CorruptedFileURL,
+			"OnFailure":        len(rule.OnFailure),
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestRuleset_ReloadIfNeeded_Extends(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/extend/reload/base/default.yaml"
+	ruleURL := "mem://localhost/extend/reload/rules/rule.yaml"
+	_ = fs.Upload(ctx, baseURL, file.DefaultFileOsMode, strings.NewReader("Dest:\n  Transient:\n    Dataset: temp\n"))
+	_ = fs.Upload(ctx, ruleURL, file.DefaultFileOsMode, strings.NewReader("Extends: ../base/default.yaml\nWhen:\n  Prefix: /data/\nDest:\n  Table: db.events\n"))
+
+	ruleset := &Ruleset{RulesURL: "mem://localhost/extend/reload/rules", CheckInMs: 1}
+	err := ruleset.Init(ctx, fs, "")
+	if !assert.Nil(t, err) || !assert.Equal(t, 1, len(ruleset.Rules)) {
+		return
+	}
+	assert.Equal(t, "temp", ruleset.Rules[0].Dest.Transient.Dataset)
+
+	time.Sleep(10 * time.Millisecond)
+	_ = fs.Upload(ctx, baseURL, file.DefaultFileOsMode, strings.NewReader("Dest:\n  Transient:\n    Dataset: transient\n"))
+	changed, err := ruleset.ReloadIfNeeded(ctx, fs)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	if assert.Equal(t, 1, len(ruleset.Rules)) {
+		assert.Equal(t, "transient", ruleset.Rules[0].Dest.Transient.Dataset)
+	}
+}
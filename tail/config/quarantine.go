@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/base"
+)
+
+//Quarantine represents bad records quarantine settings, load job skips up to MaxBadRecords bad records,
+//skipped records errors are written to quarantine table while valid records are loaded to destination
+type Quarantine struct {
+	//Table quarantine table, created if needed
+	Table string `json:",omitempty"`
+	//MaxBadRecords load job max bad records, used unless dest.MaxBadRecords is set
+	MaxBadRecords int64 `json:",omitempty"`
+	//IgnoreUnknownValues load job ignore unknown values option
+	IgnoreUnknownValues bool `json:",omitempty"`
+}
+
+//Init initialises destination load options
+func (q *Quarantine) Init(dest *Destination) {
+	if dest.MaxBadRecords == 0 {
+		dest.MaxBadRecords = q.MaxBadRecords
+	}
+	if q.IgnoreUnknownValues {
+		dest.IgnoreUnknownValues = true
+	}
+}
+
+//Validate checks if quarantine is valid
+func (q Quarantine) Validate(dest *Destination) error {
+	if q.Table == "" {
+		return errors.New("quarantine.Table was empty")
+	}
+	if _, err := base.NewTableReference(q.Table); err != nil {
+		return errors.Wrapf(err, "invalid quarantine.Table: %v", q.Table)
+	}
+	if dest.MaxBadRecords == 0 && q.MaxBadRecords == 0 {
+		return errors.New("quarantine requires quarantine.MaxBadRecords or dest.MaxBadRecords")
+	}
+	return nil
+}
@@ -18,8 +18,11 @@ import (
 
 //Rule represent matching resource route
 type Rule struct {
+	Extends               string         `json:",omitempty" description:"base rule URL, rule values override deep merged base rule values"`
 	Disabled              bool           `json:",omitempty"`
+	Mode                  string         `json:",omitempty" description:"ingestion mode: load (default) or stream"`
 	Dest                  *Destination   `json:",omitempty"`
+	Dests                 []*Destination `json:",omitempty" description:"optional additional destinations populated from the same transient table"`
 	When                  matcher.Basic  `json:",omitempty"`
 	Batch                 *Batch         `json:",omitempty"`
 	OnSuccess             []*task.Action `json:",omitempty"`
@@ -31,7 +34,11 @@ type Rule struct {
 	CorruptedFileURL      string         `json:",omitempty"`
 	InvalidSchemaURL      string         `json:",omitempty"`
 	CounterURL            string         `json:",omitempty"`
+	Quarantine            *Quarantine    `json:",omitempty" description:"optional bad records quarantine, valid records are still loaded"`
 	MaxReload             *int           `json:",omitempty"`
+	MaxConcurrentLoad     int            `json:",omitempty" description:"optional max concurrent load jobs per destination table enforced by dispatcher"`
+	MaxConcurrentSQL      int            `json:",omitempty" description:"optional max concurrent query jobs per destination table enforced by dispatcher"`
+	Priority              int            `json:",omitempty" description:"optional dispatch priority, throttled higher priority jobs and batch windows are released first"`
 }
 
 //StalledDuration returns stalled duration
@@ -65,21 +72,20 @@ func (r *Rule) IsAppend() bool {
 	if r.Dest == nil {
 		return true
 	}
-	if r.Dest.Override != nil {
-		return !*r.Dest.Override
-	}
-	return r.Dest.WriteDisposition == "" || r.Dest.WriteDisposition == "WRITE_APPEND"
+	return r.Dest.IsAppend()
+}
+
+//IsStream returns true if rule ingests data with streaming inserts
+func (r *Rule) IsStream() bool {
+	return strings.ToLower(r.Mode) == shared.RuleModeStream
 }
 
 //IsDMLCopy returns true if dml append flag is true
 func (r *Rule) IsDMLCopy() bool {
-	if r.Dest == nil || r.Dest.Transient == nil {
-		return false
-	}
-	if r.Dest.Transient.CopyMethod == nil {
+	if r.Dest == nil {
 		return false
 	}
-	return strings.ToUpper(*r.Dest.Transient.CopyMethod) == shared.CopyMethodDML
+	return r.Dest.IsCopyMethodDML()
 }
 
 //DestTable returns dest table
@@ -91,6 +97,14 @@ func (r *Rule) DestTable(URL string, modTime time.Time) string {
 	return table
 }
 
+//IsMultiPath returns true if batch collects data files from various locations
+func (r *Rule) IsMultiPath() bool {
+	if r.Batch == nil {
+		return false
+	}
+	return r.Batch.MultiPath || r.Group != ""
+}
+
 //HasMatch returns true if URL matches prefix or suffix
 func (r *Rule) HasMatch(URL string) bool {
 	location := url.Path(URL)
@@ -104,6 +118,40 @@ func (r Rule) Validate() error {
 	if r.Dest == nil {
 		return fmt.Errorf("dest was empty")
 	}
+	if r.Group != "" {
+		if r.Batch == nil {
+			return fmt.Errorf("batch was empty for group: %v", r.Group)
+		}
+		if r.Dest.Transient == nil {
+			return fmt.Errorf("dest.transient was empty for group: %v", r.Group)
+		}
+		if r.Batch.UseManifest() {
+			return fmt.Errorf("batch Manifest/MaxFiles/MaxBytes are not supported for group: %v", r.Group)
+		}
+		if r.Dest.Schema.Autodetect {
+			return fmt.Errorf("dest.schema.autodetect is not supported for group: %v", r.Group)
+		}
+	}
+	if r.Priority < 0 || r.MaxConcurrentLoad < 0 || r.MaxConcurrentSQL < 0 {
+		return fmt.Errorf("Priority, MaxConcurrentLoad and MaxConcurrentSQL can not be negative")
+	}
+	switch strings.ToLower(r.Mode) {
+	case "", shared.RuleModeLoad:
+	case shared.RuleModeStream:
+		if r.Batch != nil || r.Group != "" || r.Dest.Transient != nil || r.Quarantine != nil {
+			return fmt.Errorf("mode %v does not support Batch, Group, Quarantine or Dest.Transient", r.Mode)
+		}
+	default:
+		return fmt.Errorf("invalid mode: %v, valid: %v", r.Mode, []string{shared.RuleModeLoad, shared.RuleModeStream})
+	}
+	if r.Quarantine != nil {
+		if err := r.Quarantine.Validate(r.Dest); err != nil {
+			return err
+		}
+	}
+	if err := r.validateDests(); err != nil {
+		return err
+	}
 	return r.Dest.Validate()
 }
 
@@ -113,6 +161,62 @@ func (r *Rule) Init(ctx context.Context, fs afs.Service) error {
 	if r.Dest.Pattern != "" && r.When.Filter == "" {
 		r.When.Filter = r.Dest.Pattern
 	}
+	if r.Quarantine != nil {
+		r.Quarantine.Init(r.Dest)
+	}
+	if err := r.initDests(); err != nil {
+		return err
+	}
 	err := actions.Init(ctx, fs)
 	return err
 }
+
+//initDests initialises additional destinations, destination without transient settings inherits main destination transient dataset
+func (r *Rule) initDests() error {
+	for _, dest := range r.Dests {
+		if dest == nil {
+			continue
+		}
+		if dest.Transient == nil && r.Dest.Transient != nil {
+			inherited := *r.Dest.Transient
+			inherited.CopyMethod = nil
+			inherited.Merge = nil
+			dest.Transient = &inherited
+		}
+		if err := dest.Init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//validateDests checks if additional destinations are valid
+func (r *Rule) validateDests() error {
+	if len(r.Dests) == 0 {
+		return nil
+	}
+	if r.Dest.Transient == nil {
+		return fmt.Errorf("dest.transient was empty for dests")
+	}
+	if r.Dest.Schema.Autodetect {
+		return fmt.Errorf("dest.schema.autodetect is not supported with dests")
+	}
+	for i, dest := range r.Dests {
+		if dest == nil {
+			return fmt.Errorf("dests[%v] was empty", i)
+		}
+		if dest.HasSplit() {
+			return fmt.Errorf("dests[%v].schema.split is not supported", i)
+		}
+		if dest.Schema.Autodetect {
+			return fmt.Errorf("dests[%v].schema.autodetect is not supported", i)
+		}
+		if len(dest.Assertions) > 0 {
+			return fmt.Errorf("dests[%v].assertions are not supported, use dest.assertions", i)
+		}
+		if err := dest.Validate(); err != nil {
+			return fmt.Errorf("invalid dests[%v]: %v", i, err)
+		}
+	}
+	return nil
+}
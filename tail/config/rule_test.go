@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/afs/matcher"
+	"github.com/viant/bqtail/shared"
 	"testing"
 )
 
@@ -128,3 +129,78 @@ func TestRoute_HasMatch(t *testing.T) {
 	}
 
 }
+
+func TestRule_Dests(t *testing.T) {
+	var useCases = []struct {
+		description   string
+		rule          *Rule
+		hasError      bool
+		expectDataset string
+		expectMethod  string
+		expectPrimary string
+	}{
+		{
+			description: "dests inherit transient dataset",
+			rule: &Rule{
+				Dest: &Destination{Table: "project:dataset.events", Transient: &Transient{Dataset: "temp", CopyMethod: &shared.CopyMethodQuery}},
+				Dests: []*Destination{
+					{Table: "project:dataset.events_copy"},
+				},
+			},
+			expectDataset: "temp",
+			expectMethod:  shared.CopyMethodCopy,
+			expectPrimary: shared.CopyMethodQuery,
+		},
+		{
+			description: "dests with own copy method",
+			rule: &Rule{
+				Dest: &Destination{Table: "project:dataset.events", Transient: &Transient{Dataset: "temp"}},
+				Dests: []*Destination{
+					{Table: "project:dataset.events_dml", Transient: &Transient{Dataset: "temp", CopyMethod: &shared.CopyMethodDML}},
+				},
+			},
+			expectDataset: "temp",
+			expectMethod:  shared.CopyMethodDML,
+			expectPrimary: shared.CopyMethodCopy,
+		},
+		{
+			description: "dests without transient",
+			rule: &Rule{
+				Dest: &Destination{Table: "project:dataset.events"},
+				Dests: []*Destination{
+					{Table: "project:dataset.events_copy"},
+				},
+			},
+			hasError: true,
+		},
+		{
+			description: "dests with split",
+			rule: &Rule{
+				Dest: &Destination{Table: "project:dataset.events", Transient: &Transient{Dataset: "temp"}},
+				Dests: []*Destination{
+					{Table: "project:dataset.events_copy", Schema: Schema{Split: &Split{}}},
+				},
+			},
+			hasError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		err := useCase.rule.Dest.Init()
+		assert.Nil(t, err, useCase.description)
+		err = useCase.rule.initDests()
+		assert.Nil(t, err, useCase.description)
+		err = useCase.rule.Validate()
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		dest := useCase.rule.Dests[0]
+		assert.EqualValues(t, useCase.expectDataset, dest.Transient.Dataset, useCase.description)
+		assert.EqualValues(t, useCase.expectMethod, *dest.Transient.CopyMethod, useCase.description)
+		assert.EqualValues(t, useCase.expectPrimary, *useCase.rule.Dest.Transient.CopyMethod, useCase.description)
+	}
+}
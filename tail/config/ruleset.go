@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
 	"github.com/viant/afs/url"
@@ -13,14 +14,20 @@ import (
 	"io/ioutil"
 	"log"
 	"path"
+	"strings"
 	"time"
 )
 
 //Ruleset represents route slice
 type Ruleset struct {
-	RulesURL  string
-	CheckInMs int
-	Rules     []*Rule
+	RulesURL   string
+	CheckInMs  int
+	StrictMode bool
+	//DryRun if set, rule Transform, Split and SideInputs are checked with BigQuery dry run when rule is loaded
+	DryRun bool
+	//Checker checks loaded rule
+	Checker func(ctx context.Context, rule *Rule) error `json:"-"`
+	Rules   []*Rule
 	*base.Loader
 }
 
@@ -92,6 +99,20 @@ func (r Ruleset) Match(URL string) []*Rule {
 	return matched
 }
 
+//Group returns enabled rules for supplied group
+func (r Ruleset) Group(name string) []*Rule {
+	var result = make([]*Rule, 0)
+	if name == "" {
+		return result
+	}
+	for i := range r.Rules {
+		if r.Rules[i].Group == name && !r.Rules[i].Disabled {
+			result = append(result, r.Rules[i])
+		}
+	}
+	return result
+}
+
 //MatchByTable returns the first match route
 func (r Ruleset) MatchByTable(table string) *Rule {
 	if len(r.Rules) == 0 {
@@ -174,6 +195,16 @@ func (r *Ruleset) loadRule(ctx context.Context, fs afs.Service, URL string) ([]*
 	if err != nil {
 		return nil, err
 	}
+	if err = r.verify(URL, data); err != nil {
+		return nil, err
+	}
+	data, bases, err := r.extend(ctx, fs, URL, data)
+	if r.Loader != nil {
+		r.Loader.Depend(URL, bases...)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to extend: %v", URL)
+	}
 	rules, err := loadRules(data, path.Ext(URL))
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to decode: %v", URL)
@@ -200,10 +231,69 @@ func (r *Ruleset) loadRule(ctx context.Context, fs afs.Service, URL string) ([]*
 	if err := transientRoutes.Validate(); err != nil {
 		return nil, errors.Wrapf(err, "invalid rule: %v", URL)
 	}
-
+	if r.Checker != nil {
+		for i := range rules {
+			if err := r.Checker(ctx, rules[i]); err != nil {
+				if IsInvalidRuleError(err) {
+					return nil, err
+				}
+				log.Printf("unable to check rule: %v: %v", URL, err)
+			}
+		}
+	}
 	return rules, nil
 }
 
+//Check checks already loaded rules with Checker, rules that failed the check are removed,
+//rule is kept if it could not be checked (i.e. rate limit or backend error)
+func (r *Ruleset) Check(ctx context.Context) {
+	if r.Checker == nil {
+		return
+	}
+	var temp = make([]*Rule, 0)
+	for i, rule := range r.Rules {
+		if err := r.Checker(ctx, rule); err != nil {
+			if IsInvalidRuleError(err) {
+				log.Printf("failed to check rule: %v: %v", rule.Info.URL, err)
+				continue
+			}
+			log.Printf("unable to check rule: %v: %v", rule.Info.URL, err)
+		}
+		temp = append(temp, r.Rules[i])
+	}
+	r.Rules = temp
+}
+
+//InvalidRuleError represents rule check failure caused by rule schema or SQL problems
+type InvalidRuleError struct {
+	URL      string
+	Problems []string
+}
+
+//Error returns error message
+func (e *InvalidRuleError) Error() string {
+	return fmt.Sprintf("invalid rule %v: %v", e.URL, strings.Join(e.Problems, "; "))
+}
+
+//IsInvalidRuleError returns true if rule check failed due to rule problems
+func IsInvalidRuleError(err error) bool {
+	_, ok := errors.Cause(err).(*InvalidRuleError)
+	return ok
+}
+
+//verify checks rule keys, in strict mode unknown or misplaced keys are reported as error, otherwise as warning
+func (r *Ruleset) verify(URL string, data []byte) error {
+	err := checkRules(data, path.Ext(URL))
+	if err == nil {
+		return nil
+	}
+	if r.StrictMode {
+		return errors.Wrapf(err, "invalid rule: %v", URL)
+	}
+	shared.LogF("[WARN] %v: %v\n", URL, err)
+	return nil
+}
+
 func loadRules(data []byte, ext string) ([]*Rule, error) {
 	var rules = make([]*Rule, 0)
 	switch ext {
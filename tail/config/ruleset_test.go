@@ -1,8 +1,11 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/afs/matcher"
+	"github.com/viant/bqtail/base"
 	"testing"
 )
 
@@ -171,3 +174,23 @@ func TestRoutes_HasMatch(t *testing.T) {
 		assert.Equal(t, useCase.expextTable, actual[0].Dest.Table, useCase.description)
 	}
 }
+
+func TestRuleset_Check(t *testing.T) {
+	var useCases = []struct {
+		description string
+		err         error
+		expect      int
+	}{
+		{description: "valid rule", expect: 1},
+		{description: "invalid rule is removed", err: &InvalidRuleError{URL: "mem://localhost/rules/r1.yaml", Problems: []string{"dest.transform: invalid"}}, expect: 0},
+		{description: "rule that could not be checked is kept", err: errors.New("googleapi: Error 429: rateLimitExceeded"), expect: 1},
+	}
+	for _, useCase := range useCases {
+		ruleset := &Ruleset{Rules: []*Rule{{Info: base.Info{URL: "mem://localhost/rules/r1.yaml"}}}}
+		ruleset.Checker = func(ctx context.Context, rule *Rule) error {
+			return useCase.err
+		}
+		ruleset.Check(context.Background())
+		assert.Equal(t, useCase.expect, len(ruleset.Rules), useCase.description)
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/pkg/errors"
+	"github.com/viant/bqtail/shared"
+	"strings"
 )
 
 //Split represents data split
@@ -9,6 +11,10 @@ type Split struct {
 	TimeColumn     string
 	ClusterColumns []string
 	Mapping        []*TableMapping
+	Else           string `json:",omitempty" description:"optional table for rows not matching any mapping"`
+	OnUnmatched    string `json:",omitempty" description:"rows not matching any mapping policy: drop (default) or fail"`
+	Exclusive      bool   `json:",omitempty" description:"fails split when a row matches more than one mapping"`
+	Execution      string `json:",omitempty" description:"mapping queries execution: sequential (default), parallel or script"`
 }
 
 //Validate checks if split is valid
@@ -16,14 +22,49 @@ func (s *Split) Validate() error {
 	if len(s.Mapping) == 0 {
 		return errors.Errorf("mapping were empty")
 	}
+	var whens = make(map[string]int)
 	for i := range s.Mapping {
 		if err := s.Mapping[i].Validate(); err != nil {
 			return err
 		}
+		when := strings.TrimSpace(s.Mapping[i].When)
+		if prev, ok := whens[when]; ok {
+			return errors.Errorf("mapping[%v] and mapping[%v] have the same when: %v", prev, i, when)
+		}
+		whens[when] = i
+	}
+	switch strings.ToLower(s.OnUnmatched) {
+	case "", shared.SplitUnmatchedDrop:
+	case shared.SplitUnmatchedFail:
+		if s.Else != "" {
+			return errors.Errorf("split else: %v can not be used with onUnmatched: %v", s.Else, s.OnUnmatched)
+		}
+	default:
+		return errors.Errorf("invalid split onUnmatched: %v, valid: %v", s.OnUnmatched, []string{shared.SplitUnmatchedDrop, shared.SplitUnmatchedFail})
+	}
+	switch strings.ToLower(s.Execution) {
+	case "", shared.SplitExecutionSequential, shared.SplitExecutionParallel, shared.SplitExecutionScript:
+	default:
+		return errors.Errorf("invalid split execution: %v, valid: %v", s.Execution, []string{shared.SplitExecutionSequential, shared.SplitExecutionParallel, shared.SplitExecutionScript})
 	}
 	return nil
 }
 
+//FailUnmatched returns true if split fails for rows not matching any mapping
+func (s *Split) FailUnmatched() bool {
+	return strings.ToLower(s.OnUnmatched) == shared.SplitUnmatchedFail
+}
+
+//IsParallel returns true if mapping queries run concurrently
+func (s *Split) IsParallel() bool {
+	return strings.ToLower(s.Execution) == shared.SplitExecutionParallel
+}
+
+//IsScript returns true if mapping statements run as one multi statement query
+func (s *Split) IsScript() bool {
+	return strings.ToLower(s.Execution) == shared.SplitExecutionScript
+}
+
 //TableMapping represents table mapping
 type TableMapping struct {
 	When string
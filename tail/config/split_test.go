@@ -0,0 +1,50 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplit_Validate(t *testing.T) {
+	mapping := []*TableMapping{
+		{When: "type = 1", Then: "mydataset.events_a"},
+		{When: "type = 2", Then: "mydataset.events_b"},
+	}
+	var useCases = []struct {
+		description string
+		split       *Split
+		hasError    bool
+	}{
+		{
+			description: "default split",
+			split:       &Split{Mapping: mapping},
+		},
+		{
+			description: "else with parallel execution",
+			split:       &Split{Mapping: mapping, Else: "mydataset.events_other", Execution: "parallel", Exclusive: true},
+		},
+		{
+			description: "duplicated when",
+			split:       &Split{Mapping: append(mapping, &TableMapping{When: " type = 1", Then: "mydataset.events_c"})},
+			hasError:    true,
+		},
+		{
+			description: "else with fail on unmatched",
+			split:       &Split{Mapping: mapping, Else: "mydataset.events_other", OnUnmatched: "fail"},
+			hasError:    true,
+		},
+		{
+			description: "invalid execution",
+			split:       &Split{Mapping: mapping, Execution: "async"},
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		err := useCase.split.Validate()
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/config/strict"
+	"gopkg.in/yaml.v2"
+	"reflect"
+)
+
+//RuleSchema returns rule JSON schema, definitions cover Destination, Batch and task.Action
+func RuleSchema() map[string]interface{} {
+	return strict.JSONSchema(reflect.TypeOf(Rule{}), "bqtail rule")
+}
+
+//decodeDocument decodes rule document into generic map or slice
+func decodeDocument(data []byte, ext string) (interface{}, error) {
+	var document interface{}
+	var err error
+	if ext == shared.YAMLExt {
+		if err = yaml.Unmarshal(data, &document); err != nil {
+			err = json.Unmarshal(data, &document)
+		}
+	} else {
+		err = json.Unmarshal(data, &document)
+	}
+	return document, err
+}
+
+//checkRules reports unknown or misplaced rule keys with their position
+func checkRules(data []byte, ext string) error {
+	document, err := decodeDocument(data, ext)
+	if err != nil {
+		return err
+	}
+	if _, ok := document.([]interface{}); ok {
+		return strict.Check(data, document, reflect.TypeOf([]*Rule{}))
+	}
+	return strict.Check(data, document, reflect.TypeOf(Rule{}))
+}
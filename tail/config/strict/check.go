@@ -0,0 +1,156 @@
+package strict
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//Issue represents unknown or misplaced key
+type Issue struct {
+	Path       string
+	Key        string
+	Line       int
+	Column     int
+	Expected   string
+	Suggestion string
+}
+
+//String returns issue description
+func (i *Issue) String() string {
+	var result string
+	if i.Expected != "" {
+		result = fmt.Sprintf("misplaced key: %v, expected at: %v", i.Path, i.Expected)
+	} else {
+		result = fmt.Sprintf("unknown key: %v", i.Path)
+		if i.Suggestion != "" {
+			result += fmt.Sprintf(", did you mean: %v?", i.Suggestion)
+		}
+	}
+	if i.Line > 0 {
+		result = fmt.Sprintf("line %v, column %v: %v", i.Line, i.Column, result)
+	}
+	return result
+}
+
+//Error represents strict decoding error
+type Error struct {
+	Issues []*Issue
+}
+
+//Error returns error message
+func (e *Error) Error() string {
+	var messages = make([]string, 0)
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+//Check checks if decoded document keys match target type fields, data is used to locate issue position
+func Check(data []byte, document interface{}, target reflect.Type) error {
+	checker := &checker{index: newIndex(target)}
+	checker.check(document, target, nil)
+	if len(checker.issues) == 0 {
+		return nil
+	}
+	locator := newLocator(data)
+	for _, issue := range checker.issues {
+		issue.Line, issue.Column = locator.locate(issue.Path)
+	}
+	sort.SliceStable(checker.issues, func(i, j int) bool {
+		return checker.issues[i].Line < checker.issues[j].Line
+	})
+	return &Error{Issues: checker.issues}
+}
+
+type checker struct {
+	index  *index
+	issues []*Issue
+}
+
+func (c *checker) check(value interface{}, t reflect.Type, path []string) {
+	if value == nil {
+		return
+	}
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		if !isStruct(t) {
+			return
+		}
+		aMap, ok := asMap(value)
+		if !ok {
+			return
+		}
+		structFields := fields(t)
+		for _, key := range sortedKeys(aMap) {
+			keyPath := append(append([]string{}, path...), key)
+			matched := lookupField(structFields, key)
+			if matched == nil {
+				c.issues = append(c.issues, &Issue{
+					Path:       joinPath(keyPath),
+					Key:        key,
+					Expected:   c.index.expected(key),
+					Suggestion: suggest(key, structFields),
+				})
+				continue
+			}
+			c.check(aMap[key], matched.Type, keyPath)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			c.check(value, t.Elem(), path)
+			return
+		}
+		for i, item := range items {
+			itemPath := append([]string{}, path...)
+			if len(itemPath) > 0 {
+				itemPath[len(itemPath)-1] += fmt.Sprintf("[%v]", i)
+			} else {
+				itemPath = append(itemPath, fmt.Sprintf("[%v]", i))
+			}
+			c.check(item, t.Elem(), itemPath)
+		}
+	case reflect.Map:
+		if !isStruct(derefType(t.Elem())) {
+			return
+		}
+		aMap, ok := asMap(value)
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(aMap) {
+			c.check(aMap[key], t.Elem(), append(append([]string{}, path...), key))
+		}
+	}
+}
+
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		return actual, true
+	case map[interface{}]interface{}:
+		var result = make(map[string]interface{})
+		for k, v := range actual {
+			result[fmt.Sprint(k)] = v
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+func sortedKeys(aMap map[string]interface{}) []string {
+	var result = make([]string, 0, len(aMap))
+	for k := range aMap {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func joinPath(path []string) string {
+	return strings.Replace(strings.Join(path, "."), ".[", "[", -1)
+}
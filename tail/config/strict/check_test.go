@@ -0,0 +1,175 @@
+package strict
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAction struct {
+	Action  string
+	Request map[string]interface{}
+	*testActions
+}
+
+type testActions struct {
+	OnSuccess []*testAction
+}
+
+type testWindow struct {
+	time.Duration
+	DurationInSec int
+}
+
+type testBatch struct {
+	Window    *testWindow
+	MultiPath bool
+}
+
+type testDest struct {
+	Table         string `json:"table,omitempty"`
+	UniqueColumns []string
+	Transform     map[string]string
+	Clustering    []string `json:"clusterBy,omitempty"`
+}
+
+type testRule struct {
+	Dest      *testDest
+	Batch     *testBatch
+	OnSuccess []*testAction
+	internal  string
+	Ignored   string `json:"-"`
+}
+
+func TestCheck(t *testing.T) {
+	useCases := []struct {
+		description string
+		data        string
+		isJSON      bool
+		target      interface{}
+		expect      []*Issue
+	}{
+		{
+			description: "valid yaml",
+			data: `Dest:
+  Table: db.events
+  UniqueColumns:
+    - id
+  Transform:
+    any: value
+  clusterBy:
+    - id
+Batch:
+  Window:
+    DurationInSec: 60
+OnSuccess:
+  - Action: delete
+    Request:
+      any: value
+`,
+			target: testRule{},
+		},
+		{
+			description: "misspelled yaml keys",
+			data: `Dest:
+  table: db.events
+  UniqueColumn:
+    - id
+  Tranform:
+    a: b
+`,
+			target: testRule{},
+			expect: []*Issue{
+				{Path: "Dest.UniqueColumn", Key: "UniqueColumn", Line: 3, Column: 3, Suggestion: "UniqueColumns"},
+				{Path: "Dest.Tranform", Key: "Tranform", Line: 5, Column: 3, Suggestion: "Transform"},
+			},
+		},
+		{
+			description: "misplaced yaml keys",
+			data: `Dest:
+  Table: db.events
+Batch:
+  Window:
+    MultiPath: true
+UniqueColumns:
+  - id
+`,
+			target: testRule{},
+			expect: []*Issue{
+				{Path: "Batch.Window.MultiPath", Key: "MultiPath", Line: 5, Column: 5, Expected: "Batch.MultiPath"},
+				{Path: "UniqueColumns", Key: "UniqueColumns", Line: 6, Column: 1, Expected: "Dest.UniqueColumns"},
+			},
+		},
+		{
+			description: "nested yaml actions",
+			data: `OnSuccess:
+  - Action: query
+    OnSuccess:
+      - Action: delete
+      - Acton: move
+  - Action: notify
+    Requests:
+      a: 1
+`,
+			target: testRule{},
+			expect: []*Issue{
+				{Path: "OnSuccess[0].OnSuccess[1].Acton", Key: "Acton", Line: 5, Column: 9, Suggestion: "Action"},
+				{Path: "OnSuccess[1].Requests", Key: "Requests", Line: 7, Column: 5, Suggestion: "Request"},
+			},
+		},
+		{
+			description: "unexported and skipped fields",
+			data: `internal: 1
+Ignored: 2
+`,
+			target: testRule{},
+			expect: []*Issue{
+				{Path: "internal", Key: "internal", Line: 1, Column: 1},
+				{Path: "Ignored", Key: "Ignored", Line: 2, Column: 1},
+			},
+		},
+		{
+			description: "json rules",
+			isJSON:      true,
+			data: `[
+  {"Dest": {"Table": "db.events"}},
+  {
+    "Dest": {
+      "Table": "db.events",
+      "Tranform": {"a": "b"}
+    }
+  }
+]`,
+			target: []*testRule{},
+			expect: []*Issue{
+				{Path: "[1].Dest.Tranform", Key: "Tranform", Line: 6, Column: 7, Suggestion: "Transform"},
+			},
+		},
+	}
+
+	for _, useCase := range useCases {
+		var document interface{}
+		var err error
+		if useCase.isJSON {
+			err = json.Unmarshal([]byte(useCase.data), &document)
+		} else {
+			err = yaml.Unmarshal([]byte(useCase.data), &document)
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		err = Check([]byte(useCase.data), document, reflect.TypeOf(useCase.target))
+		if len(useCase.expect) == 0 {
+			assert.Nil(t, err, useCase.description)
+			continue
+		}
+		actual, ok := err.(*Error)
+		if !assert.True(t, ok, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expect, actual.Issues, useCase.description)
+	}
+}
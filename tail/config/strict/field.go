@@ -0,0 +1,88 @@
+package strict
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//field represents a decodable struct field
+type field struct {
+	Name        string
+	Type        reflect.Type
+	Description string
+}
+
+//fields returns decodable struct fields named as with json decoder (json tag name takes precedence), embedded struct fields are promoted to the parent level
+func fields(t reflect.Type) []*field {
+	var result = make([]*field, 0)
+	var seen = make(map[string]bool)
+	appendFields(t, &result, seen)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func appendFields(t reflect.Type, result *[]*field, seen map[string]bool) {
+	var embedded = make([]reflect.Type, 0)
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if structField.Tag.Get("json") == "-" {
+			continue
+		}
+		fieldType := structField.Type
+		if structField.Anonymous {
+			if embeddedType := derefType(fieldType); isStruct(embeddedType) {
+				embedded = append(embedded, embeddedType)
+				continue
+			}
+		}
+		if structField.PkgPath != "" { //unexported
+			continue
+		}
+		name := structField.Name
+		if tagName := strings.Split(structField.Tag.Get("json"), ",")[0]; tagName != "" {
+			name = tagName
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		*result = append(*result, &field{
+			Name:        name,
+			Type:        fieldType,
+			Description: structField.Tag.Get("description"),
+		})
+	}
+	//outer fields take precedence over promoted ones, as with go field selectors
+	for _, embeddedType := range embedded {
+		appendFields(embeddedType, result, seen)
+	}
+}
+
+//lookupField returns a field matching key, matching is case insensitive as with toolbox converter and json decoder
+func lookupField(fields []*field, key string) *field {
+	for _, candidate := range fields {
+		if strings.EqualFold(candidate.Name, key) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+//isStruct returns true if type is decoded from an object with known keys
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
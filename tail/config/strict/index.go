@@ -0,0 +1,106 @@
+package strict
+
+import (
+	"reflect"
+	"strings"
+)
+
+//index represents known key paths, used to detect misplaced keys
+type index struct {
+	paths map[string]string
+}
+
+//expected returns the shortest known path for supplied key
+func (i *index) expected(key string) string {
+	return i.paths[strings.ToLower(key)]
+}
+
+func (i *index) build(t reflect.Type, path string, visited map[reflect.Type]bool) {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		i.build(t.Elem(), path+"[]", visited)
+		return
+	case reflect.Map:
+		return
+	}
+	if !isStruct(t) || visited[t] {
+		return
+	}
+	if path != "" && strings.HasPrefix(t.PkgPath(), "google.golang.org") {
+		return //API types are not authored directly in rules
+	}
+	visited[t] = true
+	defer delete(visited, t)
+	for _, candidate := range fields(t) {
+		fieldPath := candidate.Name
+		if path != "" {
+			fieldPath = path + "." + candidate.Name
+		}
+		key := strings.ToLower(candidate.Name)
+		if existing, ok := i.paths[key]; !ok || depth(fieldPath) < depth(existing) {
+			i.paths[key] = fieldPath
+		}
+		i.build(candidate.Type, fieldPath, visited)
+	}
+}
+
+func depth(path string) int {
+	return strings.Count(path, ".")
+}
+
+func newIndex(root reflect.Type) *index {
+	result := &index{paths: make(map[string]string)}
+	for root = derefType(root); root.Kind() == reflect.Slice; {
+		root = derefType(root.Elem())
+	}
+	result.build(root, "", make(map[reflect.Type]bool))
+	return result
+}
+
+//suggest returns the closest field name for a misspelled key
+func suggest(key string, candidates []*field) string {
+	var result string
+	minDistance := len(key)/2 + 1
+	if minDistance > 3 {
+		minDistance = 3
+	}
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(key), strings.ToLower(candidate.Name))
+		if distance < minDistance {
+			minDistance = distance
+			result = candidate.Name
+		}
+	}
+	return result
+}
+
+func levenshtein(source, target string) int {
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = minOf(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+func minOf(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
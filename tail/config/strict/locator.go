@@ -0,0 +1,231 @@
+package strict
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//position represents key line and column
+type position struct {
+	line   int
+	column int
+}
+
+//locator maps key path to its position in source document
+type locator struct {
+	positions map[string]*position
+}
+
+func (l *locator) locate(path string) (int, int) {
+	if pos, ok := l.positions[strings.ToLower(path)]; ok {
+		return pos.line, pos.column
+	}
+	return 0, 0
+}
+
+func (l *locator) add(path string, line, column int) {
+	key := strings.ToLower(path)
+	if _, ok := l.positions[key]; !ok {
+		l.positions[key] = &position{line: line, column: column}
+	}
+}
+
+func newLocator(data []byte) *locator {
+	result := &locator{positions: make(map[string]*position)}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		scanner := &jsonScanner{data: data, line: 1, column: 1, locator: result}
+		scanner.value("")
+		return result
+	}
+	result.scanYAML(data)
+	return result
+}
+
+var yamlKeyExpr = regexp.MustCompile(`^(?:"([^"]+)"|'([^']+)'|([^\s"'#{\[][^:#]*?))\s*:(?:\s|$)`)
+
+//yamlEntry represents key or sequence item context
+type yamlEntry struct {
+	column int
+	path   string
+	isItem bool
+	items  int
+}
+
+//scanYAML records block style key positions, indentation determines key nesting
+func (l *locator) scanYAML(data []byte) {
+	var stack = []*yamlEntry{{column: -1}}
+	blockColumn := -1
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		text := strings.TrimLeft(line, " ")
+		column := len(line) - len(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if blockColumn >= 0 {
+			if column > blockColumn {
+				continue
+			}
+			blockColumn = -1
+		}
+		if strings.HasPrefix(text, "---") || strings.HasPrefix(text, "...") {
+			continue
+		}
+		for text == "-" || strings.HasPrefix(text, "- ") {
+			for len(stack) > 1 && stack[len(stack)-1].column > column {
+				stack = stack[:len(stack)-1]
+			}
+			if top := stack[len(stack)-1]; top.isItem && top.column == column {
+				stack = stack[:len(stack)-1]
+			}
+			owner := stack[len(stack)-1]
+			stack = append(stack, &yamlEntry{column: column, path: fmt.Sprintf("%v[%v]", owner.path, owner.items), isItem: true})
+			owner.items++
+			rest := strings.TrimLeft(strings.TrimPrefix(text, "-"), " ")
+			column += len(text) - len(rest)
+			text = rest
+		}
+		match := yamlKeyExpr.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		key := match[1] + match[2] + match[3]
+		for len(stack) > 1 && stack[len(stack)-1].column >= column {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		path := key
+		if parent.path != "" {
+			path = parent.path + "." + key
+		}
+		l.add(path, i+1, column+1)
+		stack = append(stack, &yamlEntry{column: column, path: path})
+		value := strings.TrimSpace(text[len(match[0]):])
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockColumn = column
+		}
+	}
+}
+
+//jsonScanner records object key positions
+type jsonScanner struct {
+	data    []byte
+	offset  int
+	line    int
+	column  int
+	locator *locator
+}
+
+func (s *jsonScanner) peek() byte {
+	if s.offset >= len(s.data) {
+		return 0
+	}
+	return s.data[s.offset]
+}
+
+func (s *jsonScanner) next() byte {
+	result := s.peek()
+	if result == 0 {
+		return result
+	}
+	s.offset++
+	if result == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+	return result
+}
+
+func (s *jsonScanner) skipWhitespace() {
+	for {
+		switch s.peek() {
+		case ' ', '\t', '\r', '\n':
+			s.next()
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsonScanner) text() string {
+	s.next() //opening quote
+	var result = make([]byte, 0)
+	for {
+		c := s.next()
+		switch c {
+		case 0, '"':
+			return string(result)
+		case '\\':
+			result = append(result, s.next())
+			continue
+		}
+		result = append(result, c)
+	}
+}
+
+func (s *jsonScanner) value(path string) {
+	s.skipWhitespace()
+	switch s.peek() {
+	case '{':
+		s.next()
+		for {
+			s.skipWhitespace()
+			switch s.peek() {
+			case '}':
+				s.next()
+				return
+			case ',':
+				s.next()
+				continue
+			case '"':
+			default:
+				return
+			}
+			line, column := s.line, s.column
+			key := s.text()
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			s.locator.add(keyPath, line, column)
+			s.skipWhitespace()
+			if s.peek() != ':' {
+				return
+			}
+			s.next()
+			s.value(keyPath)
+		}
+	case '[':
+		s.next()
+		for i := 0; ; i++ {
+			s.skipWhitespace()
+			switch s.peek() {
+			case ']':
+				s.next()
+				return
+			case ',':
+				s.next()
+				i--
+				continue
+			case 0, '}':
+				return
+			}
+			s.value(fmt.Sprintf("%v[%v]", path, i))
+		}
+	case '"':
+		s.text()
+	default:
+		for {
+			switch s.peek() {
+			case 0, ',', '}', ']', ' ', '\t', '\r', '\n':
+				return
+			}
+			s.next()
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package strict
+
+import (
+	"path"
+	"reflect"
+)
+
+//Draft represents JSON schema draft used by generated schema
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+//JSONSchema returns JSON schema for supplied type, each struct type is published in definitions
+func JSONSchema(target reflect.Type, title string) map[string]interface{} {
+	generator := &generator{definitions: make(map[string]interface{}), names: make(map[reflect.Type]string)}
+	result := generator.schema(target)
+	result["$schema"] = Draft
+	result["title"] = title
+	result["definitions"] = generator.definitions
+	return result
+}
+
+type generator struct {
+	definitions map[string]interface{}
+	names       map[reflect.Type]string
+}
+
+func (g *generator) schema(t reflect.Type) map[string]interface{} {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + g.define(t)}
+	}
+	return map[string]interface{}{}
+}
+
+//define registers struct type definition and returns its name
+func (g *generator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, ok := g.definitions[name]; ok || name == "" {
+		name = path.Base(t.PkgPath()) + "." + t.Name()
+	}
+	g.names[t] = name
+	definition := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+	}
+	g.definitions[name] = definition
+	properties := make(map[string]interface{})
+	for _, field := range fields(t) {
+		property := g.schema(field.Type)
+		if field.Description != "" {
+			if _, isRef := property["$ref"]; !isRef {
+				property["description"] = field.Description
+			}
+		}
+		properties[field.Name] = property
+	}
+	definition["properties"] = properties
+	return name
+}
@@ -0,0 +1,30 @@
+package strict
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema(reflect.TypeOf(testRule{}), "rule")
+	assert.EqualValues(t, Draft, schema["$schema"])
+	assert.EqualValues(t, "#/definitions/testRule", schema["$ref"])
+	definitions, ok := schema["definitions"].(map[string]interface{})
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 5, len(definitions))
+	rule := definitions["testRule"].(map[string]interface{})
+	assert.EqualValues(t, false, rule["additionalProperties"])
+	properties := rule["properties"].(map[string]interface{})
+	assert.EqualValues(t, []string{"Batch", "Dest", "OnSuccess"}, sortedKeys(properties))
+	assert.EqualValues(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/definitions/testAction"}}, properties["OnSuccess"])
+
+	action := definitions["testAction"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.EqualValues(t, []string{"Action", "OnSuccess", "Request"}, sortedKeys(action))
+	assert.EqualValues(t, map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{}}, action["Request"])
+
+	window := definitions["testWindow"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.EqualValues(t, map[string]interface{}{"type": "integer"}, window["Duration"])
+}
@@ -16,7 +16,8 @@ type Transient struct {
 	Template   string
 	CopyMethod *string
 	Criteria   string `json:",omitempty" description:"optional dml copy criteria "`
-	Balancer *transient.Balancer
+	Balancer   *transient.Balancer
+	Merge      *transient.Merge `json:",omitempty" description:"optional MERGE copy method settings"`
 }
 
 //Validate checks if transient is valid
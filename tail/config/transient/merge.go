@@ -0,0 +1,34 @@
+package transient
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/shared"
+	"strings"
+)
+
+//Merge represents MERGE copy method settings
+type Merge struct {
+	//WhenMatched UPDATE (default) updates matched destination rows, IGNORE keeps them intact
+	WhenMatched string `json:",omitempty"`
+	//VersionColumn optional column, matched row is updated only if source version is greater than destination one,
+	//when deduplicating transient rows the latest version is taken
+	VersionColumn string `json:",omitempty"`
+}
+
+//Init initialises merge
+func (m *Merge) Init() {
+	m.WhenMatched = strings.ToUpper(m.WhenMatched)
+	if m.WhenMatched == "" {
+		m.WhenMatched = shared.MergeMatchedUpdate
+	}
+}
+
+//Validate checks if merge is valid
+func (m Merge) Validate() error {
+	switch m.WhenMatched {
+	case shared.MergeMatchedUpdate, shared.MergeMatchedIgnore:
+	default:
+		return errors.Errorf("invalid Transient.Merge.WhenMatched: %v, valid: %v", m.WhenMatched, []string{shared.MergeMatchedUpdate, shared.MergeMatchedIgnore})
+	}
+	return nil
+}
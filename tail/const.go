@@ -1,3 +1,5 @@
 package tail
 
 const notFoundURLFragment = "Not found: URI "
+
+const widenEventSuffix = "w"
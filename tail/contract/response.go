@@ -5,6 +5,7 @@ import (
 	"github.com/viant/bqtail/stage"
 	"github.com/viant/bqtail/tail/batch"
 	"github.com/viant/bqtail/tail/status"
+	"github.com/viant/bqtail/tail/stream"
 )
 
 //Response represents a response
@@ -19,20 +20,24 @@ type Response struct {
 	BatchRunner     bool   `json:",omitempty"`
 	BatchingEventID string `json:",omitempty"`
 	WindowURL       string `json:",omitempty"`
+	Late            bool   `json:",omitempty"`
 	TriggerURL      string
-	ScheduledURL    string         `json:",omitempty"`
-	Window          *batch.Window  `json:",omitempty"`
-	Process         *stage.Process `json:",omitempty"`
-	ListOpCount     int            `json:",omitempty"`
-	StorageRetries  map[int]int    `json:",omitempty"`
-	Retriable       bool           `json:",omitempty"`
-	RetryError      string         `json:",omitempty"`
-	RuleError       string         `json:",omitempty"`
-	LoadError       string         `json:",omitempty"`
-	RetryCount      int            `json:",omitempty"`
-	MoveError       string         `json:",omitempty"`
-	CounterError    string         `json:",omitempty"`
-	DownloadError   string         `json:",omitempty"`
+	ScheduledURL    string           `json:",omitempty"`
+	Window          *batch.Window    `json:",omitempty"`
+	Stream          *stream.Response `json:",omitempty"`
+	Process         *stage.Process   `json:",omitempty"`
+	ListOpCount     int              `json:",omitempty"`
+	StorageRetries  map[int]int      `json:",omitempty"`
+	Retriable       bool             `json:",omitempty"`
+	RetryError      string           `json:",omitempty"`
+	RuleError       string           `json:",omitempty"`
+	LoadError       string           `json:",omitempty"`
+	RetryCount      int              `json:",omitempty"`
+	MoveError       string           `json:",omitempty"`
+	CounterError    string           `json:",omitempty"`
+	Quarantined     int              `json:",omitempty"`
+	QuarantineError string           `json:",omitempty"`
+	DownloadError   string           `json:",omitempty"`
 }
 
 //NewResponse creates a new response
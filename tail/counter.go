@@ -0,0 +1,43 @@
+package tail
+
+import (
+	"context"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/tail/contract"
+	"github.com/viant/bqtail/tail/counter"
+	"google.golang.org/api/bigquery/v2"
+	"time"
+)
+
+//countLoad updates rule counters with successfully completed load job statistics, job is counted in its completion hour bucket once
+func (s *service) countLoad(ctx context.Context, rule *config.Rule, dest string, bqJob *bigquery.Job, response *contract.Response) {
+	if rule == nil || rule.CounterURL == "" || bqJob == nil {
+		return
+	}
+	if !base.IsJobDone(bqJob) || base.JobError(bqJob) != nil || bqJob.Statistics == nil || bqJob.Statistics.Load == nil {
+		return
+	}
+	completed := time.Now()
+	if bqJob.Statistics.EndTime > 0 {
+		completed = time.Unix(0, bqJob.Statistics.EndTime*int64(time.Millisecond))
+	}
+	delta := counter.NewCounter(dest, completed)
+	if bqJob.JobReference != nil {
+		delta.JobID = bqJob.JobReference.JobId
+	}
+	delta.Files = bqJob.Statistics.Load.InputFiles
+	delta.Bytes = bqJob.Statistics.Load.InputFileBytes
+	delta.Rows = bqJob.Statistics.Load.OutputRows
+	s.updateCounter(ctx, rule, delta, response)
+}
+
+//updateCounter updates rule counter, counter error does not interrupt ingestion process
+func (s *service) updateCounter(ctx context.Context, rule *config.Rule, delta *counter.Counter, response *contract.Response) {
+	if rule == nil || rule.CounterURL == "" || delta.IsEmpty() {
+		return
+	}
+	if err := s.counter.Increment(ctx, rule.CounterURL, delta); err != nil {
+		response.CounterError = err.Error()
+	}
+}
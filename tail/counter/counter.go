@@ -0,0 +1,61 @@
+package counter
+
+import (
+	"github.com/viant/bqtail/shared"
+	"time"
+)
+
+//Counter represents destination ingestion counters for an hour bucket
+type Counter struct {
+	Dest          string    `json:",omitempty"`
+	Hour          string    `json:",omitempty"`
+	Files         int64     `json:",omitempty"`
+	Bytes         int64     `json:",omitempty"`
+	Rows          int64     `json:",omitempty"`
+	Corrupted     int64     `json:",omitempty"`
+	InvalidSchema int64     `json:",omitempty"`
+	Reloads       int64     `json:",omitempty"`
+	Updated       time.Time `json:",omitempty"`
+	//JobID delta load job ID, a delta with job ID already counted in the hour bucket is ignored
+	JobID string `json:"-"`
+	//JobIDs counted load job IDs
+	JobIDs []string `json:",omitempty"`
+}
+
+//Add adds delta counters
+func (c *Counter) Add(delta *Counter) {
+	c.Files += delta.Files
+	c.Bytes += delta.Bytes
+	c.Rows += delta.Rows
+	c.Corrupted += delta.Corrupted
+	c.InvalidSchema += delta.InvalidSchema
+	c.Reloads += delta.Reloads
+}
+
+//HasJob returns true if supplied job ID has been already counted
+func (c *Counter) HasJob(jobID string) bool {
+	for _, candidate := range c.JobIDs {
+		if candidate == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+//IsEmpty returns true if all counters are zero
+func (c *Counter) IsEmpty() bool {
+	return c.Files == 0 && c.Bytes == 0 && c.Rows == 0 && c.Corrupted == 0 && c.InvalidSchema == 0 && c.Reloads == 0
+}
+
+//Time returns counter hour bucket time
+func (c *Counter) Time() (time.Time, error) {
+	return time.Parse(shared.DateLayout, c.Hour)
+}
+
+//NewCounter creates a destination counter for supplied time hour bucket
+func NewCounter(dest string, at time.Time) *Counter {
+	return &Counter{
+		Dest: dest,
+		Hour: at.UTC().Format(shared.DateLayout),
+	}
+}
@@ -0,0 +1,181 @@
+package counter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"io/ioutil"
+	"math/rand"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxUpdateAttempts = 10
+
+//Service represents ingestion counter service
+type Service interface {
+	//Increment adds delta to destination hour bucket counter
+	Increment(ctx context.Context, baseURL string, delta *Counter) error
+
+	//List returns destination counters within supplied time range, empty dest returns all destinations counters
+	List(ctx context.Context, baseURL, dest string, from, to time.Time) ([]*Counter, error)
+}
+
+type service struct {
+	fs afs.Service
+}
+
+//URL returns counter URL
+func URL(baseURL, dest, hour string) string {
+	return url.Join(baseURL, dest, hour+shared.JSONExt)
+}
+
+//Increment adds delta to destination hour bucket counter, update uses storage generation precondition to avoid lost updates,
+//delta with job ID already counted in the bucket is skipped, so that redelivered events do not count the same load twice
+func (s *service) Increment(ctx context.Context, baseURL string, delta *Counter) error {
+	if delta.Dest == "" {
+		return errors.New("counter dest was empty")
+	}
+	if delta.Hour == "" {
+		delta.Hour = time.Now().UTC().Format(shared.DateLayout)
+	}
+	counterURL := URL(baseURL, delta.Dest, delta.Hour)
+	var err error
+	for i := 0; i < maxUpdateAttempts; i++ {
+		if err = s.increment(ctx, counterURL, delta); err == nil {
+			return nil
+		}
+		if !base.IsPreConditionError(err) && !base.IsRetryError(err) {
+			return err
+		}
+		time.Sleep(time.Duration(50+rand.Intn(250*(i+1))) * time.Millisecond)
+	}
+	return errors.Wrapf(err, "failed to update counter: %v, exceeded max attempts", counterURL)
+}
+
+func (s *service) increment(ctx context.Context, URL string, delta *Counter) error {
+	counter, generation, err := s.load(ctx, URL)
+	if err != nil {
+		return err
+	}
+	if counter == nil {
+		counter = &Counter{Dest: delta.Dest, Hour: delta.Hour}
+	}
+	if delta.JobID != "" {
+		if counter.HasJob(delta.JobID) {
+			return nil
+		}
+		counter.JobIDs = append(counter.JobIDs, delta.JobID)
+	}
+	counter.Add(delta)
+	counter.Updated = time.Now().UTC()
+	data, err := json.Marshal(counter)
+	if err != nil {
+		return err
+	}
+	var options = make([]storage.Option, 0)
+	if generation != nil {
+		options = append(options, generation)
+	}
+	return s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data), options...)
+}
+
+//load returns counter with generation precondition or nil counter if it does not exists yet
+func (s *service) load(ctx context.Context, URL string) (*Counter, *option.Generation, error) {
+	if ok, _ := s.fs.Exists(ctx, URL, option.NewObjectKind(true)); !ok {
+		return nil, option.NewGeneration(true, 0), nil
+	}
+	object, err := s.fs.Object(ctx, URL, option.NewObjectKind(true))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get counter: %v", URL)
+	}
+	counter, err := s.read(ctx, object)
+	if err != nil {
+		return nil, nil, err
+	}
+	return counter, base.GenerationMatch(object), nil
+}
+
+func (s *service) read(ctx context.Context, object storage.Object) (*Counter, error) {
+	reader, err := s.fs.Download(ctx, object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download counter: %v", object.URL())
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read counter: %v", object.URL())
+	}
+	counter := &Counter{}
+	if err = json.Unmarshal(data, counter); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode counter: %v", object.URL())
+	}
+	return counter, nil
+}
+
+//List returns destination counters within supplied time range
+func (s *service) List(ctx context.Context, baseURL, dest string, from, to time.Time) ([]*Counter, error) {
+	var destURLs = make([]string, 0)
+	if dest != "" {
+		destURLs = append(destURLs, url.Join(baseURL, dest))
+	} else {
+		objects, err := s.fs.List(ctx, baseURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list counters: %v", baseURL)
+		}
+		for _, object := range objects {
+			if object.IsDir() && strings.Trim(object.URL(), "/") != strings.Trim(baseURL, "/") {
+				destURLs = append(destURLs, object.URL())
+			}
+		}
+	}
+	var result = make([]*Counter, 0)
+	for _, destURL := range destURLs {
+		if ok, _ := s.fs.Exists(ctx, destURL); !ok {
+			continue
+		}
+		objects, err := s.fs.List(ctx, destURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list counters: %v", destURL)
+		}
+		for _, object := range objects {
+			if object.IsDir() || path.Ext(object.Name()) != shared.JSONExt {
+				continue
+			}
+			hour := strings.Replace(object.Name(), shared.JSONExt, "", 1)
+			at, err := time.Parse(shared.DateLayout, hour)
+			if err != nil || at.Before(from.UTC().Truncate(time.Hour)) || at.After(to.UTC()) {
+				continue
+			}
+			counter, err := s.read(ctx, object)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, counter)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Dest == result[j].Dest {
+			return result[i].Hour < result[j].Hour
+		}
+		return result[i].Dest < result[j].Dest
+	})
+	return result, nil
+}
+
+//New creates a counter service
+func New(fs afs.Service) Service {
+	return &service{fs: fs}
+}
@@ -0,0 +1,90 @@
+package counter
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"testing"
+	"time"
+)
+
+func TestService_Increment(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2020, 4, 20, 10, 15, 0, 0, time.UTC)
+
+	useCases := []struct {
+		description string
+		baseURL     string
+		deltas      []*Counter
+		dest        string
+		from        time.Time
+		to          time.Time
+		expect      []*Counter
+	}{
+		{
+			description: "single destination increments",
+			baseURL:     "mem://localhost/counter/case001",
+			deltas: []*Counter{
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 2, Bytes: 100, Rows: 10},
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 1, Bytes: 50, Rows: 5, Reloads: 1, Corrupted: 1},
+			},
+			dest: "db.events",
+			from: at.Add(-time.Hour),
+			to:   at,
+			expect: []*Counter{
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 3, Bytes: 150, Rows: 15, Reloads: 1, Corrupted: 1},
+			},
+		},
+		{
+			description: "all destinations with time range",
+			baseURL:     "mem://localhost/counter/case002",
+			deltas: []*Counter{
+				{Dest: "db.events", Hour: "2020-04-20_09", Files: 1, Rows: 1},
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 2, Rows: 2},
+				{Dest: "db.clicks", Hour: "2020-04-20_10", Files: 3, Rows: 3, InvalidSchema: 1},
+				{Dest: "db.clicks", Hour: "2020-04-19_10", Files: 4, Rows: 4},
+			},
+			from: at.Add(-2 * time.Hour),
+			to:   at,
+			expect: []*Counter{
+				{Dest: "db.clicks", Hour: "2020-04-20_10", Files: 3, Rows: 3, InvalidSchema: 1},
+				{Dest: "db.events", Hour: "2020-04-20_09", Files: 1, Rows: 1},
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 2, Rows: 2},
+			},
+		},
+		{
+			description: "redelivered job counted once",
+			baseURL:     "mem://localhost/counter/case003",
+			deltas: []*Counter{
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 2, Rows: 20, JobID: "job1"},
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 2, Rows: 20, JobID: "job1"},
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 1, Rows: 5, JobID: "job2"},
+			},
+			dest: "db.events",
+			from: at.Add(-time.Hour),
+			to:   at,
+			expect: []*Counter{
+				{Dest: "db.events", Hour: "2020-04-20_10", Files: 3, Rows: 25, JobIDs: []string{"job1", "job2"}},
+			},
+		},
+	}
+
+	for _, useCase := range useCases {
+		srv := New(afs.New())
+		for _, delta := range useCase.deltas {
+			err := srv.Increment(ctx, useCase.baseURL, delta)
+			assert.Nil(t, err, useCase.description)
+		}
+		actual, err := srv.List(ctx, useCase.baseURL, useCase.dest, useCase.from, useCase.to)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		if !assert.Equal(t, len(useCase.expect), len(actual), useCase.description) {
+			continue
+		}
+		for i, expect := range useCase.expect {
+			actual[i].Updated = time.Time{}
+			assert.EqualValues(t, expect, actual[i], useCase.description)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package plan
+
+import (
+	"github.com/viant/bqtail/task"
+)
+
+const (
+	//StatusPlanned represents planned data file ingestion
+	StatusPlanned = "planned"
+	//StatusBatched represents data file added to other data file batch window
+	StatusBatched = "batched"
+	//StatusNoMatch represents data file without matching rule
+	StatusNoMatch = "noMatch"
+	//StatusDisabled represents data file matching disabled rule
+	StatusDisabled = "disabled"
+	//StatusError represents data file that can not be planned
+	StatusError = "error"
+)
+
+//Plan represents a data file execution plan
+type Plan struct {
+	URL            string
+	Status         string
+	RuleURL        string       `json:",omitempty"`
+	EventID        string       `json:",omitempty"`
+	DestTable      string       `json:",omitempty"`
+	Partition      string       `json:",omitempty"`
+	WindowURL      string       `json:",omitempty"`
+	OwnerEventID   string       `json:",omitempty"`
+	TransientTable string       `json:",omitempty"`
+	URIs           []string     `json:",omitempty"`
+	Steps          []*Step      `json:",omitempty"`
+	Action         *task.Action `json:",omitempty"`
+	Warning        string       `json:",omitempty"`
+	Error          string       `json:",omitempty"`
+}
+
+//Step represents planned action step
+type Step struct {
+	Action string
+	JobID  string `json:",omitempty"`
+	SQL    string `json:",omitempty"`
+}
+
+//steps returns action tree steps in depth first order
+func steps(action *task.Action, result *[]*Step) {
+	if action == nil {
+		return
+	}
+	step := &Step{Action: action.Action}
+	if action.Meta != nil {
+		step.JobID = action.Meta.GetJobID()
+	}
+	if SQL, ok := action.Request["SQL"].(string); ok {
+		step.SQL = SQL
+	}
+	*result = append(*result, step)
+	if action.Actions == nil {
+		return
+	}
+	for _, child := range action.OnSuccess {
+		steps(child, result)
+	}
+	for _, child := range action.OnFailure {
+		steps(child, result)
+	}
+}
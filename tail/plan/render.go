@@ -0,0 +1,156 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/viant/bqtail/task"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+const (
+	onSuccessLabel = "onSuccess"
+	onFailureLabel = "onFailure"
+)
+
+//Text returns action tree as indented text
+func Text(action *task.Action) string {
+	buffer := new(bytes.Buffer)
+	writeText(buffer, action, "")
+	return buffer.String()
+}
+
+func writeText(buffer *bytes.Buffer, action *task.Action, indent string) {
+	buffer.WriteString(indent + "- " + describe(action, " "))
+	buffer.WriteString("\n")
+	if action.Actions == nil {
+		return
+	}
+	for _, branch := range []struct {
+		label   string
+		actions []*task.Action
+	}{
+		{onSuccessLabel, action.OnSuccess},
+		{onFailureLabel, action.OnFailure},
+	} {
+		if len(branch.actions) == 0 {
+			continue
+		}
+		buffer.WriteString(indent + "  " + branch.label + ":\n")
+		for _, child := range branch.actions {
+			writeText(buffer, child, indent+"    ")
+		}
+	}
+}
+
+//Graphviz returns action tree as graphviz dot digraph
+func Graphviz(action *task.Action) string {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("digraph plan {\n  node [shape=box];\n")
+	walk(action, func(ID string, node *task.Action) {
+		buffer.WriteString(fmt.Sprintf("  %v [label=%q];\n", ID, describe(node, "\n")))
+	}, func(parentID, childID, label string) {
+		style := ""
+		if label == onFailureLabel {
+			style = ", style=dashed"
+		}
+		buffer.WriteString(fmt.Sprintf("  %v -> %v [label=%q%v];\n", parentID, childID, label, style))
+	})
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+//Mermaid returns action tree as mermaid flowchart
+func Mermaid(action *task.Action) string {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("graph TD\n")
+	walk(action, func(ID string, node *task.Action) {
+		label := strings.Replace(describe(node, "<br/>"), `"`, "#quot;", -1)
+		buffer.WriteString(fmt.Sprintf("  %v[\"%v\"]\n", ID, label))
+	}, func(parentID, childID, label string) {
+		arrow := "-->"
+		if label == onFailureLabel {
+			arrow = "-.->"
+		}
+		buffer.WriteString(fmt.Sprintf("  %v %v|%v| %v\n", parentID, arrow, label, childID))
+	})
+	return buffer.String()
+}
+
+//walk visits action tree nodes and edges, node ID reflects visit order
+func walk(action *task.Action, onNode func(ID string, action *task.Action), onEdge func(parentID, childID, label string)) {
+	counter := 0
+	var visit func(action *task.Action) string
+	visit = func(action *task.Action) string {
+		ID := fmt.Sprintf("n%v", counter)
+		counter++
+		onNode(ID, action)
+		if action.Actions == nil {
+			return ID
+		}
+		for _, child := range action.OnSuccess {
+			onEdge(ID, visit(child), onSuccessLabel)
+		}
+		for _, child := range action.OnFailure {
+			onEdge(ID, visit(child), onFailureLabel)
+		}
+		return ID
+	}
+	if action != nil {
+		visit(action)
+	}
+}
+
+//describe returns action name with job ID and its main request target
+func describe(action *task.Action, separator string) string {
+	var result = []string{action.Action}
+	if action.When != "" {
+		result = append(result, "when: "+action.When)
+	}
+	if action.Meta != nil {
+		result = append(result, "job: "+action.Meta.GetJobID())
+	}
+	if target := target(action.Request); target != "" {
+		result = append(result, target)
+	}
+	return strings.Join(result, separator)
+}
+
+func target(request map[string]interface{}) string {
+	if len(request) == 0 {
+		return ""
+	}
+	if ref, ok := request["DestinationTable"]; ok && toolbox.IsMap(ref) {
+		aMap := toolbox.AsMap(ref)
+		table := fmt.Sprintf("%v.%v", aMap["DatasetId"], aMap["TableId"])
+		if projectID := value(aMap, "ProjectId"); projectID != "" {
+			table = projectID + ":" + table
+		}
+		return "dest: " + table
+	}
+	source := value(request, "Source", "SourceURL")
+	dest := value(request, "Dest", "DestURL")
+	switch {
+	case source != "" && dest != "":
+		return "source: " + source + ", dest: " + dest
+	case dest != "":
+		return "dest: " + dest
+	}
+	if table := value(request, "Table"); table != "" {
+		return "table: " + table
+	}
+	return ""
+}
+
+//value returns first non empty request value for supplied keys
+func value(request map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if request[key] == nil {
+			continue
+		}
+		if result := toolbox.AsString(request[key]); result != "" {
+			return result
+		}
+	}
+	return ""
+}
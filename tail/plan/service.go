@@ -0,0 +1,190 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/stage/load"
+	"github.com/viant/bqtail/tail"
+	"github.com/viant/bqtail/tail/batch"
+	"github.com/viant/bqtail/tail/config"
+	"strings"
+	"time"
+)
+
+//Service represents an offline execution plan service
+type Service interface {
+	//Plan returns execution plan for each data file URL
+	Plan(ctx context.Context, URLs ...string) ([]*Plan, error)
+}
+
+type service struct {
+	config *tail.Config
+	fs     afs.Service
+	bq     bq.Service
+	batch  batch.Service
+}
+
+type windowPlan struct {
+	plan   *Plan
+	rule   *config.Rule
+	window *batch.Window
+}
+
+//Plan returns execution plan for each data file URL, batched data files are planned with their window owner
+func (s *service) Plan(ctx context.Context, URLs ...string) ([]*Plan, error) {
+	var result = make([]*Plan, 0)
+	var windows = make([]*windowPlan, 0)
+	var windowsByURL = make(map[string]*windowPlan)
+	eventID := time.Now().UnixNano() / int64(time.Millisecond)
+	for i, URL := range URLs {
+		plan := &Plan{URL: URL, EventID: fmt.Sprintf("%v", eventID+int64(i))}
+		result = append(result, plan)
+		rule, err := s.matchRule(plan)
+		if rule == nil {
+			if err != nil {
+				plan.Status, plan.Error = StatusError, err.Error()
+			}
+			continue
+		}
+		process, err := s.newProcess(plan, rule)
+		if err != nil {
+			plan.Status, plan.Error = StatusError, err.Error()
+			continue
+		}
+		if rule.Batch == nil {
+			s.build(ctx, plan, rule, process, nil)
+			continue
+		}
+		info, err := s.batch.TryAcquireWindow(ctx, process, rule)
+		if err != nil {
+			plan.Status, plan.Error = StatusError, errors.Wrapf(err, "failed to acquire batch window").Error()
+			continue
+		}
+		if info.Window == nil {
+			plan.Status = StatusBatched
+			plan.WindowURL = info.WindowURL
+			plan.OwnerEventID = info.OwnerEventID
+			if owner, ok := windowsByURL[info.WindowURL]; ok {
+				plan.OwnerEventID = owner.plan.EventID
+				owner.window.URIs = append(owner.window.URIs, URL)
+			}
+			continue
+		}
+		plan.WindowURL = info.Window.URL
+		info.Window.URIs = []string{URL}
+		owner := &windowPlan{plan: plan, rule: rule, window: info.Window}
+		windows = append(windows, owner)
+		windowsByURL[info.Window.URL] = owner
+	}
+	for _, owner := range windows {
+		s.build(ctx, owner.plan, owner.rule, owner.window.Process, owner.window)
+	}
+	return result, nil
+}
+
+func (s *service) matchRule(plan *Plan) (*config.Rule, error) {
+	matched := s.config.Match(plan.URL)
+	switch len(matched) {
+	case 0:
+		plan.Status = StatusNoMatch
+		return nil, nil
+	case 1:
+	default:
+		var URLs = make([]string, 0)
+		for _, rule := range matched {
+			URLs = append(URLs, rule.Info.URL)
+		}
+		return nil, errors.Errorf("multi rule match currently not supported: %v", strings.Join(URLs, ","))
+	}
+	rule := matched[0]
+	plan.RuleURL = rule.Info.URL
+	if rule.Disabled {
+		plan.Status = StatusDisabled
+		return nil, nil
+	}
+	return rule, nil
+}
+
+func (s *service) newProcess(plan *Plan, rule *config.Rule) (*stage.Process, error) {
+	result := stage.NewProcess(plan.EventID, stage.NewSource(plan.URL, time.Now().UTC()), rule.Info.URL, rule.Async)
+	var err error
+	if result.DestTable, err = rule.Dest.ExpandTable(rule.Dest.Table, result.Source); err != nil {
+		return nil, errors.Wrapf(err, "failed to expand table :%v", rule.Dest.Table)
+	}
+	result.ProcessURL = s.config.BuildLoadURL(result)
+	result.DoneProcessURL = s.config.DoneLoadURL(result)
+	result.FailedURL = url.Join(s.config.JournalURL, "failed")
+	result.ProjectID = s.config.ProjectID
+	if rule.Dest.Transient != nil {
+		result.ProjectID = rule.Dest.Transient.JobProjectID(nil)
+	}
+	if result.Params, err = rule.Dest.Params(result.Source.URL); err != nil {
+		return nil, err
+	}
+	plan.DestTable = result.DestTable
+	if destRef, err := rule.Dest.CustomTableReference(result.DestTable, result.Source); err == nil {
+		plan.Partition = base.TablePartition(destRef.TableId)
+	}
+	return result, nil
+}
+
+//build builds load job with faker BigQuery service and expands its actions tree
+func (s *service) build(ctx context.Context, plan *Plan, rule *config.Rule, process *stage.Process, window *batch.Window) {
+	plan.Status = StatusPlanned
+	job, err := load.NewJob(rule, process, window)
+	if err == nil {
+		err = job.Init(ctx, s.bq)
+	}
+	if err != nil {
+		plan.Status, plan.Error = StatusError, err.Error()
+		return
+	}
+	plan.URIs = job.Load.SourceUris
+	plan.TransientTable = strings.Trim(job.TempTable, "`")
+	_, plan.Action = job.NewLoadRequest()
+	plan.Steps = make([]*Step, 0)
+	steps(plan.Action, &plan.Steps)
+	if plan.Warning = schemaWarning(rule, job, plan.Steps); plan.Warning != "" {
+		for _, step := range plan.Steps {
+			step.SQL = ""
+		}
+	}
+}
+
+//schemaWarning returns warning if SQL was built without table schema (not supplied to faker), such SQL has no columns and is not valid
+func schemaWarning(rule *config.Rule, job *load.Job, steps []*Step) string {
+	if rule.Dest.Schema.Autodetect || (job.Load.Schema != nil && len(job.Load.Schema.Fields) > 0) {
+		return ""
+	}
+	hasSQL := false
+	for _, step := range steps {
+		hasSQL = hasSQL || step.SQL != ""
+	}
+	if !hasSQL {
+		return ""
+	}
+	var tables = []string{job.DestTable}
+	if rule.Dest.Schema.Template != "" {
+		tables = append(tables, rule.Dest.Schema.Template)
+	}
+	if rule.Dest.Transient != nil && rule.Dest.Transient.Template != "" {
+		tables = append(tables, rule.Dest.Transient.Template)
+	}
+	return fmt.Sprintf("missing table schema: %v, supply it with tables option to see generated SQL", strings.Join(tables, ", "))
+}
+
+//New creates an offline plan service, config is expected to use in memory storage URLs
+func New(config *tail.Config, fs afs.Service, bqService bq.Service) Service {
+	return &service{
+		config: config,
+		fs:     fs,
+		bq:     bqService,
+		batch:  batch.New(config.TaskURL, config.LateArrivalHorizon(), fs),
+	}
+}
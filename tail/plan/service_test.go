@@ -0,0 +1,155 @@
+package plan
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/tail"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+	"testing"
+)
+
+func TestService_Plan(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/plan"
+	_ = fs.Upload(ctx, baseURL+"/rules/batch.yaml", file.DefaultFileOsMode, strings.NewReader(`When:
+  Prefix: /data/
+  Suffix: .json
+Dest:
+  Table: mydataset.events
+  Transient:
+    Dataset: temp
+  UniqueColumns:
+    - id
+Batch:
+  Window:
+    DurationInSec: 60
+OnSuccess:
+  - Action: delete
+`))
+	_ = fs.Upload(ctx, baseURL+"/rules/disabled.yaml", file.DefaultFileOsMode, strings.NewReader(`When:
+  Prefix: /disabled/
+Disabled: true
+Dest:
+  Table: mydataset.disabled
+`))
+	cfg := &tail.Config{
+		Config: base.Config{
+			ProjectID:     "myproject",
+			JournalURL:    baseURL + "/journal",
+			AsyncTaskURL:  baseURL + "/tasks",
+			SyncTaskURL:   baseURL + "/tasks",
+			ErrorURL:      baseURL + "/errors",
+			BqJobInfoPath: "/bqtail/",
+		},
+		Ruleset: config.Ruleset{RulesURL: baseURL + "/rules"},
+	}
+	if !assert.Nil(t, cfg.Init(ctx, fs)) {
+		return
+	}
+	tables := map[string]*bigquery.Table{
+		"mydataset.events": {
+			Schema: &bigquery.TableSchema{
+				Fields: []*bigquery.TableFieldSchema{
+					{Name: "id", Type: "INTEGER"},
+					{Name: "name", Type: "STRING"},
+				},
+			},
+		},
+	}
+	srv := New(cfg, fs, bq.NewFaker(tables, &bigquery.Table{Schema: &bigquery.TableSchema{}}))
+	plans, err := srv.Plan(ctx,
+		"gs://bucket/data/2020/a.json",
+		"gs://bucket/data/2020/b.json",
+		"gs://bucket/disabled/c.json",
+		"gs://bucket/other/d.csv",
+	)
+	if !assert.Nil(t, err) || !assert.Equal(t, 4, len(plans)) {
+		return
+	}
+
+	owner := plans[0]
+	assert.Equal(t, StatusPlanned, owner.Status, owner.Error)
+	assert.Equal(t, baseURL+"/rules/batch.yaml", owner.RuleURL)
+	assert.Equal(t, "mydataset.events", owner.DestTable)
+	assert.True(t, owner.WindowURL != "")
+	assert.Equal(t, []string{"gs://bucket/data/2020/a.json", "gs://bucket/data/2020/b.json"}, owner.URIs)
+	assert.True(t, strings.HasPrefix(owner.TransientTable, "temp.events_"), owner.TransientTable)
+	var jobs = make([]string, 0)
+	var SQL string
+	for _, step := range owner.Steps {
+		if step.JobID != "" {
+			jobs = append(jobs, step.Action)
+		}
+		if step.SQL != "" {
+			SQL = step.SQL
+		}
+	}
+	assert.Equal(t, []string{"load", "query", "drop"}, jobs)
+	assert.Contains(t, SQL, "GROUP BY 1")
+	assert.Contains(t, SQL, owner.TransientTable)
+
+	batched := plans[1]
+	assert.Equal(t, StatusBatched, batched.Status)
+	assert.Equal(t, owner.WindowURL, batched.WindowURL)
+	assert.Equal(t, owner.EventID, batched.OwnerEventID)
+	assert.Nil(t, batched.Action)
+
+	assert.Equal(t, StatusDisabled, plans[2].Status)
+	assert.Equal(t, StatusNoMatch, plans[3].Status)
+
+	text := Text(owner.Action)
+	assert.Contains(t, text, "- load job: ")
+	assert.Contains(t, text, "onSuccess:")
+	dot := Graphviz(owner.Action)
+	assert.Contains(t, dot, "n0 -> n1 [label=\"onSuccess\"];")
+	mermaid := Mermaid(owner.Action)
+	assert.Contains(t, mermaid, "n0 -->|onSuccess| n1")
+}
+
+func TestService_Plan_MissingSchema(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/plan_schema"
+	_ = fs.Upload(ctx, baseURL+"/rules/transient.yaml", file.DefaultFileOsMode, strings.NewReader(`When:
+  Prefix: /data/
+  Suffix: .json
+Dest:
+  Table: mydataset.events
+  Transient:
+    Dataset: temp
+  UniqueColumns:
+    - id
+`))
+	cfg := &tail.Config{
+		Config: base.Config{
+			ProjectID:     "myproject",
+			JournalURL:    baseURL + "/journal",
+			AsyncTaskURL:  baseURL + "/tasks",
+			SyncTaskURL:   baseURL + "/tasks",
+			ErrorURL:      baseURL + "/errors",
+			BqJobInfoPath: "/bqtail/",
+		},
+		Ruleset: config.Ruleset{RulesURL: baseURL + "/rules"},
+	}
+	if !assert.Nil(t, cfg.Init(ctx, fs)) {
+		return
+	}
+	srv := New(cfg, fs, bq.NewFaker(nil, &bigquery.Table{Schema: &bigquery.TableSchema{}}))
+	plans, err := srv.Plan(ctx, "gs://bucket/data/2020/a.json")
+	if !assert.Nil(t, err) || !assert.Equal(t, 1, len(plans)) {
+		return
+	}
+	aPlan := plans[0]
+	assert.Equal(t, StatusPlanned, aPlan.Status, aPlan.Error)
+	assert.Contains(t, aPlan.Warning, "missing table schema: mydataset.events")
+	for _, step := range aPlan.Steps {
+		assert.Equal(t, "", step.SQL, step.Action)
+	}
+}
@@ -0,0 +1,82 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/stage/activity"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/tail/contract"
+	"github.com/viant/bqtail/tail/status"
+	"github.com/viant/bqtail/task"
+	"google.golang.org/api/bigquery/v2"
+	"time"
+)
+
+const quarantineEventSuffix = "q"
+
+//quarantine writes records skipped by successful load job into rule quarantine table, quarantine error does not interrupt ingestion process
+func (s *service) quarantine(ctx context.Context, rule *config.Rule, process *stage.Process, bqJob *bigquery.Job, response *contract.Response) {
+	if rule == nil || rule.Quarantine == nil || process == nil {
+		return
+	}
+	records := status.BadRecords(bqJob)
+	if len(records) == 0 {
+		return
+	}
+	response.Quarantined += len(records)
+	if err := s.loadBadRecords(ctx, rule.Quarantine, process, records); err != nil {
+		response.QuarantineError = err.Error()
+	}
+}
+
+//loadBadRecords uploads bad records into journal quarantine location and loads them into quarantine table
+func (s *service) loadBadRecords(ctx context.Context, quarantine *config.Quarantine, process *stage.Process, records []*status.BadRecord) error {
+	created := time.Now().UTC().Format("2006-01-02 15:04:05.000000")
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	for _, record := range records {
+		record.DestTable = process.DestTable
+		record.EventID = process.EventID
+		record.Created = created
+		if err := encoder.Encode(record); err != nil {
+			return errors.Wrapf(err, "failed to encode bad record: %v", record.SourceURI)
+		}
+	}
+	URL := url.Join(s.config.JournalURL, shared.QuarantineLocation, process.DestTable, process.EventID+shared.JSONExt)
+	if err := s.fs.Upload(ctx, URL, file.DefaultFileOsMode, buffer); err != nil {
+		return errors.Wrapf(err, "failed to upload bad records: %v", URL)
+	}
+	tableRef, err := base.NewTableReference(quarantine.Table)
+	if err != nil {
+		return err
+	}
+	loadRequest := &bq.LoadRequest{
+		Append: true,
+		JobConfigurationLoad: &bigquery.JobConfigurationLoad{
+			SourceUris:       []string{URL},
+			DestinationTable: tableRef,
+			Schema:           status.BadRecordSchema(),
+			SourceFormat:     "NEWLINE_DELIMITED_JSON",
+		},
+	}
+	quarantineProcess := *process
+	quarantineProcess.EventID += quarantineEventSuffix
+	quarantineProcess.Async = true
+	action := &task.Action{
+		Action:  shared.ActionLoad,
+		Meta:    activity.New(&quarantineProcess, shared.ActionLoad, shared.StepModeNop, 1),
+		Actions: &task.Actions{},
+	}
+	if _, err = s.bq.Load(ctx, loadRequest, action); err != nil {
+		return errors.Wrapf(err, "failed to load bad records into %v", quarantine.Table)
+	}
+	return nil
+}
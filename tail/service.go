@@ -18,6 +18,7 @@ import (
 	"github.com/viant/bqtail/base/job"
 	"github.com/viant/bqtail/schema"
 	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/service/group"
 	"github.com/viant/bqtail/service/http"
 	"github.com/viant/bqtail/service/pubsub"
 	"github.com/viant/bqtail/service/secret"
@@ -28,9 +29,13 @@ import (
 	"github.com/viant/bqtail/stage/activity"
 	"github.com/viant/bqtail/stage/load"
 	"github.com/viant/bqtail/tail/batch"
+	"github.com/viant/bqtail/tail/check"
 	"github.com/viant/bqtail/tail/config"
 	"github.com/viant/bqtail/tail/contract"
+	"github.com/viant/bqtail/tail/counter"
+	"github.com/viant/bqtail/tail/sql"
 	"github.com/viant/bqtail/tail/status"
+	"github.com/viant/bqtail/tail/stream"
 	"github.com/viant/bqtail/task"
 	"github.com/viant/toolbox"
 	"google.golang.org/api/bigquery/v2"
@@ -48,11 +53,14 @@ type Service interface {
 
 type service struct {
 	task.Registry
-	bq     bq.Service
-	batch  batch.Service
-	fs     afs.Service
-	cfs    afs.Service
-	config *Config
+	bq      bq.Service
+	group   group.Service
+	batch   batch.Service
+	counter counter.Service
+	stream  stream.Service
+	fs      afs.Service
+	cfs     afs.Service
+	config  *Config
 }
 
 func (s *service) Init(ctx context.Context) error {
@@ -80,10 +88,18 @@ func (s *service) Init(ctx context.Context) error {
 		return err
 	}
 	s.bq = bq.New(bqService, s.Registry, s.config.ProjectID, s.fs, s.config.Config)
-	s.batch = batch.New(s.config.TaskURL, s.fs)
+	s.batch = batch.New(s.config.TaskURL, s.config.LateArrivalHorizon(), s.fs)
+	s.counter = counter.New(s.fs)
+	s.stream = stream.New(s.bq, s.fs)
+	if s.config.DryRun {
+		s.config.Checker = check.New(s.bq).Check
+		s.config.Check(ctx)
+	}
 	bq.InitRegistry(s.Registry, s.bq)
 	http.InitRegistry(s.Registry, http.New())
 	storage.InitRegistry(s.Registry, storage.New(s.fs))
+	s.group = group.New(s.Registry, s.fs)
+	group.InitRegistry(s.Registry, s.group)
 	return err
 }
 
@@ -129,6 +145,9 @@ func (s *service) tail(ctx context.Context, request *contract.Request, response
 	if err != nil {
 		return err
 	}
+	if rule.IsStream() {
+		return s.tailStream(ctx, process, rule, response)
+	}
 	var job *load.Job
 	if rule.Batch != nil {
 		job, err = s.tailInBatch(ctx, process, rule, response)
@@ -272,6 +291,10 @@ func (s *service) getCounterAndIncrease(ctx context.Context, URL string) (int, e
 
 func (s *service) newProcess(ctx context.Context, source astorage.Object, rule *config.Rule, request *contract.Request, response *contract.Response) (*stage.Process, error) {
 	result := stage.NewProcess(request.EventID, stage.NewSource(source.URL(), source.ModTime()), rule.Info.URL, rule.Async)
+	result.Source.Size = source.Size()
+	result.MaxConcurrentLoad = rule.MaxConcurrentLoad
+	result.MaxConcurrentSQL = rule.MaxConcurrentSQL
+	result.Priority = rule.Priority
 	var err error
 	if result.DestTable, err = rule.Dest.ExpandTable(rule.Dest.Table, result.Source); err != nil {
 		return nil, errors.Wrapf(err, "failed to expand table :%v", rule.Dest.Table)
@@ -308,6 +331,10 @@ func (s *service) submitJob(ctx context.Context, job *load.Job, response *contra
 		if err == nil {
 			err = base.JobError(bqJob)
 		}
+		if err == nil {
+			s.countLoad(ctx, job.Rule, job.DestTable, bqJob, response)
+			s.quarantine(ctx, job.Rule, job.Process, bqJob, response)
+		}
 	}
 	job.BqJob = bqJob
 	return job, err
@@ -373,6 +400,12 @@ func (s *service) tailInBatch(ctx context.Context, process *stage.Process, rule
 		response.BatchingEventID = batchWindow.OwnerEventID
 		response.WindowURL = batchWindow.WindowURL
 	}
+	if batchWindow.Late {
+		response.Late = true
+		if err := s.journalLate(ctx, process); err != nil {
+			response.UploadError = err.Error()
+		}
+	}
 	if batchWindow.Window == nil {
 		return nil, nil
 	}
@@ -383,6 +416,19 @@ func (s *service) tailInBatch(ctx context.Context, process *stage.Process, rule
 	return s.runInBatch(ctx, rule, batchWindow.Window, response)
 }
 
+//journalLate records data file that arrived after its batch window had been processed, monitoring reports late arrivals per destination
+func (s *service) journalLate(ctx context.Context, process *stage.Process) error {
+	URL := url.Join(s.config.JournalURL, shared.LateLocation, process.DestTable, process.EventID+shared.JSONExt)
+	data, err := json.Marshal(process.Source)
+	if err == nil {
+		err = s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data))
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to journal late data file: %v", process.Source.URL)
+	}
+	return nil
+}
+
 func (s *service) runPostLoadActions(ctx context.Context, request *contract.Request, response *contract.Response) error {
 	action, err := task.NewActionFromURL(ctx, s.fs, request.SourceURL)
 	if err != nil {
@@ -440,16 +486,24 @@ func (s *service) runPostLoadActions(ctx context.Context, request *contract.Requ
 		response.Retriable = true
 		return bqJobError
 	}
+	if bqJobError == nil && bqJob.Configuration != nil && bqJob.Configuration.Load != nil {
+		rule := s.config.Rule(ctx, action.Meta.RuleURL)
+		s.countLoad(ctx, rule, action.Meta.DestTable, bqJob, response)
+		s.quarantine(ctx, rule, &action.Meta.Process, bqJob, response)
+	}
 
 	if err := action.Init(ctx, s.cfs); err != nil {
 		return err
 	}
 	bqjob := base.Job(*bqJob)
-	toRun := action.ToRun(bqJobError, &bqjob)
+	toRun, conditionErr := action.ToRun(bqJobError, &bqjob)
 	retriable, err := task.RunAll(ctx, s.Registry, toRun)
 	if retriable {
 		response.Retriable = true
 	}
+	if err == nil {
+		err = conditionErr
+	}
 	if err != nil {
 		return err
 	}
@@ -498,7 +552,10 @@ func (s *service) runInBatch(ctx context.Context, rule *config.Rule, window *bat
 		shared.LogF("[%v] starting batch window: %s\n", window.DestTable, rule.Batch.Window.Duration)
 	}
 	if remainingDuration > 0 {
-		time.Sleep(remainingDuration)
+		s.waitForWindow(ctx, rule, window, remainingDuration)
+	}
+	if rule.Group != "" {
+		return nil, s.runGroupInBatch(ctx, rule, window, response)
 	}
 	err := s.batch.MatchWindowDataURLs(ctx, rule, window)
 	if err != nil || len(window.URIs) == 0 {
@@ -513,10 +570,165 @@ func (s *service) runInBatch(ctx context.Context, rule *config.Rule, window *bat
 	if err != nil {
 		return nil, err
 	}
-	loadJob, err = s.submitJob(ctx, loadJob, response)
+	loadJob, err = s.submitWindowJob(ctx, loadJob, response)
 	return loadJob, err
 }
 
+//submitWindowJob submits window load job, job exceeding load job limits is split into parts loading into the same table
+func (s *service) submitWindowJob(ctx context.Context, job *load.Job, response *contract.Response) (*load.Job, error) {
+	parts, err := job.Split(shared.MaxLoadURIs, shared.MaxLoadBytes, func(process *stage.Process) {
+		process.ProcessURL = s.config.BuildLoadURL(process)
+		process.DoneProcessURL = s.config.DoneLoadURL(process)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 1 {
+		return s.submitJob(ctx, job, response)
+	}
+	if shared.IsInfoLoggingLevel() {
+		shared.LogF("[%v] loading %v data file(s) with %v load jobs\n", job.DestTable, len(job.Part.URIs), len(parts))
+	}
+	var errs = make([]string, 0)
+	for _, part := range parts[1:] {
+		if err := s.submitPart(ctx, part, response); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	loadJob, err := s.submitJob(ctx, parts[0], response)
+	if loadJob == nil || loadJob.BqJob == nil {
+		if err == nil {
+			err = errors.Errorf("failed to submit load job: %v", job.EventID)
+		}
+		err = s.failPart(ctx, job.Part, err)
+	}
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return loadJob, errors.Errorf("failed to load %v in parts: %v", job.DestTable, strings.Join(errs, "; "))
+	}
+	return loadJob, nil
+}
+
+//submitPart submits additional window load job part, a part that could not be submitted is marked as failed to unblock other parts commit
+func (s *service) submitPart(ctx context.Context, part *load.Job, response *contract.Response) error {
+	loadJob, err := s.submitJob(ctx, part, response)
+	if loadJob == nil || loadJob.BqJob == nil {
+		if err == nil {
+			err = errors.Errorf("failed to submit load job part: %v", part.EventID)
+		}
+		return s.failPart(ctx, part.Part, err)
+	}
+	if loadJob.Recoverable() {
+		return s.tryRecover(ctx, loadJob, response)
+	}
+	return err
+}
+
+//waitForWindow waits for window end time, window with batch limit can be closed earlier
+func (s *service) waitForWindow(ctx context.Context, rule *config.Rule, window *batch.Window, remainingDuration time.Duration) {
+	if !rule.Batch.HasLimit() {
+		time.Sleep(remainingDuration)
+		return
+	}
+	checkFrequency := time.Duration(shared.StorageListVisibilityDelay) * time.Millisecond
+	deadline := time.Now().Add(remainingDuration)
+	for !s.batch.IsClosed(ctx, window) {
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			return
+		}
+		if remaining > checkFrequency {
+			remaining = checkFrequency
+		}
+		time.Sleep(remaining)
+	}
+}
+
+//runGroupInBatch loads each group member into transient table, copying to destinations is deferred till all members complete
+func (s *service) runGroupInBatch(ctx context.Context, rule *config.Rule, window *batch.Window, response *contract.Response) error {
+	members, err := s.batch.MatchGroupWindows(ctx, s.config.Group(rule.Group), window)
+	if err != nil || len(members) == 0 {
+		return err
+	}
+	groupURL := strings.Replace(window.URL, shared.WindowExt, "/"+rule.Group+"/", 1)
+	var expected = make([]string, 0)
+	for _, member := range members {
+		expected = append(expected, member.DestTable)
+	}
+	if shared.IsInfoLoggingLevel() {
+		shared.LogF("[%v] starting group batch with %v member(s)\n", rule.Group, len(members))
+	}
+	var errs = make([]string, 0)
+	for _, member := range members {
+		commitGroup := &load.Group{URL: groupURL, Member: member.DestTable, Expected: expected}
+		if err := s.runGroupMember(ctx, member, commitGroup, response); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to run group %v batch: %v", rule.Group, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *service) runGroupMember(ctx context.Context, window *batch.Window, commitGroup *load.Group, response *contract.Response) error {
+	rule := s.config.Rule(ctx, window.RuleURL)
+	if rule == nil {
+		return s.failGroupMember(ctx, commitGroup, errors.Errorf("failed to lookup rule: '%v'", window.RuleURL))
+	}
+	var err error
+	process := window.Process
+	process.ProcessURL = s.config.BuildLoadURL(process)
+	process.DoneProcessURL = s.config.DoneLoadURL(process)
+	if process.Params, err = rule.Dest.Params(process.Source.URL); err != nil {
+		return s.failGroupMember(ctx, commitGroup, err)
+	}
+	s.logBatchInfo(ctx, window)
+	loadJob, err := load.NewJob(rule, process, window)
+	if err != nil {
+		return s.failGroupMember(ctx, commitGroup, err)
+	}
+	loadJob.Group = commitGroup
+	if err = loadJob.Init(ctx, s.bq); err != nil {
+		return s.failGroupMember(ctx, commitGroup, err)
+	}
+	loadJob, err = s.submitWindowJob(ctx, loadJob, response)
+	if loadJob != nil && loadJob.Part != nil && loadJob.BqJob == nil {
+		return err //group commit is handled by failed parts commit
+	}
+	if loadJob == nil || loadJob.BqJob == nil {
+		if err == nil {
+			err = errors.Errorf("failed to submit load job: %v", process.DestTable)
+		}
+		return s.failGroupMember(ctx, commitGroup, err)
+	}
+	//once load job was submitted, group commit is handled by the load job post actions
+	if loadJob.Recoverable() {
+		return s.tryRecover(ctx, loadJob, response)
+	}
+	return err
+}
+
+//failGroupMember marks group member that could not be loaded as failed to unblock the group commit
+func (s *service) failGroupMember(ctx context.Context, commitGroup *load.Group, err error) error {
+	request := &group.CommitRequest{URL: commitGroup.URL, Member: commitGroup.Member, Expected: commitGroup.Expected, Error: err.Error()}
+	if commitErr := s.group.Commit(ctx, request, nil); commitErr != nil {
+		return errors.Wrapf(err, "failed to commit group member: %v", commitErr)
+	}
+	return err
+}
+
+//failPart marks load job part as failed, so that the last completed part runs deferred failure actions
+func (s *service) failPart(ctx context.Context, part *load.Part, err error) error {
+	request := &group.BarrierRequest{URL: part.URL, Member: part.Member, Expected: part.Expected, Error: err.Error()}
+	if barrierErr := s.group.Barrier(ctx, request, nil); barrierErr != nil {
+		return errors.Wrapf(err, "failed to complete load job part: %v", barrierErr)
+	}
+	return err
+}
+
 func (s *service) tryRecover(ctx context.Context, job *load.Job, response *contract.Response) error {
 	err := base.JobError(job.BqJob)
 	if err == nil {
@@ -536,6 +748,12 @@ func (s *service) tryRecover(ctx context.Context, job *load.Job, response *contr
 	uris.Classify(ctx, s.fs, job.BqJob)
 
 	if len(uris.InvalidSchema) > 0 {
+		if job.Rule.Dest.AllowTypeWidening && len(uris.WidenedFields) > 0 {
+			uris.AcceptWidened()
+			if err := s.widenFields(ctx, job, uris); err != nil {
+				return err
+			}
+		}
 		if job.Rule.Dest.AllowFieldAddition {
 			uris.Valid = append(uris.Valid, uris.InvalidSchema...)
 			uris.InvalidSchema = []string{}
@@ -552,7 +770,7 @@ func (s *service) tryRecover(ctx context.Context, job *load.Job, response *contr
 		}
 	}
 
-	if len(uris.InvalidSchema) == 0 && len(uris.Corrupted) == 0 && len(uris.Missing) == 0 && len(uris.MissingFields) == 0 {
+	if len(uris.InvalidSchema) == 0 && len(uris.Corrupted) == 0 && len(uris.Missing) == 0 && len(uris.MissingFields) == 0 && len(uris.WidenedFields) == 0 {
 		return base.JobError(job.BqJob)
 	}
 
@@ -566,7 +784,11 @@ func (s *service) tryRecover(ctx context.Context, job *load.Job, response *contr
 		response.MoveError = err.Error()
 	}
 
+	recovery := counter.NewCounter(job.DestTable, time.Now())
+	recovery.Corrupted = int64(len(uris.Corrupted))
+	recovery.InvalidSchema = int64(len(uris.InvalidSchema))
 	if len(uris.Valid) == 0 {
+		s.updateCounter(ctx, job.Rule, recovery, response)
 		response.Retriable = false
 		return nil
 	}
@@ -582,13 +804,20 @@ func (s *service) tryRecover(ctx context.Context, job *load.Job, response *contr
 		shared.LogLn(meta)
 	}
 	if reloadCount > job.Rule.MaxReloadAttempts() {
+		s.updateCounter(ctx, job.Rule, recovery, response)
 		return base.JobError(job.BqJob)
 	}
+	recovery.Reloads = 1
+	s.updateCounter(ctx, job.Rule, recovery, response)
 	action.Meta = action.Meta.Wrap(shared.ActionReload)
 	loadJob, err := s.bq.Load(ctx, loadRequest, action)
 	if err == nil {
 		err = base.JobError(loadJob)
 	}
+	if err == nil {
+		s.countLoad(ctx, job.Rule, job.DestTable, loadJob, response)
+		s.quarantine(ctx, job.Rule, job.Process, loadJob, response)
+	}
 	if err != nil && loadJob != nil {
 		job.BqJob = loadJob
 		return s.tryRecover(ctx, job, response)
@@ -687,49 +916,169 @@ func (s *service) logJobInfo(ctx context.Context, bqjob *bigquery.Job, action *t
 }
 
 func (s *service) addMissingFields(ctx context.Context, job *load.Job, uris *status.URIs) error {
-	for _, field := range uris.MissingFields {
-		if err := field.AdjustType(ctx, s.fs); err != nil {
-			return err
-		}
-		if shared.IsInfoLoggingLevel() {
+	missing, err := uris.InferMissingFields(ctx, s.fs)
+	if err != nil {
+		return err
+	}
+	if shared.IsInfoLoggingLevel() {
+		for _, field := range uris.MissingFields {
 			shared.LogF("Adding field: %v %v\n", field.Name, field.Type)
 		}
 	}
-
 	if job.TempTable != "" {
-		if err := s.PatchedTable(ctx, uris.MissingFields, job.TempTable); err != nil {
+		if err := s.PatchedTable(ctx, missing, job.TempTable); err != nil {
 			return err
 		}
 	}
 	if job.DestTable != "" {
-		if err := s.PatchedTable(ctx, uris.MissingFields, job.DestTable); err != nil {
+		if err := s.PatchedTable(ctx, missing, job.DestTable); err != nil {
 			return err
 		}
 	}
 	if job.Rule.Dest.Schema.Template != "" {
-		if err := s.PatchedTable(ctx, uris.MissingFields, job.Rule.Dest.Schema.Template); err != nil {
+		if err := s.PatchedTable(ctx, missing, job.Rule.Dest.Schema.Template); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *service) PatchedTable(ctx context.Context, fields []*status.Field, tableName string) error {
+//widenFields widens dest and template tables fields type or mode, transient table is recreated with widened load job schema
+func (s *service) widenFields(ctx context.Context, job *load.Job, uris *status.URIs) error {
+	if shared.IsInfoLoggingLevel() {
+		for _, field := range uris.WidenedFields {
+			shared.LogF("Widening field: %v %v %v\n", field.Name, field.Type, field.Mode)
+		}
+	}
+	relaxed := false
+	for _, field := range uris.WidenedFields {
+		if job.Load.Schema != nil {
+			schema.Widen(job.Load.Schema.Fields, field.Name, field.Type, field.Mode)
+		}
+		relaxed = relaxed || field.Mode == schema.ModeNullable
+	}
+	if relaxed && job.Load.WriteDisposition == shared.WriteDispositionAppend && !toolbox.HasSliceAnyElements(job.Load.SchemaUpdateOptions, shared.AllowFieldRelaxation) {
+		job.Load.SchemaUpdateOptions = append(job.Load.SchemaUpdateOptions, shared.AllowFieldRelaxation)
+	}
+	var DDL = make([]string, 0)
+	for _, tableName := range []string{job.DestTable, job.Rule.Dest.Schema.Template} {
+		if tableName == "" {
+			continue
+		}
+		statements, err := s.WidenedTable(ctx, uris.WidenedFields, tableName)
+		if err != nil {
+			return err
+		}
+		DDL = append(DDL, statements...)
+	}
+	var transient *bigquery.Table
+	if job.TempTable != "" {
+		var err error
+		if transient, err = s.widenedTransient(ctx, job, uris.WidenedFields); err != nil {
+			return err
+		}
+		DDL = append(DDL, fmt.Sprintf("DROP TABLE IF EXISTS `%v`", base.EncodeTableReference(transient.TableReference, true)))
+	}
+	if len(DDL) == 0 {
+		return nil
+	}
+	if err := s.runWidenDDL(ctx, job, DDL); err != nil {
+		return err
+	}
+	if transient == nil {
+		return nil
+	}
+	if err := s.bq.CreateTableIfNotExist(ctx, transient, false); err != nil {
+		return errors.Wrapf(err, "failed to recreate transient table: %v", job.TempTable)
+	}
+	return nil
+}
+
+//WidenedTable relaxes table fields mode with patch, and returns ALTER COLUMN statements for widened fields type, as tables.patch does not allow column type change
+func (s *service) WidenedTable(ctx context.Context, fields []*status.Field, tableName string) ([]string, error) {
 	tableRef, _ := base.NewTableReference(tableName)
 	table, err := s.bq.Table(ctx, tableRef)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	schemaFields := table.Schema.Fields
+	var DDL = make([]string, 0)
+	relaxed := false
 	for _, field := range fields {
-		if len(field.Fields) == 0 {
-			return errors.Errorf("failed to detect schema for %v", field.Name)
+		if schema.Widen(table.Schema.Fields, field.Name, "", field.Mode) {
+			relaxed = true
+		}
+		if field.Type == "" {
+			continue
+		}
+		tableField := schema.Field(table.Schema.Fields, field.Name)
+		if tableField == nil || !schema.IsWider(field.Type, tableField.Type) {
+			continue
+		}
+		if strings.Contains(field.Name, ".") {
+			return nil, errors.Errorf("unable to widen %v nested field type to %v: %v", field.Name, field.Type, tableName)
+		}
+		DDL = append(DDL, sql.BuildAlterColumnType(tableRef, field.Name, field.Type))
+	}
+	if !relaxed {
+		return DDL, nil
+	}
+	table.ExpirationTime = 0
+	_, err = s.bq.Patch(ctx, &bq.PatchRequest{
+		Table:         tableName,
+		TemplateTable: table,
+	})
+	return DDL, err
+}
+
+//widenedTransient returns transient table to recreate with widened load job schema
+func (s *service) widenedTransient(ctx context.Context, job *load.Job, fields []*status.Field) (*bigquery.Table, error) {
+	tableRef, _ := base.NewTableReference(job.TempTable)
+	table, err := s.bq.Table(ctx, tableRef)
+	if err != nil {
+		return nil, err
+	}
+	tableSchema := job.Load.Schema
+	if tableSchema == nil {
+		tableSchema = table.Schema
+		for _, field := range fields {
+			schema.Widen(tableSchema.Fields, field.Name, field.Type, field.Mode)
 		}
-		schemaFields = schema.MergeFields(schemaFields, field.Fields)
 	}
+	return &bigquery.Table{
+		TableReference:    tableRef,
+		Schema:            tableSchema,
+		Clustering:        table.Clustering,
+		RangePartitioning: table.RangePartitioning,
+		TimePartitioning:  table.TimePartitioning,
+	}, nil
+}
 
-	table.Schema.Fields = schemaFields
+//runWidenDDL runs widening DDL script in sync mode, so that reload job runs with widened tables
+func (s *service) runWidenDDL(ctx context.Context, job *load.Job, DDL []string) error {
+	widenProcess := *job.Process
+	widenProcess.EventID += widenEventSuffix
+	widenProcess.Async = false
+	step := activity.Parse(job.BqJob.JobReference.JobId).Step
+	action := &task.Action{
+		Action:  shared.ActionQuery,
+		Meta:    activity.New(&widenProcess, shared.ActionQuery, shared.StepModeNop, step),
+		Actions: &task.Actions{},
+	}
+	SQL := strings.Join(DDL, ";\n") + ";"
+	if _, err := s.bq.Query(ctx, &bq.QueryRequest{SQL: SQL}, action); err != nil {
+		return errors.Wrapf(err, "failed to widen fields: %v", SQL)
+	}
+	return nil
+}
+
+//PatchedTable patches table with merged table and supplied fields schema
+func (s *service) PatchedTable(ctx context.Context, fields []*bigquery.TableFieldSchema, tableName string) error {
+	tableRef, _ := base.NewTableReference(tableName)
+	table, err := s.bq.Table(ctx, tableRef)
+	if err != nil {
+		return err
+	}
+	table.Schema.Fields = schema.MergeFields(table.Schema.Fields, fields)
 	table.ExpirationTime = 0
 	_, err = s.bq.Patch(ctx, &bq.PatchRequest{
 		Template:      "",
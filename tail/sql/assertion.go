@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"regexp"
+	"strings"
+)
+
+var comparisonOperators = []string{">=", "<=", "!=", "<>", "=", ">", "<"}
+
+var aggregateExpr = regexp.MustCompile(`(?i)\b(COUNT|COUNTIF|SUM|AVG|MIN|MAX|LOGICAL_AND|LOGICAL_OR|ANY_VALUE|ARRAY_AGG|STRING_AGG|APPROX_COUNT_DISTINCT|APPROX_QUANTILES|APPROX_TOP_COUNT|BIT_AND|BIT_OR|BIT_XOR|CORR|COVAR_POP|COVAR_SAMP|STDDEV|STDDEV_POP|STDDEV_SAMP|VARIANCE|VAR_POP|VAR_SAMP)\s*\(`)
+var literalExpr = regexp.MustCompile(`'(\\.|[^'\\])*'|"(\\.|[^"\\])*"`)
+
+//BuildAssertion returns SQL evaluating assertions against source table, the first failed assertion raises an error
+//with assertion name and observed value, row predicate is aggregated with LOGICAL_AND and observes failing rows count
+func BuildAssertion(source *bigquery.TableReference, alias string, assertions []*config.Assertion) string {
+	sourceTable := "`" + base.EncodeTableReference(source, true) + "`"
+	var projection = make([]string, 0)
+	for i, assertion := range assertions {
+		expr, observed := assertion.Expr, assertion.Observed
+		if isAggregate(expr) {
+			if observed == "" {
+				observed = observedExpression(expr)
+			}
+		} else {
+			if observed == "" {
+				observed = fmt.Sprintf("COUNTIF(NOT (%v))", expr)
+			}
+			expr = fmt.Sprintf("IFNULL(LOGICAL_AND(%v), TRUE)", expr)
+		}
+		message := quote(fmt.Sprintf("assertion %v failed: %v, observed: ", assertion.Name, assertion.Expr))
+		projection = append(projection, fmt.Sprintf("IF(%v, TRUE, ERROR(CONCAT(%v, IFNULL(CAST(%v AS STRING), 'NULL')))) AS assertion%v",
+			expr, message, observed, i))
+	}
+	return fmt.Sprintf("SELECT %v\nFROM %v %v $WHERE", strings.Join(projection, ",\n  "), sourceTable, alias)
+}
+
+//isAggregate returns true if expression uses aggregate function (outside of string literals)
+func isAggregate(expr string) bool {
+	return aggregateExpr.MatchString(literalExpr.ReplaceAllString(expr, "''"))
+}
+
+//observedExpression returns left operand of top level comparison or expression itself
+func observedExpression(expr string) string {
+	depth := 0
+	var quoted rune
+	for i, r := range expr {
+		switch {
+		case quoted != 0:
+			if r == quoted {
+				quoted = 0
+			}
+			continue
+		case r == '\'' || r == '"':
+			quoted = r
+			continue
+		case r == '(':
+			depth++
+			continue
+		case r == ')':
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+		for _, operator := range comparisonOperators {
+			if strings.HasPrefix(expr[i:], operator) {
+				if left := strings.TrimSpace(expr[:i]); left != "" {
+					return left
+				}
+			}
+		}
+	}
+	return expr
+}
+
+func quote(text string) string {
+	return "'" + strings.Replace(strings.Replace(text, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+}
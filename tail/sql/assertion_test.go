@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestBuildAssertion(t *testing.T) {
+	source := &bigquery.TableReference{ProjectId: "p", DatasetId: "temp", TableId: "events_123"}
+	var useCases = []struct {
+		description string
+		assertions  []*config.Assertion
+		expect      string
+	}{
+		{
+			description: "aggregate assertion",
+			assertions:  []*config.Assertion{{Name: "notEmpty", Expr: "COUNT(*) > 0"}},
+			expect: "SELECT IF(COUNT(*) > 0, TRUE, ERROR(CONCAT('assertion notEmpty failed: COUNT(*) > 0, observed: ', IFNULL(CAST(COUNT(*) AS STRING), 'NULL')))) AS assertion0\n" +
+				"FROM `p.temp.events_123` t $WHERE",
+		},
+		{
+			description: "multi assertions with observed",
+			assertions: []*config.Assertion{
+				{Name: "noNullID", Expr: "COUNTIF(id IS NULL) = 0"},
+				{Name: "notFuture", Expr: "MAX(ts) < CURRENT_TIMESTAMP()", Observed: "TIMESTAMP_DIFF(MAX(ts), CURRENT_TIMESTAMP(), SECOND)"},
+			},
+			expect: "SELECT IF(COUNTIF(id IS NULL) = 0, TRUE, ERROR(CONCAT('assertion noNullID failed: COUNTIF(id IS NULL) = 0, observed: ', IFNULL(CAST(COUNTIF(id IS NULL) AS STRING), 'NULL')))) AS assertion0,\n" +
+				"  IF(MAX(ts) < CURRENT_TIMESTAMP(), TRUE, ERROR(CONCAT('assertion notFuture failed: MAX(ts) < CURRENT_TIMESTAMP(), observed: ', IFNULL(CAST(TIMESTAMP_DIFF(MAX(ts), CURRENT_TIMESTAMP(), SECOND) AS STRING), 'NULL')))) AS assertion1\n" +
+				"FROM `p.temp.events_123` t $WHERE",
+		},
+		{
+			description: "quoted expression",
+			assertions:  []*config.Assertion{{Name: "status", Expr: "LOGICAL_AND(status != 'x')"}},
+			expect: "SELECT IF(LOGICAL_AND(status != 'x'), TRUE, ERROR(CONCAT('assertion status failed: LOGICAL_AND(status != \\'x\\'), observed: ', IFNULL(CAST(LOGICAL_AND(status != 'x') AS STRING), 'NULL')))) AS assertion0\n" +
+				"FROM `p.temp.events_123` t $WHERE",
+		},
+		{
+			description: "row predicate assertion",
+			assertions:  []*config.Assertion{{Name: "positive", Expr: "amount >= 0"}},
+			expect: "SELECT IF(IFNULL(LOGICAL_AND(amount >= 0), TRUE), TRUE, ERROR(CONCAT('assertion positive failed: amount >= 0, observed: ', IFNULL(CAST(COUNTIF(NOT (amount >= 0)) AS STRING), 'NULL')))) AS assertion0\n" +
+				"FROM `p.temp.events_123` t $WHERE",
+		},
+		{
+			description: "mixed aggregate and row predicate assertions",
+			assertions: []*config.Assertion{
+				{Name: "notEmpty", Expr: "COUNT(*) > 0"},
+				{Name: "status", Expr: "status IN ('count(', 'max(')"},
+			},
+			expect: "SELECT IF(COUNT(*) > 0, TRUE, ERROR(CONCAT('assertion notEmpty failed: COUNT(*) > 0, observed: ', IFNULL(CAST(COUNT(*) AS STRING), 'NULL')))) AS assertion0,\n" +
+				"  IF(IFNULL(LOGICAL_AND(status IN ('count(', 'max(')), TRUE), TRUE, ERROR(CONCAT('assertion status failed: status IN (\\'count(\\', \\'max(\\'), observed: ', IFNULL(CAST(COUNTIF(NOT (status IN ('count(', 'max('))) AS STRING), 'NULL')))) AS assertion1\n" +
+				"FROM `p.temp.events_123` t $WHERE",
+		},
+	}
+	for _, useCase := range useCases {
+		actual := BuildAssertion(source, "t", useCase.assertions)
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestObservedExpression(t *testing.T) {
+	var useCases = []struct {
+		expr   string
+		expect string
+	}{
+		{expr: "COUNT(*) > 0", expect: "COUNT(*)"},
+		{expr: "COUNTIF(id IS NULL) = 0", expect: "COUNTIF(id IS NULL)"},
+		{expr: "MAX(ts) <= CURRENT_TIMESTAMP()", expect: "MAX(ts)"},
+		{expr: "COUNTIF(name = '>') <> 0", expect: "COUNTIF(name = '>')"},
+		{expr: "LOGICAL_AND(id > 0)", expect: "LOGICAL_AND(id > 0)"},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, observedExpression(useCase.expr), useCase.expr)
+	}
+}
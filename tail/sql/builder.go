@@ -44,7 +44,23 @@ FROM (
       ROW_NUMBER() OVER (PARTITION BY %v) row_number
   FROM %v $WHERE
 ) %v $JOIN
-WHERE row_number = 1`, strings.Join(outerProjection, ", "), strings.Join(projection, ", "), strings.Join(dest.UniqueColumns, ","), sourceTable, dest.Transient.Alias)
+WHERE row_number = 1`, strings.Join(outerProjection, ", "), strings.Join(projection, ", "), dedupePartition(dest), sourceTable, dest.Transient.Alias)
+}
+
+//dedupePartition returns dedupe window partition, merge version column orders rows so that the latest version is taken
+func dedupePartition(dest *config.Destination) string {
+	result := strings.Join(dest.UniqueColumns, ",")
+	if version := mergeVersionColumn(dest); version != "" {
+		result += " ORDER BY " + version + " DESC"
+	}
+	return result
+}
+
+func mergeVersionColumn(dest *config.Destination) string {
+	if !dest.IsCopyMethodMerge() || dest.Transient.Merge == nil {
+		return ""
+	}
+	return dest.Transient.Merge.VersionColumn
 }
 
 func getTransform(dest *config.Destination) (map[string]string, []string) {
@@ -178,12 +194,48 @@ func BuildAppendDML(source, destination *bigquery.TableReference, sourceSchema *
 //BuildSelect returns select SQL statement for specified parameter, if uniqueColumns SQL de-duplicates data
 func BuildSelect(source *bigquery.TableReference, sourceSchema *bigquery.TableSchema, dest *config.Destination, destSchema *bigquery.TableSchema) string {
 	except := columnExclusion(sourceSchema, destSchema)
+	dest = recastTransform(sourceSchema, dest, destSchema)
 	SQL := buildSelect(source, sourceSchema, dest, except)
 	join := buildJoins(dest.SideInputs)
 	SQL = strings.Replace(SQL, "$JOIN", join, 1)
 	return SQL
 }
 
+//BuildAlterColumnType returns DDL widening table column data type, BigQuery tables.patch does not allow column type change
+func BuildAlterColumnType(table *bigquery.TableReference, column, fieldType string) string {
+	return fmt.Sprintf("ALTER TABLE `%v` ALTER COLUMN %v SET DATA TYPE %v", base.EncodeTableReference(table, true), column, schema.StandardType(fieldType))
+}
+
+//recastTransform returns destination with transform casting source columns to wider dest table column type, so that widened dest table can take narrower source
+func recastTransform(sourceSchema *bigquery.TableSchema, dest *config.Destination, destSchema *bigquery.TableSchema) *config.Destination {
+	if !dest.AllowTypeWidening || dest.Transient == nil || sourceSchema == nil || destSchema == nil {
+		return dest
+	}
+	var recast *config.Destination
+	for _, field := range sourceSchema.Fields {
+		if _, ok := getTransformExpression(dest, field); ok {
+			continue
+		}
+		destField := schema.Field(destSchema.Fields, field.Name)
+		if destField == nil || !schema.IsWider(destField.Type, field.Type) {
+			continue
+		}
+		if recast == nil {
+			cloned := *dest
+			cloned.Transform = make(map[string]string)
+			for k, v := range dest.Transform {
+				cloned.Transform[k] = v
+			}
+			recast = &cloned
+		}
+		recast.Transform[field.Name] = fmt.Sprintf("CAST(%v.%v AS %v)", dest.Transient.Alias, field.Name, schema.StandardType(destField.Type))
+	}
+	if recast == nil {
+		return dest
+	}
+	return recast
+}
+
 func columnExclusion(source, destSchema *bigquery.TableSchema) map[string]bool {
 	if destSchema == nil {
 		return map[string]bool{}
@@ -222,7 +274,7 @@ func buildSelect(source *bigquery.TableReference, tableScheme *bigquery.TableSch
 	if len(dest.UniqueColumns) == 0 {
 		return buildSelectAll(sourceTable, schema, dest, except)
 	}
-	if schema.IsNested() {
+	if schema.IsNested() || mergeVersionColumn(dest) != "" {
 		return buildNestedDedupeSQL(sourceTable, schema, dest, except)
 	}
 	var unique = make(map[string]bool)
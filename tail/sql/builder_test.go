@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestBuildSelect_Recast(t *testing.T) {
+	source := &bigquery.TableReference{ProjectId: "p", DatasetId: "temp", TableId: "events_123"}
+	sourceSchema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "qty", Type: "INTEGER"},
+		{Name: "price", Type: "FLOAT"},
+	}}
+	destSchema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "qty", Type: "FLOAT"},
+		{Name: "price", Type: "FLOAT"},
+	}}
+	dest := &config.Destination{Transient: &config.Transient{Alias: "t"}, AllowTypeWidening: true}
+	SQL := BuildSelect(source, sourceSchema, dest, destSchema)
+	assert.Contains(t, SQL, "CAST(t.qty AS FLOAT64) AS qty")
+	assert.Contains(t, SQL, "t.id AS id")
+	assert.Contains(t, SQL, "t.price AS price")
+	assert.Empty(t, dest.Transform)
+
+	dest.AllowTypeWidening = false
+	SQL = BuildSelect(source, sourceSchema, dest, destSchema)
+	assert.NotContains(t, SQL, "CAST")
+}
+
+func TestBuildAlterColumnType(t *testing.T) {
+	var useCases = []struct {
+		description string
+		table       *bigquery.TableReference
+		column      string
+		fieldType   string
+		expect      string
+	}{
+		{
+			description: "integer to float",
+			table:       &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "events"},
+			column:      "qty",
+			fieldType:   "FLOAT",
+			expect:      "ALTER TABLE `p.db.events` ALTER COLUMN qty SET DATA TYPE FLOAT64",
+		},
+		{
+			description: "integer to numeric",
+			table:       &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "events"},
+			column:      "amount",
+			fieldType:   "NUMERIC",
+			expect:      "ALTER TABLE `p.db.events` ALTER COLUMN amount SET DATA TYPE NUMERIC",
+		},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expect, BuildAlterColumnType(useCase.table, useCase.column, useCase.fieldType), useCase.description)
+	}
+}
@@ -0,0 +1,46 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+)
+
+//BuildMergeDML returns MERGE destination USING (deduplicated select) ON unique columns DML
+func BuildMergeDML(source, destination *bigquery.TableReference, sourceSchema *bigquery.TableSchema, dest *config.Destination, destSchema *bigquery.TableSchema) string {
+	selectAll := BuildSelect(source, sourceSchema, dest, destSchema)
+	except := columnExclusion(sourceSchema, destSchema)
+	columns := columnNames(sourceSchema, dest, except)
+	destRef := *destination
+	destRef.TableId = base.TableID(destRef.TableId)
+	destTable := base.EncodeTableReference(&destRef, true)
+
+	var unique = make(map[string]bool)
+	var on = make([]string, 0)
+	for _, column := range dest.UniqueColumns {
+		unique[strings.ToLower(column)] = true
+		on = append(on, fmt.Sprintf("T.%v = S.%v", column, column))
+	}
+	var update = make([]string, 0)
+	var values = make([]string, 0)
+	for _, column := range columns {
+		values = append(values, "S."+column)
+		if !unique[strings.ToLower(column)] {
+			update = append(update, fmt.Sprintf("%v = S.%v", column, column))
+		}
+	}
+	result := fmt.Sprintf("MERGE `%v` T\nUSING (%v) S\nON %v", destTable, selectAll, strings.Join(on, " AND "))
+	merge := dest.Transient.Merge
+	if (merge == nil || merge.WhenMatched != shared.MergeMatchedIgnore) && len(update) > 0 {
+		condition := ""
+		if merge != nil && merge.VersionColumn != "" {
+			condition = fmt.Sprintf(" AND S.%v > T.%v", merge.VersionColumn, merge.VersionColumn)
+		}
+		result += fmt.Sprintf("\nWHEN MATCHED%v THEN\n  UPDATE SET %v", condition, strings.Join(update, ", "))
+	}
+	result += fmt.Sprintf("\nWHEN NOT MATCHED THEN\n  INSERT (%v) VALUES (%v)", strings.Join(columns, ", "), strings.Join(values, ", "))
+	return result
+}
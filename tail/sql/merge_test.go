@@ -0,0 +1,83 @@
+package sql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/tail/config/transient"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+	"testing"
+)
+
+func TestBuildMergeDML(t *testing.T) {
+	source := &bigquery.TableReference{ProjectId: "p", DatasetId: "temp", TableId: "events_123"}
+	destination := &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "events$20200101"}
+	tableSchema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "name", Type: "STRING"},
+		{Name: "updated", Type: "TIMESTAMP"},
+	}}
+
+	var useCases = []struct {
+		description string
+		merge       *transient.Merge
+		transform   map[string]string
+		expect      []string
+		notExpect   []string
+	}{
+		{
+			description: "update all",
+			merge:       &transient.Merge{},
+			expect: []string{
+				"MERGE `p.db.events` T",
+				"GROUP BY 1",
+				"ON T.id = S.id",
+				"WHEN MATCHED THEN\n  UPDATE SET name = S.name, updated = S.updated",
+				"WHEN NOT MATCHED THEN\n  INSERT (id, name, updated) VALUES (S.id, S.name, S.updated)",
+			},
+		},
+		{
+			description: "update greater version",
+			merge:       &transient.Merge{VersionColumn: "updated"},
+			expect: []string{
+				"ROW_NUMBER() OVER (PARTITION BY id ORDER BY updated DESC) row_number",
+				"WHEN MATCHED AND S.updated > T.updated THEN",
+			},
+			notExpect: []string{"GROUP BY"},
+		},
+		{
+			description: "ignore matched",
+			merge:       &transient.Merge{WhenMatched: shared.MergeMatchedIgnore},
+			expect:      []string{"WHEN NOT MATCHED THEN"},
+			notExpect:   []string{"WHEN MATCHED"},
+		},
+		{
+			description: "transform",
+			merge:       &transient.Merge{},
+			transform:   map[string]string{"name": "UPPER(t.name)", "source": "'batch'"},
+			expect: []string{
+				"MAX(UPPER(t.name)) AS name",
+				"UPDATE SET name = S.name, updated = S.updated, source = S.source",
+				"INSERT (id, name, updated, source) VALUES (S.id, S.name, S.updated, S.source)",
+			},
+		},
+	}
+
+	for _, useCase := range useCases {
+		useCase.merge.Init()
+		dest := &config.Destination{
+			Table:         "p:db.events",
+			UniqueColumns: []string{"id"},
+			Transform:     useCase.transform,
+			Transient:     &config.Transient{Dataset: "temp", Alias: "t", CopyMethod: &shared.CopyMethodMerge, Merge: useCase.merge},
+		}
+		SQL := BuildMergeDML(source, destination, tableSchema, dest, tableSchema)
+		for _, expect := range useCase.expect {
+			assert.True(t, strings.Contains(SQL, expect), "%v: %v\n%v", useCase.description, expect, SQL)
+		}
+		for _, notExpect := range useCase.notExpect {
+			assert.False(t, strings.Contains(SQL, notExpect), "%v: %v\n%v", useCase.description, notExpect, SQL)
+		}
+	}
+}
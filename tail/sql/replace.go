@@ -0,0 +1,52 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+)
+
+const replaceBatchTable = "_replace_batch"
+
+//BuildReplaceDML returns script deleting destination partitions present in transformed source and inserting source data within one transaction
+func BuildReplaceDML(source, destination *bigquery.TableReference, sourceSchema *bigquery.TableSchema, dest *config.Destination, destSchema *bigquery.TableSchema, timePartitioning *bigquery.TimePartitioning, rangePartitioning *bigquery.RangePartitioning) (string, error) {
+	partition, err := partitionExpression(timePartitioning, rangePartitioning)
+	if err != nil {
+		return "", err
+	}
+	selectAll := BuildSelect(source, sourceSchema, dest, destSchema)
+	except := columnExclusion(sourceSchema, destSchema)
+	columns := strings.Join(columnNames(sourceSchema, dest, except), ", ")
+	destRef := *destination
+	destRef.TableId = base.TableID(destRef.TableId)
+	destTable := base.EncodeTableReference(&destRef, true)
+	//temp table is named after destination, so that grouped replace scripts can run within one transaction
+	batchTable := replaceBatchTable + "_" + destRef.TableId
+	return fmt.Sprintf(`CREATE TEMP TABLE %v AS %v;
+BEGIN TRANSACTION;
+DELETE FROM `+"`%v`"+` WHERE %v IN (SELECT DISTINCT %v FROM %v);
+INSERT INTO `+"`%v`"+`(%v) SELECT %v FROM %v;
+COMMIT TRANSACTION;`, batchTable, selectAll, destTable, partition, partition, batchTable, destTable, columns, columns, batchTable), nil
+}
+
+//partitionExpression returns expression computing column value partition
+func partitionExpression(timePartitioning *bigquery.TimePartitioning, rangePartitioning *bigquery.RangePartitioning) (string, error) {
+	if rangePartitioning != nil && rangePartitioning.Range != nil {
+		r := rangePartitioning.Range
+		return fmt.Sprintf("RANGE_BUCKET(%v, GENERATE_ARRAY(%v, %v, %v))", rangePartitioning.Field, r.Start, r.End, r.Interval), nil
+	}
+	if timePartitioning == nil {
+		return "", errors.New("destination table is not partitioned")
+	}
+	if timePartitioning.Field == "" {
+		return "", errors.New("ingestion time partitioned destination table is not supported, partitioning column is required")
+	}
+	unit := timePartitioning.Type
+	if unit == "" {
+		unit = "DAY"
+	}
+	return fmt.Sprintf("TIMESTAMP_TRUNC(TIMESTAMP(%v), %v)", timePartitioning.Field, unit), nil
+}
@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/tail/config"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+	"testing"
+)
+
+func TestBuildReplaceDML(t *testing.T) {
+	source := &bigquery.TableReference{ProjectId: "p", DatasetId: "temp", TableId: "events_123"}
+	destination := &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "events"}
+	tableSchema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "bucket", Type: "INTEGER"},
+		{Name: "ts", Type: "TIMESTAMP"},
+	}}
+
+	var useCases = []struct {
+		description       string
+		timePartitioning  *bigquery.TimePartitioning
+		rangePartitioning *bigquery.RangePartitioning
+		uniqueColumns     []string
+		expect            []string
+		hasError          bool
+	}{
+		{
+			description:      "day partition",
+			timePartitioning: &bigquery.TimePartitioning{Field: "ts"},
+			expect: []string{
+				"CREATE TEMP TABLE _replace_batch_events AS SELECT t.id AS id, t.bucket AS bucket, t.ts AS ts \nFROM `p.temp.events_123` t",
+				"BEGIN TRANSACTION;",
+				"DELETE FROM `p.db.events` WHERE TIMESTAMP_TRUNC(TIMESTAMP(ts), DAY) IN (SELECT DISTINCT TIMESTAMP_TRUNC(TIMESTAMP(ts), DAY) FROM _replace_batch_events);",
+				"INSERT INTO `p.db.events`(id, bucket, ts) SELECT id, bucket, ts FROM _replace_batch_events;",
+				"COMMIT TRANSACTION;",
+			},
+		},
+		{
+			description:      "hour partition with dedupe",
+			timePartitioning: &bigquery.TimePartitioning{Field: "ts", Type: "HOUR"},
+			uniqueColumns:    []string{"id"},
+			expect: []string{
+				"GROUP BY 1",
+				"WHERE TIMESTAMP_TRUNC(TIMESTAMP(ts), HOUR) IN",
+			},
+		},
+		{
+			description:       "range partition",
+			rangePartitioning: &bigquery.RangePartitioning{Field: "bucket", Range: &bigquery.RangePartitioningRange{Start: 0, End: 100, Interval: 10}},
+			expect: []string{
+				"WHERE RANGE_BUCKET(bucket, GENERATE_ARRAY(0, 100, 10)) IN (SELECT DISTINCT RANGE_BUCKET(bucket, GENERATE_ARRAY(0, 100, 10)) FROM _replace_batch_events)",
+			},
+		},
+		{
+			description:      "ingestion time partition",
+			timePartitioning: &bigquery.TimePartitioning{Type: "DAY"},
+			hasError:         true,
+		},
+		{
+			description: "not partitioned",
+			hasError:    true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		dest := &config.Destination{
+			Table:         "p:db.events",
+			UniqueColumns: useCase.uniqueColumns,
+			Transient:     &config.Transient{Dataset: "temp", Alias: "t", CopyMethod: &shared.CopyMethodReplace},
+		}
+		SQL, err := BuildReplaceDML(source, destination, tableSchema, dest, tableSchema, useCase.timePartitioning, useCase.rangePartitioning)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		for _, expect := range useCase.expect {
+			assert.True(t, strings.Contains(SQL, expect), "%v: %v\n%v", useCase.description, expect, SQL)
+		}
+	}
+}
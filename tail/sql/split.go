@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/viant/bqtail/base"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+)
+
+//BuildElseCriteria returns criteria matching rows that none of split mapping criteria matches
+func BuildElseCriteria(criteria []string) string {
+	return fmt.Sprintf("NOT (%v)", strings.Join(matchExpressions(criteria), " OR "))
+}
+
+//BuildSplitCheck returns SQL raising an error when a source row matches more than one split mapping (exclusive)
+//or does not match any split mapping (matched)
+func BuildSplitCheck(source *bigquery.TableReference, alias string, criteria []string, exclusive, matched bool) string {
+	sourceTable := "`" + base.EncodeTableReference(source, true) + "`"
+	var counters = make([]string, 0)
+	for _, expr := range matchExpressions(criteria) {
+		counters = append(counters, fmt.Sprintf("IF(%v, 1, 0)", expr))
+	}
+	var projection = make([]string, 0)
+	if exclusive {
+		projection = append(projection, "IF(COUNTIF(matches > 1) = 0, TRUE, ERROR(CONCAT('split mappings are not exclusive, rows matching more than one mapping: ', CAST(COUNTIF(matches > 1) AS STRING)))) AS exclusive")
+	}
+	if matched {
+		projection = append(projection, "IF(COUNTIF(matches = 0) = 0, TRUE, ERROR(CONCAT('split mappings did not match rows: ', CAST(COUNTIF(matches = 0) AS STRING)))) AS matched")
+	}
+	return fmt.Sprintf("SELECT %v\nFROM (SELECT %v AS matches FROM %v %v)", strings.Join(projection, ",\n  "), strings.Join(counters, " + "), sourceTable, alias)
+}
+
+//BuildSplitScript returns multi statement transaction script for supplied split statements,
+//DDL statements (i.e. split table creation) run before the transaction as BigQuery does not allow them within a transaction
+func BuildSplitScript(ddl, statements []string) string {
+	script := fmt.Sprintf("BEGIN TRANSACTION;\n%v;\nCOMMIT TRANSACTION;", strings.Join(statements, ";\n"))
+	if len(ddl) == 0 {
+		return script
+	}
+	return fmt.Sprintf("%v;\n%v", strings.Join(ddl, ";\n"), script)
+}
+
+//matchExpressions returns criteria expressions evaluating NULL as no match
+func matchExpressions(criteria []string) []string {
+	var result = make([]string, len(criteria))
+	for i, expr := range criteria {
+		result[i] = fmt.Sprintf("COALESCE((%v), FALSE)", expr)
+	}
+	return result
+}
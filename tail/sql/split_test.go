@@ -0,0 +1,92 @@
+package sql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestBuildElseCriteria(t *testing.T) {
+	var useCases = []struct {
+		description string
+		criteria    []string
+		expect      string
+	}{
+		{
+			description: "single mapping",
+			criteria:    []string{"type = 1"},
+			expect:      "NOT (COALESCE((type = 1), FALSE))",
+		},
+		{
+			description: "multi mapping",
+			criteria:    []string{"type = 1", "type IN(2, 3)"},
+			expect:      "NOT (COALESCE((type = 1), FALSE) OR COALESCE((type IN(2, 3)), FALSE))",
+		},
+	}
+	for _, useCase := range useCases {
+		actual := BuildElseCriteria(useCase.criteria)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestBuildSplitCheck(t *testing.T) {
+	source := &bigquery.TableReference{ProjectId: "p", DatasetId: "temp", TableId: "events_123"}
+	criteria := []string{"type = 1", "type = 2"}
+	var useCases = []struct {
+		description string
+		exclusive   bool
+		matched     bool
+		expect      string
+	}{
+		{
+			description: "exclusive check",
+			exclusive:   true,
+			expect: "SELECT IF(COUNTIF(matches > 1) = 0, TRUE, ERROR(CONCAT('split mappings are not exclusive, rows matching more than one mapping: ', CAST(COUNTIF(matches > 1) AS STRING)))) AS exclusive\n" +
+				"FROM (SELECT IF(COALESCE((type = 1), FALSE), 1, 0) + IF(COALESCE((type = 2), FALSE), 1, 0) AS matches FROM `p.temp.events_123` t)",
+		},
+		{
+			description: "exclusive and matched check",
+			exclusive:   true,
+			matched:     true,
+			expect: "SELECT IF(COUNTIF(matches > 1) = 0, TRUE, ERROR(CONCAT('split mappings are not exclusive, rows matching more than one mapping: ', CAST(COUNTIF(matches > 1) AS STRING)))) AS exclusive,\n" +
+				"  IF(COUNTIF(matches = 0) = 0, TRUE, ERROR(CONCAT('split mappings did not match rows: ', CAST(COUNTIF(matches = 0) AS STRING)))) AS matched\n" +
+				"FROM (SELECT IF(COALESCE((type = 1), FALSE), 1, 0) + IF(COALESCE((type = 2), FALSE), 1, 0) AS matches FROM `p.temp.events_123` t)",
+		},
+	}
+	for _, useCase := range useCases {
+		actual := BuildSplitCheck(source, "t", criteria, useCase.exclusive, useCase.matched)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestBuildSplitScript(t *testing.T) {
+	var useCases = []struct {
+		description string
+		ddl         []string
+		statements  []string
+		expect      string
+	}{
+		{
+			description: "split statements",
+			statements:  []string{"INSERT INTO `p.db.a`(id) SELECT id FROM `p.temp.t` t WHERE type = 1", "INSERT INTO `p.db.b`(id) SELECT id FROM `p.temp.t` t WHERE type = 2"},
+			expect: "BEGIN TRANSACTION;\n" +
+				"INSERT INTO `p.db.a`(id) SELECT id FROM `p.temp.t` t WHERE type = 1;\n" +
+				"INSERT INTO `p.db.b`(id) SELECT id FROM `p.temp.t` t WHERE type = 2;\n" +
+				"COMMIT TRANSACTION;",
+		},
+		{
+			description: "split table creation before transaction",
+			ddl:         []string{"CREATE TABLE IF NOT EXISTS `p.db.b` LIKE `p.db.a`"},
+			statements:  []string{"INSERT INTO `p.db.a`(id) SELECT id FROM `p.temp.t` t WHERE type = 1", "INSERT INTO `p.db.b`(id) SELECT id FROM `p.temp.t` t WHERE type = 2"},
+			expect: "CREATE TABLE IF NOT EXISTS `p.db.b` LIKE `p.db.a`;\n" +
+				"BEGIN TRANSACTION;\n" +
+				"INSERT INTO `p.db.a`(id) SELECT id FROM `p.temp.t` t WHERE type = 1;\n" +
+				"INSERT INTO `p.db.b`(id) SELECT id FROM `p.temp.t` t WHERE type = 2;\n" +
+				"COMMIT TRANSACTION;",
+		},
+	}
+	for _, useCase := range useCases {
+		actual := BuildSplitScript(useCase.ddl, useCase.statements)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
@@ -0,0 +1,75 @@
+package status
+
+import (
+	"google.golang.org/api/bigquery/v2"
+	"regexp"
+	"strconv"
+)
+
+var (
+	badRecordOffsetExpr = regexp.MustCompile(`starting at (?:position|location)[: ]*(\d+)`)
+	badRecordLineExpr   = regexp.MustCompile(`\b(?:Line|Row|Rows): *(\d+)`)
+)
+
+//BadRecord represents a record skipped by load job with max bad records option
+type BadRecord struct {
+	SourceURI string `json:",omitempty"`
+	Line      int64  `json:",omitempty"`
+	Offset    int64  `json:",omitempty"`
+	Reason    string `json:",omitempty"`
+	Error     string `json:",omitempty"`
+	DestTable string `json:",omitempty"`
+	EventID   string `json:",omitempty"`
+	JobID     string `json:",omitempty"`
+	Created   string `json:",omitempty"`
+}
+
+//BadRecords returns successful load job skipped records, records are identified by job status data file errors
+func BadRecords(job *bigquery.Job) []*BadRecord {
+	var result = make([]*BadRecord, 0)
+	if job == nil || job.Status == nil || job.Status.ErrorResult != nil || job.Configuration == nil || job.Configuration.Load == nil {
+		return result
+	}
+	var URIs = make(map[string]bool)
+	for _, URI := range job.Configuration.Load.SourceUris {
+		URIs[URI] = true
+	}
+	for _, element := range job.Status.Errors {
+		if !URIs[element.Location] {
+			continue
+		}
+		record := &BadRecord{
+			SourceURI: element.Location,
+			Reason:    element.Reason,
+			Error:     element.Message,
+		}
+		if matched := badRecordOffsetExpr.FindStringSubmatch(element.Message); len(matched) > 1 {
+			record.Offset, _ = strconv.ParseInt(matched[1], 10, 64)
+		}
+		if matched := badRecordLineExpr.FindStringSubmatch(element.Message); len(matched) > 1 {
+			record.Line, _ = strconv.ParseInt(matched[1], 10, 64)
+		}
+		if job.JobReference != nil {
+			record.JobID = job.JobReference.JobId
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+//BadRecordSchema returns quarantine table schema
+func BadRecordSchema() *bigquery.TableSchema {
+	return &bigquery.TableSchema{
+		Fields: []*bigquery.TableFieldSchema{
+			{Name: "SourceURI", Type: "STRING"},
+			{Name: "Line", Type: "INTEGER"},
+			{Name: "Offset", Type: "INTEGER"},
+			{Name: "Reason", Type: "STRING"},
+			{Name: "Error", Type: "STRING"},
+			{Name: "DestTable", Type: "STRING"},
+			{Name: "EventID", Type: "STRING"},
+			{Name: "JobID", Type: "STRING"},
+			{Name: "Created", Type: "TIMESTAMP"},
+		},
+	}
+}
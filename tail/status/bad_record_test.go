@@ -0,0 +1,64 @@
+package status
+
+import (
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/bigquery/v2"
+	"testing"
+)
+
+func TestBadRecords(t *testing.T) {
+	var useCases = []struct {
+		description string
+		job         *bigquery.Job
+		expect      []*BadRecord
+	}{
+		{
+			description: "json and csv bad records",
+			job: &bigquery.Job{
+				JobReference: &bigquery.JobReference{JobId: "mytable--123_00001_load--tail"},
+				Configuration: &bigquery.JobConfiguration{Load: &bigquery.JobConfigurationLoad{
+					SourceUris: []string{"gs://bucket/data/a.json", "gs://bucket/data/b.csv"},
+				}},
+				Status: &bigquery.JobStatus{
+					State: "DONE",
+					Errors: []*bigquery.ErrorProto{
+						{Reason: "invalid", Location: "gs://bucket/data/a.json", Message: "Error while reading data, error message: JSON parsing error in row starting at position 2016: Could not convert value to string. Field: name; Value: 1"},
+						{Reason: "invalid", Location: "gs://bucket/data/b.csv", Message: "Error while reading data, error message: Too many values in line. Line: 3 / Field: 4"},
+						{Reason: "invalid", Message: "Error while reading data, error message: 2 bad records skipped"},
+					},
+				},
+			},
+			expect: []*BadRecord{
+				{SourceURI: "gs://bucket/data/a.json", Offset: 2016, Reason: "invalid", JobID: "mytable--123_00001_load--tail",
+					Error: "Error while reading data, error message: JSON parsing error in row starting at position 2016: Could not convert value to string. Field: name; Value: 1"},
+				{SourceURI: "gs://bucket/data/b.csv", Line: 3, Reason: "invalid", JobID: "mytable--123_00001_load--tail",
+					Error: "Error while reading data, error message: Too many values in line. Line: 3 / Field: 4"},
+			},
+		},
+		{
+			description: "failed job",
+			job: &bigquery.Job{
+				Configuration: &bigquery.JobConfiguration{Load: &bigquery.JobConfigurationLoad{
+					SourceUris: []string{"gs://bucket/data/a.json"},
+				}},
+				Status: &bigquery.JobStatus{
+					State:       "DONE",
+					ErrorResult: &bigquery.ErrorProto{Reason: "invalid", Message: "too many errors"},
+					Errors:      []*bigquery.ErrorProto{{Reason: "invalid", Location: "gs://bucket/data/a.json", Message: "JSON parsing error"}},
+				},
+			},
+			expect: []*BadRecord{},
+		},
+		{
+			description: "no errors",
+			job: &bigquery.Job{
+				Configuration: &bigquery.JobConfiguration{Load: &bigquery.JobConfigurationLoad{}},
+				Status:        &bigquery.JobStatus{State: "DONE"},
+			},
+			expect: []*BadRecord{},
+		},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, BadRecords(useCase.job), useCase.description)
+	}
+}
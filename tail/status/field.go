@@ -2,15 +2,14 @@ package status
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
 	"github.com/viant/bqtail/schema"
-	"github.com/viant/toolbox"
 	"google.golang.org/api/bigquery/v2"
-	"strings"
+	"io"
 )
 
 //Field represents schema missing field
@@ -23,69 +22,79 @@ type Field struct {
 	Fields   []*bigquery.TableFieldSchema
 }
 
+//AdjustType infers field type and data file schema from all rows starting from the field row,
+//rows schemas are merged, so that fields deep inside nested and repeated records are all included
 func (f *Field) AdjustType(ctx context.Context, fs afs.Service) error {
 	reader, err := fs.DownloadWithURL(ctx, f.Location)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
-	scanner := bufio.NewScanner(reader)
-	rowNo := 1
-	fName := f.Name
-	for ; scanner.Scan(); rowNo++ {
-		data := scanner.Bytes()
-		if rowNo < f.Row {
-			continue
+	lines := bufio.NewReader(reader)
+	description := "Added auto: from location:" + f.Location + " at: %s"
+	for rowNo := 1; ; rowNo++ {
+		data, readErr := lines.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return errors.Wrapf(readErr, "failed to read %v", f.Location)
 		}
-		var record = map[string]interface{}{}
-		if err := json.Unmarshal(data, &record); err != nil {
-			return err
-		}
-		if err != nil {
-			return errors.Wrapf(err, "unable to extract schema from %v", record)
-		}
-		fName, values := f.getFieldWithRecord(fName, record)
-		value, ok := values[fName]
-		if !ok || value == nil {
-			continue
+		if data = bytes.TrimSpace(data); len(data) > 0 && rowNo >= f.Row {
+			var record = map[string]interface{}{}
+			if err := json.Unmarshal(data, &record); err != nil {
+				return errors.Wrapf(err, "unable to extract schema from %v, row: %v", f.Location, rowNo)
+			}
+			fields, err := schema.New(compact(record).(map[string]interface{}), description)
+			if err != nil {
+				return errors.Wrapf(err, "unable to extract schema from %v, row: %v", f.Location, rowNo)
+			}
+			f.Fields = schema.MergeFields(f.Fields, fields)
 		}
-		f.Fields, err = schema.New(record, "Added auto: from location:"+f.Location+fmt.Sprintf(", row: %v,", rowNo)+" at: %s")
-		isRepeated := false
-		f.Type, isRepeated = schema.FieldType(value)
-		if isRepeated {
-			f.Mode = schema.ModeRepeated
+		if readErr == io.EOF {
+			break
 		}
-		break
 	}
+	f.adjustType()
 	return nil
 }
 
-func (f *Field) getFieldWithRecord(fName string, record map[string]interface{}) (string, map[string]interface{}) {
+//adjustType sets field type and mode with inferred schema
+func (f *Field) adjustType() {
+	if field := schema.Field(f.Fields, f.Name); field != nil {
+		f.Type = field.Type
+		f.Mode = field.Mode
+	}
+}
 
-	for strings.Contains(fName, ".") {
-		if index := strings.Index(fName, "."); index != -1 {
-			parent := fName[:index]
-			value, ok := record[parent]
-			if ok {
-				fName = fName[index+1:]
-				if toolbox.IsSlice(value) {
-					aSlice := toolbox.AsSlice(value)
-					for _, val := range aSlice {
-						if val == nil {
-							continue
-						}
-						if toolbox.IsMap(val) {
-							record = toolbox.AsMap(val)
-							if v, ok := record[fName]; ok && v != nil {
-								break
-							}
-						}
-					}
-				} else if toolbox.IsMap(value) {
-					record = toolbox.AsMap(value)
-				}
+//compact removes null values, empty arrays and maps, so that field type is inferred only from a row with the field value
+func compact(value interface{}) interface{} {
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		for k, v := range actual {
+			if v = compact(v); isEmpty(v) {
+				delete(actual, k)
+				continue
 			}
+			actual[k] = v
 		}
+	case []interface{}:
+		var result = make([]interface{}, 0, len(actual))
+		for _, item := range actual {
+			if item = compact(item); !isEmpty(item) {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	return value
+}
+
+func isEmpty(value interface{}) bool {
+	switch actual := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(actual) == 0
+	case []interface{}:
+		return len(actual) == 0
 	}
-	return fName, record
+	return false
 }
@@ -95,6 +95,43 @@ func TestField_AdjustType(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "empty array type from subsequent row",
+			location:    "mem://localhost/status/field/case005",
+			field: &Field{
+				Name:     "tags",
+				Location: "mem://localhost/status/field/case005/data.json",
+				Row:      1,
+				Type:     "",
+			},
+			expectType: "STRING",
+			assets: []*asset.Resource{
+				{
+					Name: "data.json",
+					Data: []byte(`{"id": 101, "tags": [], "attrs": {}}
+{"id": 102, "tags": null}
+{"id": 103, "tags": ["a", "b"]}`),
+				},
+			},
+		},
+		{
+			description: "int widened to float by subsequent row",
+			location:    "mem://localhost/status/field/case006",
+			field: &Field{
+				Name:     "value",
+				Location: "mem://localhost/status/field/case006/data.json",
+				Row:      1,
+				Type:     "",
+			},
+			expectType: "FLOAT64",
+			assets: []*asset.Resource{
+				{
+					Name: "data.json",
+					Data: []byte(`{"id": 101, "value": 3}
+{"id": 102, "value": 3.4}`),
+				},
+			},
+		},
 
 		{
 			description: "unsupported yey type",
@@ -2,11 +2,14 @@ package status
 
 import (
 	"context"
+	"github.com/pkg/errors"
 	"github.com/viant/afs"
 	"github.com/viant/afs/option"
 	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/schema"
 	"github.com/viant/toolbox"
 	"google.golang.org/api/bigquery/v2"
+	"regexp"
 	"strings"
 )
 
@@ -16,6 +19,17 @@ const (
 	rowsFragment        = "Rows:"
 )
 
+var (
+	//JSON: Could not convert value 'double: 1.5' to integer. Field: x; Value: 1.5
+	convertValueExpr = regexp.MustCompile(`Could not convert value '[^']*' to ([A-Za-z0-9]+)\. Field: ([^;\s]+); Value: ([^\s]+)`)
+	//CSV: Could not parse '1.5' as INT64 for field x (position 0) starting at location 0
+	parseValueExpr = regexp.MustCompile(`Could not parse '([^']*)' as ([A-Za-z0-9]+) for field ([^\s]+)`)
+	//JSON: Only optional fields can be set to NULL. Field: x; Value: NULL
+	optionalFieldExpr = regexp.MustCompile(`Only optional fields can be set to NULL\. Field: ([^;\s]+)`)
+	//CSV: Required field x cannot be null
+	requiredFieldExpr = regexp.MustCompile(`Required field ([^\s]+) cannot be null`)
+)
+
 //URIs represents error classified URIs
 type URIs struct {
 	Valid         []string `json:",omitempty"`
@@ -23,6 +37,7 @@ type URIs struct {
 	Missing       []string `json:",omitempty"`
 	Corrupted     []string `json:",omitempty"`
 	MissingFields []*Field `json:",omitempty"`
+	WidenedFields []*Field `json:",omitempty"`
 }
 
 //Classify classify uri
@@ -38,6 +53,7 @@ func (u *URIs) Classify(ctx context.Context, fs afs.Service, job *bigquery.Job)
 	schemaErrors := getInvalidSchemaLocations(job)
 	if len(schemaErrors) > 0 {
 		u.addMissingFields(job)
+		u.addWidenedFields(job)
 	}
 
 	for _, element := range job.Status.Errors {
@@ -89,6 +105,7 @@ func (u *URIs) Classify(ctx context.Context, fs afs.Service, job *bigquery.Job)
 
 func (u *URIs) addMissingFields(job *bigquery.Job) {
 	rows := 0
+	var added = make(map[string]bool)
 	for _, element := range job.Status.Errors {
 		if index := strings.Index(element.Message, rowsFragment); index != -1 {
 			rowsInfo := string(element.Message[index+1+len(rowsFragment):])
@@ -99,17 +116,107 @@ func (u *URIs) addMissingFields(job *bigquery.Job) {
 		}
 		if index := strings.Index(element.Message, noSuchFieldFragment); index != -1 {
 			field := strings.Trim(string(element.Message[index+1+len(noSuchFieldFragment):]), ".")
+			key := element.Location + "/" + field
+			if added[key] {
+				continue
+			}
+			added[key] = true
 			u.MissingFields = append(u.MissingFields, &Field{
 				Name:     field,
 				Location: element.Location,
 				Row:      rows,
 				Type:     "",
 			})
-			break
 		}
 	}
 }
 
+//addWidenedFields adds fields with type mismatch or null value errors that can be fixed by type widening or mode relaxation
+func (u *URIs) addWidenedFields(job *bigquery.Job) {
+	var added = make(map[string]bool)
+	for _, element := range job.Status.Errors {
+		if element.Location == "" {
+			continue
+		}
+		field := widenedField(element.Message)
+		if field == nil {
+			continue
+		}
+		key := element.Location + "/" + field.Name + "/" + field.Type + "/" + field.Mode
+		if added[key] {
+			continue
+		}
+		added[key] = true
+		field.Location = element.Location
+		u.WidenedFields = append(u.WidenedFields, field)
+	}
+}
+
+//AcceptWidened moves invalid schema URIs with widened fields to valid URIs
+func (u *URIs) AcceptWidened() {
+	var widened = make(map[string]bool)
+	for _, field := range u.WidenedFields {
+		widened[field.Location] = true
+	}
+	var invalidSchema = make([]string, 0)
+	for _, URI := range u.InvalidSchema {
+		if widened[URI] {
+			u.Valid = append(u.Valid, URI)
+			continue
+		}
+		invalidSchema = append(invalidSchema, URI)
+	}
+	u.InvalidSchema = invalidSchema
+}
+
+//widenedField returns a field with widened type or relaxed mode for supplied error message or nil
+func widenedField(message string) *Field {
+	if match := convertValueExpr.FindStringSubmatch(message); len(match) > 0 {
+		if fieldType := schema.WidenType(match[1], match[3]); fieldType != "" {
+			return &Field{Name: match[2], Type: fieldType}
+		}
+		return nil
+	}
+	if match := parseValueExpr.FindStringSubmatch(message); len(match) > 0 {
+		if fieldType := schema.WidenType(match[2], match[1]); fieldType != "" {
+			return &Field{Name: match[3], Type: fieldType}
+		}
+		return nil
+	}
+	if match := optionalFieldExpr.FindStringSubmatch(message); len(match) > 0 {
+		return &Field{Name: match[1], Mode: schema.ModeNullable}
+	}
+	if match := requiredFieldExpr.FindStringSubmatch(message); len(match) > 0 {
+		return &Field{Name: match[1], Mode: schema.ModeNullable}
+	}
+	return nil
+}
+
+//InferMissingFields infers missing fields types and returns merged schema of all rows of data files with missing fields,
+//data file is read only once regardless of its missing fields count
+func (u *URIs) InferMissingFields(ctx context.Context, fs afs.Service) ([]*bigquery.TableFieldSchema, error) {
+	var inferred = make(map[string]*Field)
+	var fields = make([][]*bigquery.TableFieldSchema, 0)
+	for _, field := range u.MissingFields {
+		if prev, ok := inferred[field.Location]; ok && prev.Row <= field.Row {
+			field.Fields = prev.Fields
+			field.adjustType()
+			continue
+		}
+		if err := field.AdjustType(ctx, fs); err != nil {
+			return nil, err
+		}
+		inferred[field.Location] = field
+		fields = append(fields, field.Fields)
+	}
+	for _, field := range u.MissingFields {
+		if field.Type == "" {
+			return nil, errors.Errorf("failed to detect schema for %v in %v", field.Name, field.Location)
+		}
+	}
+	return schema.MergeFields(fields...), nil
+}
+
 func getInvalidSchemaLocations(job *bigquery.Job) map[string]bool {
 	var schemaError = make(map[string]bool)
 	for _, element := range job.Status.Errors {
@@ -131,5 +238,6 @@ func NewURIs() *URIs {
 		Missing:       make([]string, 0),
 		Corrupted:     make([]string, 0),
 		MissingFields: make([]*Field, 0),
+		WidenedFields: make([]*Field, 0),
 	}
 }
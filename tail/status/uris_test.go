@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/bqtail/schema"
 	"google.golang.org/api/bigquery/v2"
+	"strings"
 	"testing"
 )
 
@@ -451,3 +455,70 @@ func TestURIs_Classify(t *testing.T) {
 	}
 
 }
+
+func TestURIs_InferMissingFields(t *testing.T) {
+	ctx := context.Background()
+	location := "mem://localhost/status/uris/case001"
+	fs := afs.New()
+	data := `{"id": 1, "info": {"key": "k1"}}
+{"id": 2, "info": {"key": "k2", "extra": {"flag": true}}, "items": [{"sku": "a"}]}
+{"id": 3, "info": {"key": "k3"}, "items": [null, {"sku": "b", "attrs": [{"k": "x", "v": 3.4}]}]}
+{"id": 4, "info": {"key": null}, "items": [{"sku": "c", "qty": 3}]}`
+	err := fs.Upload(ctx, location+"/data.json", file.DefaultFileOsMode, strings.NewReader(data))
+	if !assert.Nil(t, err) {
+		return
+	}
+	uris := &URIs{MissingFields: []*Field{
+		{Name: "info.extra", Location: location + "/data.json", Row: 2},
+		{Name: "items", Location: location + "/data.json", Row: 2},
+	}}
+	fields, err := uris.InferMissingFields(ctx, fs)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "RECORD", uris.MissingFields[0].Type)
+	assert.Equal(t, "RECORD", uris.MissingFields[1].Type)
+	assert.Equal(t, "REPEATED", uris.MissingFields[1].Mode)
+
+	var expect = map[string]string{
+		"id":              "INT64",
+		"info.key":        "STRING",
+		"info.extra.flag": "BOOLEAN",
+		"items.sku":       "STRING",
+		"items.qty":       "INT64",
+		"items.attrs.k":   "STRING",
+		"items.attrs.v":   "FLOAT64",
+	}
+	for name, fieldType := range expect {
+		field := schema.Field(fields, name)
+		if assert.NotNil(t, field, name) {
+			assert.Equal(t, fieldType, field.Type, name)
+		}
+	}
+}
+
+func TestURIs_AcceptWidened(t *testing.T) {
+	job := &bigquery.Job{
+		Configuration: &bigquery.JobConfiguration{Load: &bigquery.JobConfigurationLoad{
+			SourceUris: []string{"gs://b/1.json", "gs://b/2.csv", "gs://b/3.json", "gs://b/4.json"},
+		}},
+		Status: &bigquery.JobStatus{Errors: []*bigquery.ErrorProto{
+			{Location: "gs://b/1.json", Message: "Error while reading data, error message: JSON parsing error in row starting at position 0: Could not convert value 'double: 1.5' to integer. Field: qty; Value: 1.5"},
+			{Location: "gs://b/1.json", Message: "Error while reading data, error message: JSON parsing error in row starting at position 20: Only optional fields can be set to NULL. Field: info.name; Value: NULL"},
+			{Location: "gs://b/2.csv", Message: "Could not parse '92233720368547758070' as INT64 for field id (position 0) starting at location 0  with message 'Unable to parse'"},
+			{Location: "gs://b/3.json", Message: "Error while reading data, error message: JSON parsing error in row starting at position 0: No such field: extra."},
+		}},
+	}
+	uris := NewURIs()
+	uris.Classify(context.Background(), nil, job)
+	assert.EqualValues(t, []*Field{
+		{Name: "qty", Location: "gs://b/1.json", Type: "FLOAT64"},
+		{Name: "info.name", Location: "gs://b/1.json", Mode: "NULLABLE"},
+		{Name: "id", Location: "gs://b/2.csv", Type: "NUMERIC"},
+	}, uris.WidenedFields)
+	assert.EqualValues(t, []string{"gs://b/1.json", "gs://b/2.csv", "gs://b/3.json"}, uris.InvalidSchema)
+
+	uris.AcceptWidened()
+	assert.EqualValues(t, []string{"gs://b/3.json"}, uris.InvalidSchema)
+	assert.EqualValues(t, []string{"gs://b/4.json", "gs://b/1.json", "gs://b/2.csv"}, uris.Valid)
+}
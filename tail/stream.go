@@ -0,0 +1,100 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/shared"
+	"github.com/viant/bqtail/stage"
+	"github.com/viant/bqtail/tail/config"
+	"github.com/viant/bqtail/tail/contract"
+	"github.com/viant/bqtail/tail/stream"
+	"github.com/viant/bqtail/task"
+	"google.golang.org/api/bigquery/v2"
+	"strings"
+)
+
+//tailStream streams data file rows into destination table, rule post actions run once the data file was streamed
+func (s *service) tailStream(ctx context.Context, process *stage.Process, rule *config.Rule, response *contract.Response) error {
+	request, err := s.newStreamRequest(ctx, process, rule)
+	if err != nil {
+		return err
+	}
+	streamResponse, err := s.stream.Stream(ctx, request)
+	response.Stream = streamResponse
+	if streamResponse == nil {
+		streamResponse = &stream.Response{}
+	}
+	if shared.IsInfoLoggingLevel() {
+		shared.LogF("[%v] streamed %v/%v row(s), failed: %v\n", process.DestTable, streamResponse.Inserted, streamResponse.Rows, streamResponse.Failed)
+	}
+	bqJob := streamJob(process, request, streamResponse, err)
+	actions := rule.Actions().Expand(process, shared.ActionLoad, []string{process.Source.URL})
+	toRun, conditionErr := actions.ToRun(err, bqJob)
+	retriable, runErr := task.RunAll(ctx, s.Registry, toRun)
+	if runErr == nil {
+		runErr = conditionErr
+	}
+	if err != nil {
+		response.Retriable = base.IsRetryError(err)
+		return err
+	}
+	response.Retriable = retriable
+	return runErr
+}
+
+func (s *service) newStreamRequest(ctx context.Context, process *stage.Process, rule *config.Rule) (*stream.Request, error) {
+	tableRef, err := rule.Dest.TableReference(process.Source)
+	if err != nil {
+		return nil, err
+	}
+	request := &stream.Request{
+		Source:          process.Source,
+		Table:           tableRef,
+		SourceFormat:    rule.Dest.SourceFormat,
+		SkipLeadingRows: rule.Dest.SkipLeadingRows,
+		FieldDelimiter:  rule.Dest.FieldDelimiter,
+		ErrorURL:        url.Join(s.config.ErrorURL, process.DestTable, process.EventID+shared.ErrorExt),
+	}
+	if request.SourceFormat == "" {
+		request.SourceFormat = "NEWLINE_DELIMITED_JSON"
+		if strings.Contains(strings.ToLower(process.Source.URL), ".csv") {
+			request.SourceFormat = "CSV"
+		}
+	}
+	if request.SourceFormat == "CSV" {
+		schemaRef := *tableRef
+		table, err := s.bq.Table(ctx, &schemaRef)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get stream table schema")
+		}
+		request.Schema = table.Schema
+	}
+	return request, nil
+}
+
+//streamJob returns stream summary as load job, so that post actions conditions and notification can use load job statistics
+func streamJob(process *stage.Process, request *stream.Request, response *stream.Response, err error) *base.Job {
+	result := &base.Job{
+		JobReference: &bigquery.JobReference{JobId: fmt.Sprintf("%v--%v--%v", strings.Replace(process.DestTable, ".", "_", 1), process.EventID, shared.RuleModeStream)},
+		Configuration: &bigquery.JobConfiguration{
+			JobType: "LOAD",
+			Load:    &bigquery.JobConfigurationLoad{SourceUris: []string{process.Source.URL}, DestinationTable: request.Table},
+		},
+		Statistics: &bigquery.JobStatistics{
+			Load: &bigquery.JobStatistics3{
+				InputFiles:     1,
+				InputFileBytes: process.Source.Size,
+				OutputRows:     int64(response.Inserted),
+				BadRecords:     int64(response.Failed),
+			},
+		},
+		Status: &bigquery.JobStatus{State: "DONE"},
+	}
+	if err != nil {
+		result.Status.ErrorResult = &bigquery.ErrorProto{Message: err.Error()}
+	}
+	return result
+}
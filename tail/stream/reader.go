@@ -0,0 +1,148 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"google.golang.org/api/bigquery/v2"
+	"io"
+	"strings"
+)
+
+const gzipExt = ".gz"
+
+//invalidReason row error reason of rows that can not be parsed
+const invalidReason = "invalid"
+
+//row represents data file row
+type row struct {
+	number   int
+	insertID string
+	size     int
+	values   map[string]bigquery.JsonValue
+	//invalid is set when the row can not be parsed, the row is then reported with raw text instead of values
+	invalid *bigquery.ErrorProto
+	raw     string
+}
+
+type rowReader struct {
+	request *Request
+	lines   *bufio.Reader
+	csv     *csv.Reader
+	//sourceID distinguishes data file upload, so that only replayed rows of the same upload are deduplicated
+	sourceID string
+	number   int
+}
+
+//next returns next row or nil if there is no more rows
+func (r *rowReader) next() (*row, error) {
+	if r.csv != nil {
+		return r.nextCSV()
+	}
+	return r.nextJSON()
+}
+
+func (r *rowReader) nextJSON() (*row, error) {
+	for {
+		line, err := r.lines.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrapf(err, "failed to read %v", r.request.Source.URL)
+		}
+		if len(line) == 0 && err == io.EOF {
+			return nil, nil
+		}
+		r.number++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || int64(r.number) <= r.request.SkipLeadingRows {
+			continue
+		}
+		var values = make(map[string]bigquery.JsonValue)
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return r.newInvalidRow(string(line), "invalid JSON: %v", err), nil
+		}
+		return r.newRow(values, len(line)), nil
+	}
+}
+
+func (r *rowReader) nextCSV() (*row, error) {
+	for {
+		record, err := r.csv.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		parseErr, isParseErr := err.(*csv.ParseError)
+		if err != nil && !isParseErr {
+			return nil, errors.Wrapf(err, "failed to read CSV %v", r.request.Source.URL)
+		}
+		r.number++
+		if int64(r.number) <= r.request.SkipLeadingRows {
+			continue
+		}
+		if isParseErr {
+			return r.newInvalidRow(strings.Join(record, string(r.csv.Comma)), "invalid CSV: %v", parseErr.Err), nil
+		}
+		fields := r.request.Schema.Fields
+		if len(record) > len(fields) {
+			return r.newInvalidRow(strings.Join(record, string(r.csv.Comma)), "invalid CSV: %v values, but schema has %v fields", len(record), len(fields)), nil
+		}
+		var values = make(map[string]bigquery.JsonValue)
+		size := 0
+		for i, value := range record {
+			size += len(value)
+			if value == "" && strings.ToUpper(fields[i].Type) != "STRING" {
+				values[fields[i].Name] = nil
+				continue
+			}
+			values[fields[i].Name] = value
+		}
+		return r.newRow(values, size), nil
+	}
+}
+
+func (r *rowReader) newRow(values map[string]bigquery.JsonValue, size int) *row {
+	return &row{
+		number:   r.number,
+		insertID: fmt.Sprintf("%v:%v", r.sourceID, r.number),
+		size:     size,
+		values:   values,
+	}
+}
+
+//newInvalidRow creates a row that can not be inserted, it is reported as row error
+func (r *rowReader) newInvalidRow(raw string, template string, args ...interface{}) *row {
+	result := r.newRow(nil, len(raw))
+	result.raw = raw
+	result.invalid = &bigquery.ErrorProto{Reason: invalidReason, Message: fmt.Sprintf(template, args...)}
+	return result
+}
+
+func newRowReader(reader io.Reader, request *Request) (*rowReader, error) {
+	if strings.HasSuffix(request.Source.URL, gzipExt) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create gzip reader: %v", request.Source.URL)
+		}
+		reader = gzipReader
+	}
+	result := &rowReader{
+		request:  request,
+		sourceID: fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%v/%v", request.Source.URL, request.Source.Time.UnixNano())))),
+	}
+	if request.SourceFormat == formatCSV {
+		result.csv = csv.NewReader(reader)
+		result.csv.FieldsPerRecord = -1
+		if delimiter := []rune(request.FieldDelimiter); len(delimiter) > 0 {
+			result.csv.Comma = delimiter[0]
+		}
+		return result, nil
+	}
+	result.lines = bufio.NewReader(reader)
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/bqtail/stage"
+	"google.golang.org/api/bigquery/v2"
+)
+
+const (
+	formatJSON = "NEWLINE_DELIMITED_JSON"
+	formatCSV  = "CSV"
+)
+
+//Request represents streaming insert request
+type Request struct {
+	Source *stage.Source
+	Table  *bigquery.TableReference
+	//Schema destination table schema, CSV values are mapped to schema fields
+	Schema          *bigquery.TableSchema
+	SourceFormat    string
+	SkipLeadingRows int64
+	FieldDelimiter  string
+	//ErrorURL failed rows location
+	ErrorURL string
+}
+
+//Validate checks if request is valid
+func (r *Request) Validate() error {
+	if r.Source == nil || r.Source.URL == "" {
+		return errors.New("source was empty")
+	}
+	if r.Table == nil {
+		return errors.New("table was empty")
+	}
+	switch r.SourceFormat {
+	case formatJSON:
+	case formatCSV:
+		if r.Schema == nil || len(r.Schema.Fields) == 0 {
+			return errors.Errorf("schema was empty for %v format", formatCSV)
+		}
+	default:
+		return errors.Errorf("unsupported stream source format: %v, supported: %v", r.SourceFormat, []string{formatJSON, formatCSV})
+	}
+	return nil
+}
+
+//Response represents streaming insert response
+type Response struct {
+	Rows     int `json:",omitempty"`
+	Inserted int `json:",omitempty"`
+	Failed   int `json:",omitempty"`
+	Requests int `json:",omitempty"`
+	Retries  int `json:",omitempty"`
+}
+
+//RowError represents failed row
+type RowError struct {
+	SourceURL string
+	Row       int
+	InsertID  string
+	Reason    string `json:",omitempty"`
+	Message   string `json:",omitempty"`
+	Location  string `json:",omitempty"`
+	Data      map[string]bigquery.JsonValue
+	Raw       string `json:",omitempty"`
+}
+
+func newRowError(request *Request, record *row, rowError *bigquery.ErrorProto) *RowError {
+	result := &RowError{
+		SourceURL: request.Source.URL,
+		Row:       record.number,
+		InsertID:  record.insertID,
+		Data:      record.values,
+		Raw:       record.raw,
+	}
+	if rowError != nil {
+		result.Reason = rowError.Reason
+		result.Message = rowError.Message
+		result.Location = rowError.Location
+	}
+	return result
+}
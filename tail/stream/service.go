@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/bqtail/base"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/shared"
+	"google.golang.org/api/bigquery/v2"
+	"time"
+)
+
+//retryReasons insert error reasons of rows that can be inserted with another request
+var retryReasons = map[string]bool{
+	"stopped":       true,
+	"timeout":       true,
+	"backendError":  true,
+	"internalError": true,
+}
+
+//Service represents streaming insert service
+type Service interface {
+	//Stream streams data file rows into destination table
+	Stream(ctx context.Context, request *Request) (*Response, error)
+}
+
+type service struct {
+	bq      bq.Service
+	fs      afs.Service
+	backoff time.Duration
+}
+
+//Stream streams data file rows into destination table with size bounded insertAll requests,
+//rows failed with retryable reason are resent, the other failed and unparsable rows are written to request ErrorURL
+func (s *service) Stream(ctx context.Context, request *Request) (*Response, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	reader, err := s.fs.DownloadWithURL(ctx, request.Source.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download: %v", request.Source.URL)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	rows, err := newRowReader(reader, request)
+	if err != nil {
+		return nil, err
+	}
+	response := &Response{}
+	var failed = make([]*RowError, 0)
+	var pending = make([]*row, 0)
+	size := 0
+	for {
+		record, err := rows.next()
+		if err != nil {
+			return response, err
+		}
+		if record != nil && len(pending) > 0 && (len(pending) >= shared.MaxStreamRows || size+record.size > shared.MaxStreamBytes) {
+			if failed, err = s.insert(ctx, request, pending, response, failed); err != nil {
+				return response, err
+			}
+			pending, size = make([]*row, 0), 0
+		}
+		if record == nil {
+			break
+		}
+		response.Rows++
+		if record.invalid != nil {
+			failed = append(failed, newRowError(request, record, record.invalid))
+			continue
+		}
+		pending = append(pending, record)
+		size += record.size
+	}
+	if len(pending) > 0 {
+		if failed, err = s.insert(ctx, request, pending, response, failed); err != nil {
+			return response, err
+		}
+	}
+	response.Failed = len(failed)
+	if len(failed) > 0 {
+		err = s.writeErrors(ctx, request, failed)
+	}
+	return response, err
+}
+
+//insert sends rows with retries, it returns failed rows
+func (s *service) insert(ctx context.Context, request *Request, rows []*row, response *Response, failed []*RowError) ([]*RowError, error) {
+	var reasons = make(map[*row]*bigquery.ErrorProto)
+	for attempt := 0; len(rows) > 0; attempt++ {
+		if attempt > shared.MaxStreamRetries {
+			for _, record := range rows {
+				failed = append(failed, newRowError(request, record, reasons[record]))
+			}
+			return failed, nil
+		}
+		if attempt > 0 {
+			response.Retries++
+			select {
+			case <-ctx.Done():
+				return failed, ctx.Err()
+			case <-time.After(s.backoff * time.Duration(attempt)):
+			}
+		}
+		insertRequest := &bigquery.TableDataInsertAllRequest{Rows: make([]*bigquery.TableDataInsertAllRequestRows, len(rows))}
+		for i, record := range rows {
+			insertRequest.Rows[i] = &bigquery.TableDataInsertAllRequestRows{InsertId: record.insertID, Json: record.values}
+		}
+		response.Requests++
+		insertResponse, err := s.bq.InsertAll(ctx, request.Table, insertRequest)
+		if err != nil {
+			if base.IsRetryError(err) {
+				continue
+			}
+			return failed, err
+		}
+		var retry = make([]*row, 0)
+		var rowErrors = make(map[int64]*bigquery.ErrorProto)
+		for _, insertErrors := range insertResponse.InsertErrors {
+			if len(insertErrors.Errors) > 0 {
+				rowErrors[insertErrors.Index] = insertErrors.Errors[0]
+			}
+		}
+		for i, record := range rows {
+			rowError, ok := rowErrors[int64(i)]
+			if !ok {
+				response.Inserted++
+				continue
+			}
+			if retryReasons[rowError.Reason] {
+				reasons[record] = rowError
+				retry = append(retry, record)
+				continue
+			}
+			failed = append(failed, newRowError(request, record, rowError))
+		}
+		rows = retry
+	}
+	return failed, nil
+}
+
+//writeErrors writes failed rows as new line delimited JSON into error URL
+func (s *service) writeErrors(ctx context.Context, request *Request, failed []*RowError) error {
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	for _, rowError := range failed {
+		if err := encoder.Encode(rowError); err != nil {
+			return errors.Wrapf(err, "failed to encode row %v error", rowError.Row)
+		}
+	}
+	if err := s.fs.Upload(ctx, request.ErrorURL, file.DefaultFileOsMode, buffer); err != nil {
+		return errors.Wrapf(err, "failed to upload stream row errors: %v", request.ErrorURL)
+	}
+	return nil
+}
+
+//New creates streaming insert service
+func New(bqService bq.Service, fs afs.Service) Service {
+	return &service{bq: bqService, fs: fs, backoff: time.Second}
+}
@@ -0,0 +1,187 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/bqtail/service/bq"
+	"github.com/viant/bqtail/stage"
+	"google.golang.org/api/bigquery/v2"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+//inserter fakes insertAll, rows with invalid value are rejected, other rows in the same request are stopped
+type inserter struct {
+	bq.Service
+	rows     map[string]map[string]bigquery.JsonValue
+	requests int
+}
+
+func (i *inserter) InsertAll(ctx context.Context, reference *bigquery.TableReference, request *bigquery.TableDataInsertAllRequest) (*bigquery.TableDataInsertAllResponse, error) {
+	i.requests++
+	response := &bigquery.TableDataInsertAllResponse{}
+	for index, row := range request.Rows {
+		if row.Json["name"] == "invalid" {
+			response.InsertErrors = append(response.InsertErrors, &bigquery.TableDataInsertAllResponseInsertErrors{
+				Index: int64(index), Errors: []*bigquery.ErrorProto{{Reason: "invalid", Location: "name", Message: "invalid value"}},
+			})
+		}
+	}
+	if len(response.InsertErrors) > 0 {
+		failed := map[int64]bool{}
+		for _, insertErrors := range response.InsertErrors {
+			failed[insertErrors.Index] = true
+		}
+		for index := range request.Rows {
+			if !failed[int64(index)] {
+				response.InsertErrors = append(response.InsertErrors, &bigquery.TableDataInsertAllResponseInsertErrors{
+					Index: int64(index), Errors: []*bigquery.ErrorProto{{Reason: "stopped"}},
+				})
+			}
+		}
+		return response, nil
+	}
+	for _, row := range request.Rows {
+		i.rows[row.InsertId] = row.Json
+	}
+	return response, nil
+}
+
+func TestService_Stream(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/stream"
+	var largeJSON = make([]string, 0)
+	for i := 0; i < 1200; i++ {
+		largeJSON = append(largeJSON, fmt.Sprintf(`{"id":%v,"name":"n%v"}`, i, i))
+	}
+	schema := &bigquery.TableSchema{Fields: []*bigquery.TableFieldSchema{{Name: "id", Type: "INTEGER"}, {Name: "name", Type: "STRING"}}}
+
+	var useCases = []struct {
+		description     string
+		URL             string
+		content         string
+		format          string
+		skipLeadingRows int64
+		expect          *Response
+		expectFailed    []int
+		expectRow       map[string]bigquery.JsonValue
+	}{
+		{
+			description: "json rows chunks",
+			URL:         baseURL + "/data/large.json",
+			content:     strings.Join(largeJSON, "\n"),
+			format:      formatJSON,
+			expect:      &Response{Rows: 1200, Inserted: 1200, Requests: 3},
+			expectRow:   map[string]bigquery.JsonValue{"id": json.Number("1"), "name": "n1"},
+		},
+		{
+			description:  "json partial failure retry",
+			URL:          baseURL + "/data/partial.json",
+			content:      "{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"invalid\"}\n{\"id\":3,\"name\":\"c\"}\n",
+			format:       formatJSON,
+			expect:       &Response{Rows: 3, Inserted: 2, Failed: 1, Requests: 2, Retries: 1},
+			expectFailed: []int{3},
+		},
+		{
+			description:     "csv rows",
+			URL:             baseURL + "/data/rows.csv",
+			content:         "id,name\n1,a\n,b\n",
+			format:          formatCSV,
+			skipLeadingRows: 1,
+			expect:          &Response{Rows: 2, Inserted: 2, Requests: 1},
+			expectRow:       map[string]bigquery.JsonValue{"id": nil, "name": "b"},
+		},
+		{
+			description:  "malformed json row",
+			URL:          baseURL + "/data/malformed.json",
+			content:      "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\n{\"id\":3,\"name\":\"c\"}\n",
+			format:       formatJSON,
+			expect:       &Response{Rows: 3, Inserted: 2, Failed: 1, Requests: 1},
+			expectFailed: []int{2},
+		},
+		{
+			description:     "csv row with extra values",
+			URL:             baseURL + "/data/extra.csv",
+			content:         "id,name\n1,a\n2,b,c\n3,d\n",
+			format:          formatCSV,
+			skipLeadingRows: 1,
+			expect:          &Response{Rows: 3, Inserted: 2, Failed: 1, Requests: 1},
+			expectFailed:    []int{3},
+		},
+	}
+
+	for _, useCase := range useCases {
+		_ = fs.Upload(ctx, useCase.URL, file.DefaultFileOsMode, strings.NewReader(useCase.content))
+		fake := &inserter{rows: map[string]map[string]bigquery.JsonValue{}}
+		srv := &service{bq: fake, fs: fs}
+		errorURL := baseURL + "/errors/" + fmt.Sprintf("%v.err", len(useCase.description))
+		request := &Request{
+			Source:          stage.NewSource(useCase.URL, time.Now()),
+			Table:           &bigquery.TableReference{ProjectId: "p", DatasetId: "db", TableId: "events"},
+			Schema:          schema,
+			SourceFormat:    useCase.format,
+			SkipLeadingRows: useCase.skipLeadingRows,
+			ErrorURL:        errorURL,
+		}
+		response, err := srv.Stream(ctx, request)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, response, useCase.description)
+		assert.Equal(t, useCase.expect.Inserted, len(fake.rows), useCase.description)
+		if useCase.expectRow != nil {
+			var found bool
+			for _, row := range fake.rows {
+				if row["name"] == useCase.expectRow["name"] {
+					found = assert.Equal(t, useCase.expectRow, row, useCase.description)
+				}
+			}
+			assert.True(t, found, useCase.description)
+		}
+		if len(useCase.expectFailed) == 0 {
+			continue
+		}
+		reader, err := fs.DownloadWithURL(ctx, errorURL)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		data, _ := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		for _, row := range useCase.expectFailed {
+			assert.Contains(t, string(data), fmt.Sprintf(`"Row":%v,`, row), useCase.description)
+		}
+		assert.Contains(t, string(data), `"Reason":"invalid"`, useCase.description)
+	}
+}
+
+func TestService_Insert_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	srv := &service{bq: &retryInserter{}, backoff: time.Hour}
+	request := &Request{Source: stage.NewSource("mem://localhost/stream/data/cancel.json", time.Now()), Table: &bigquery.TableReference{TableId: "events"}}
+	rows := []*row{{number: 1, insertID: "1", values: map[string]bigquery.JsonValue{"id": 1}}}
+	_, err := srv.insert(ctx, request, rows, &Response{}, nil)
+	assert.Equal(t, context.Canceled, err)
+}
+
+//retryInserter fakes insertAll, all rows are rejected with retryable reason
+type retryInserter struct {
+	bq.Service
+}
+
+func (i *retryInserter) InsertAll(ctx context.Context, reference *bigquery.TableReference, request *bigquery.TableDataInsertAllRequest) (*bigquery.TableDataInsertAllResponse, error) {
+	response := &bigquery.TableDataInsertAllResponse{}
+	for index := range request.Rows {
+		response.InsertErrors = append(response.InsertErrors, &bigquery.TableDataInsertAllResponseInsertErrors{
+			Index: int64(index), Errors: []*bigquery.ErrorProto{{Reason: "backendError"}},
+		})
+	}
+	return response, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/viant/bqtail/shared"
 	"github.com/viant/bqtail/stage"
 	"github.com/viant/bqtail/stage/activity"
+	"github.com/viant/bqtail/task/condition"
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/data"
 	"google.golang.org/api/bigquery/v2"
@@ -19,6 +20,7 @@ import (
 //Action represents route action
 type Action struct {
 	Action         string                 `json:",omitempty"`
+	When           string                 `json:",omitempty"`
 	Meta           *activity.Meta         `json:",omitempty"`
 	Request        map[string]interface{} `json:",omitempty"`
 	serviceRequest interface{}
@@ -30,6 +32,11 @@ func (a *Action) Init(ctx context.Context, fs afs.Service) error {
 	if a.Request == nil {
 		a.Request = make(map[string]interface{})
 	}
+	if a.When != "" {
+		if _, err := condition.Parse(a.When); err != nil {
+			return errors.Wrapf(err, "invalid %v action When", a.Action)
+		}
+	}
 	isEmptyRequest := len(a.Request) == 0
 	if isEmptyRequest {
 		switch a.Action {
@@ -101,6 +108,14 @@ func (a Action) RequestValue(key string) interface{} {
 	return ""
 }
 
+//IsEligible returns true if action When expression is empty or evaluates to true for supplied state
+func (a Action) IsEligible(state map[string]interface{}) (bool, error) {
+	if a.When == "" {
+		return true, nil
+	}
+	return condition.Evaluate(a.When, state)
+}
+
 //RequestStringValue returns request string value for supplied key
 func (a Action) RequestStringValue(key string) string {
 	value := a.RequestValue(key)
@@ -132,6 +147,9 @@ func (a Action) Expand(root *stage.Process, expander data.Map) *Action {
 	var result = &Action{
 		Action: a.Action,
 	}
+	if a.When != "" {
+		result.When = expandCondition(a.When, expander)
+	}
 	expanded := expander.Expand(a.Request)
 	result.Request = toolbox.AsMap(expanded)
 	if !shared.Actionable[a.Action] {
@@ -192,3 +210,59 @@ func newActionFromURL(fs afs.Service, ctx context.Context, URL string) (*Action,
 	}
 	return result, err
 }
+
+//expandCondition expands process variables in When expression, values expanded outside string literals are quoted
+func expandCondition(expression string, expander data.Map) string {
+	quoted := data.Map(quoteValues(expander))
+	builder := strings.Builder{}
+	runes := []rune(expression)
+	start := 0
+	var quote rune
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case quote == 0 && (runes[i] == '"' || runes[i] == '\''):
+			builder.WriteString(quoted.ExpandAsText(string(runes[start:i])))
+			builder.WriteRune(runes[i])
+			quote = runes[i]
+			start = i + 1
+		case quote != 0 && runes[i] == '\\':
+			i++
+		case quote != 0 && runes[i] == quote:
+			builder.WriteString(escapeLiteral(expander.ExpandAsText(string(runes[start:i])), quote))
+			builder.WriteRune(quote)
+			quote = 0
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		if quote == 0 {
+			builder.WriteString(quoted.ExpandAsText(string(runes[start:])))
+		} else {
+			builder.WriteString(string(runes[start:]))
+		}
+	}
+	return builder.String()
+}
+
+//quoteValues returns a copy of the supplied map with text values turned into condition string literals
+func quoteValues(aMap map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{}, len(aMap))
+	for k, v := range aMap {
+		switch value := v.(type) {
+		case string:
+			result[k] = `"` + escapeLiteral(value, '"') + `"`
+		case map[string]interface{}:
+			result[k] = quoteValues(value)
+		case data.Map:
+			result[k] = data.Map(quoteValues(value))
+		default:
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func escapeLiteral(text string, quote rune) string {
+	text = strings.Replace(text, `\`, `\\`, -1)
+	return strings.Replace(text, string(quote), `\`+string(quote), -1)
+}
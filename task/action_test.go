@@ -1,9 +1,12 @@
 package task
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/viant/bqtail/base"
 	"github.com/viant/bqtail/stage"
 	"github.com/viant/toolbox/data"
+	"google.golang.org/api/bigquery/v2"
 	"testing"
 )
 
@@ -45,3 +48,130 @@ func TestActions_Expand(t *testing.T) {
 	}
 
 }
+
+func TestActions_ToRun(t *testing.T) {
+
+	useCases := []struct {
+		description string
+		actions     Actions
+		job         *base.Job
+		err         error
+		expect      []string
+		hasError    bool
+	}{
+		{
+			description: "skip notify on empty load",
+			actions: Actions{
+				OnSuccess: []*Action{
+					{Action: "notify", When: "OutputRows > 0", Request: map[string]interface{}{}},
+					{Action: "delete", Request: map[string]interface{}{}},
+				},
+			},
+			job: &base.Job{
+				Configuration: &bigquery.JobConfiguration{JobType: "LOAD", Load: &bigquery.JobConfigurationLoad{}},
+				Statistics:    &bigquery.JobStatistics{Load: &bigquery.JobStatistics3{OutputRows: 0}},
+			},
+			expect: []string{"delete"},
+		},
+		{
+			description: "run on failure matching error",
+			actions: Actions{
+				OnFailure: []*Action{
+					{Action: "notify", When: `Error contains "quota"`, Request: map[string]interface{}{}},
+					{Action: "call", When: `Error contains "schema"`, Request: map[string]interface{}{}},
+				},
+			},
+			job:    &base.Job{},
+			err:    errors.New("quota exceeded"),
+			expect: []string{"notify"},
+		},
+		{
+			description: "request value condition",
+			actions: Actions{
+				OnSuccess: []*Action{
+					{Action: "move", When: `DestURL != ""`, Request: map[string]interface{}{"DestURL": "gs://bucket/done"}},
+				},
+			},
+			job:    &base.Job{},
+			expect: []string{"move"},
+		},
+		{
+			description: "request values do not leak between actions",
+			actions: Actions{
+				OnSuccess: []*Action{
+					{Action: "move", When: `Target == "archive"`, Request: map[string]interface{}{"Target": "archive"}},
+					{Action: "delete", When: `Target == ""`, Request: map[string]interface{}{}},
+				},
+			},
+			job:    &base.Job{},
+			expect: []string{"move", "delete"},
+		},
+		{
+			description: "invalid condition skips action",
+			actions: Actions{
+				OnSuccess: []*Action{
+					{Action: "copy", When: `OutputRows >`, Request: map[string]interface{}{}},
+					{Action: "delete", When: `Dest =~ "events_[("`, Request: map[string]interface{}{"Dest": "events"}},
+					{Action: "notify", Request: map[string]interface{}{}},
+				},
+			},
+			job:      &base.Job{},
+			expect:   []string{"notify"},
+			hasError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		toRun, err := useCase.actions.ToRun(useCase.err, useCase.job)
+		assert.EqualValues(t, useCase.hasError, err != nil, useCase.description)
+		var actual = make([]string, 0)
+		for _, action := range toRun {
+			actual = append(actual, action.Action)
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestAction_ExpandWhen(t *testing.T) {
+
+	useCases := []struct {
+		description string
+		when        string
+		expander    map[string]interface{}
+		expandWhen  string
+		expect      bool
+	}{
+		{
+			description: "source URL contains",
+			when:        `$SourceURL contains "case002"`,
+			expander:    map[string]interface{}{"SourceURL": "gs://viant_e2e_bqtail/data/case002/dummy.json"},
+			expandWhen:  `"gs://viant_e2e_bqtail/data/case002/dummy.json" contains "case002"`,
+			expect:      true,
+		},
+		{
+			description: "dest table with job stats",
+			when:        `$DestTable =~ "events_.*" && OutputRows > 0`,
+			expander:    map[string]interface{}{"DestTable": "myproject:mydataset.events_20200101"},
+			expandWhen:  `"myproject:mydataset.events_20200101" =~ "events_.*" && OutputRows > 0`,
+			expect:      true,
+		},
+		{
+			description: "variable inside literal",
+			when:        `Dest == "$DestTable"`,
+			expander:    map[string]interface{}{"DestTable": "mydataset.\"quoted\""},
+			expandWhen:  `Dest == "mydataset.\"quoted\""`,
+			expect:      true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		action := Action{Action: "notify", When: useCase.when, Request: map[string]interface{}{}}
+		expanded := action.Expand(nil, data.Map(useCase.expander))
+		assert.EqualValues(t, useCase.expandWhen, expanded.When, useCase.description)
+		eligible, err := expanded.IsEligible(map[string]interface{}{"OutputRows": 10, "Dest": "mydataset.\"quoted\""})
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expect, eligible, useCase.description)
+	}
+}
@@ -72,8 +72,8 @@ func (a Actions) CloneOnFailure() *Actions {
 	return result
 }
 
-//ToRun returns actions to run
-func (a Actions) ToRun(err error, job *base.Job) []*Action {
+//ToRun returns actions to run, actions which When expression fails to evaluate are skipped and reported with an error
+func (a Actions) ToRun(err error, job *base.Job) ([]*Action, error) {
 	var toRun []*Action
 	if err == nil {
 		toRun = append([]*Action{}, a.OnSuccess...)
@@ -86,6 +86,7 @@ func (a Actions) ToRun(err error, job *base.Job) []*Action {
 		}
 	}
 
+	toRun, conditionErr := eligibleActions(toRun, err, job)
 	for i := range toRun {
 		//childInfo := a.Meta.ChildInfo(toRun[i].Action, i+1)
 		//toRun[i].Request[shared.JobIDKey] = childInfo.GetJobID()
@@ -98,7 +99,60 @@ func (a Actions) ToRun(err error, job *base.Job) []*Action {
 			toRun[i].Request[shared.JobSourceKey] = job.Source()
 		}
 	}
-	return toRun
+	return toRun, conditionErr
+}
+
+//eligibleActions filters out actions which When expression evaluates to false or fails to evaluate
+func eligibleActions(actions []*Action, err error, job *base.Job) ([]*Action, error) {
+	var result = make([]*Action, 0)
+	var jobState map[string]interface{}
+	var conditionErr error
+	for _, action := range actions {
+		if action.When == "" {
+			result = append(result, action)
+			continue
+		}
+		if jobState == nil {
+			jobState = conditionState(err, job)
+		}
+		state := make(map[string]interface{}, len(jobState)+len(action.Request))
+		for k, v := range jobState {
+			state[k] = v
+		}
+		for k, v := range action.Request {
+			if _, ok := state[k]; !ok {
+				state[k] = v
+			}
+		}
+		eligible, evalErr := action.IsEligible(state)
+		if evalErr != nil {
+			if conditionErr == nil {
+				conditionErr = errors.Wrapf(evalErr, "failed to evaluate %v action When: %v", action.Action, action.When)
+			}
+			continue
+		}
+		if !eligible {
+			if shared.IsDebugLoggingLevel() {
+				shared.LogF("skipping %v action, condition not met: %v\n", action.Action, action.When)
+			}
+			continue
+		}
+		result = append(result, action)
+	}
+	return result, conditionErr
+}
+
+func conditionState(err error, job *base.Job) map[string]interface{} {
+	var state = make(map[string]interface{})
+	if job != nil {
+		state = job.Stats()
+	}
+	if err != nil {
+		state[shared.ErrorKey] = err.Error()
+	} else if _, ok := state[shared.ErrorKey]; !ok {
+		state[shared.ErrorKey] = ""
+	}
+	return state
 }
 
 //IsEmpty returns is actions are empty
@@ -0,0 +1,373 @@
+package condition
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/toolbox"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//Expression represents a parsed conditional expression
+type Expression struct {
+	text string
+	root node
+}
+
+//String returns expression text
+func (e *Expression) String() string {
+	return e.text
+}
+
+//Evaluate evaluates expression with supplied state
+func (e *Expression) Evaluate(state map[string]interface{}) (bool, error) {
+	value, err := e.root.eval(state)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to evaluate: %v", e.text)
+	}
+	return isTrue(value), nil
+}
+
+//Parse parses conditional expression
+func Parse(expression string) (*Expression, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expression: %v", expression)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = fmt.Errorf("unexpected %q at position %v", p.peek().value, p.peek().pos)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expression: %v", expression)
+	}
+	return &Expression{text: expression, root: root}, nil
+}
+
+//Evaluate parses and evaluates expression with supplied state
+func Evaluate(expression string, state map[string]interface{}) (bool, error) {
+	expr, err := Parse(expression)
+	if err != nil {
+		return false, err
+	}
+	return expr.Evaluate(state)
+}
+
+type node interface {
+	eval(state map[string]interface{}) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (l *literal) eval(state map[string]interface{}) (interface{}, error) {
+	return l.value, nil
+}
+
+type variable struct {
+	name string
+}
+
+func (v *variable) eval(state map[string]interface{}) (interface{}, error) {
+	return lookup(state, v.name), nil
+}
+
+type unary struct {
+	operand node
+}
+
+func (u *unary) eval(state map[string]interface{}) (interface{}, error) {
+	value, err := u.operand.eval(state)
+	if err != nil {
+		return nil, err
+	}
+	return !isTrue(value), nil
+}
+
+type binary struct {
+	operator    string
+	left, right node
+}
+
+func (b *binary) eval(state map[string]interface{}) (interface{}, error) {
+	left, err := b.left.eval(state)
+	if err != nil {
+		return nil, err
+	}
+	switch b.operator {
+	case "&&":
+		if !isTrue(left) {
+			return false, nil
+		}
+		right, err := b.right.eval(state)
+		return isTrue(right), err
+	case "||":
+		if isTrue(left) {
+			return true, nil
+		}
+		right, err := b.right.eval(state)
+		return isTrue(right), err
+	}
+	right, err := b.right.eval(state)
+	if err != nil {
+		return nil, err
+	}
+	switch b.operator {
+	case "==":
+		return compare(left, right) == 0, nil
+	case "!=":
+		return compare(left, right) != 0, nil
+	case ">":
+		return compare(left, right) > 0, nil
+	case ">=":
+		return compare(left, right) >= 0, nil
+	case "<":
+		return compare(left, right) < 0, nil
+	case "<=":
+		return compare(left, right) <= 0, nil
+	case "contains":
+		return strings.Contains(asText(left), asText(right)), nil
+	case "=~", "!~":
+		expr, err := regexp.Compile(asText(right))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regular expression: %v", right)
+		}
+		matched := expr.MatchString(asText(left))
+		if b.operator == "!~" {
+			return !matched, nil
+		}
+		return matched, nil
+	}
+	return nil, fmt.Errorf("unsupported operator: %v", b.operator)
+}
+
+type parser struct {
+	tokens []*token
+	index  int
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() *token {
+	result := p.tokens[p.index]
+	if result.kind != tokenEOF {
+		p.index++
+	}
+	return result
+}
+
+func (p *parser) isOperator(operators ...string) bool {
+	current := p.peek()
+	if current.kind != tokenOperator {
+		return false
+	}
+	for _, operator := range operators {
+		if current.value == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{operator: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{operator: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOperator("!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("==", "!=", ">", ">=", "<", "<=", "=~", "!~", "contains") {
+		operator := p.next().value
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &binary{operator: operator, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	current := p.next()
+	switch current.kind {
+	case tokenLParen:
+		result, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %v", p.peek().pos)
+		}
+		p.next()
+		return result, nil
+	case tokenNumber:
+		value, err := strconv.ParseFloat(current.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %v at position %v", current.value, current.pos)
+		}
+		return &literal{value: value}, nil
+	case tokenString:
+		return &literal{value: current.value}, nil
+	case tokenIdent:
+		switch strings.ToLower(current.value) {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		case "null", "nil":
+			return &literal{value: nil}, nil
+		}
+		name := strings.TrimPrefix(current.value, "$")
+		name = strings.Trim(name, "{}")
+		return &variable{name: name}, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %v", current.value, current.pos)
+}
+
+//lookup returns state value for supplied name, name can use dot to reference nested map value
+func lookup(state map[string]interface{}, name string) interface{} {
+	if value, ok := lookupKey(state, name); ok {
+		return value
+	}
+	index := strings.Index(name, ".")
+	if index == -1 {
+		return nil
+	}
+	value, ok := lookupKey(state, name[:index])
+	if !ok || !toolbox.IsMap(value) {
+		return nil
+	}
+	return lookup(toolbox.AsMap(value), name[index+1:])
+}
+
+func lookupKey(state map[string]interface{}, name string) (interface{}, bool) {
+	if len(state) == 0 {
+		return nil, false
+	}
+	if value, ok := state[name]; ok {
+		return value, true
+	}
+	for k, v := range state {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func compare(left, right interface{}) int {
+	if leftNumber, ok := asNumber(left); ok {
+		if rightNumber, ok := asNumber(right); ok {
+			switch {
+			case leftNumber < rightNumber:
+				return -1
+			case leftNumber > rightNumber:
+				return 1
+			}
+			return 0
+		}
+	}
+	if leftBool, ok := left.(bool); ok {
+		if rightBool, ok := right.(bool); ok {
+			if leftBool == rightBool {
+				return 0
+			}
+			if leftBool {
+				return 1
+			}
+			return -1
+		}
+	}
+	return strings.Compare(asText(left), asText(right))
+}
+
+func asNumber(value interface{}) (float64, bool) {
+	switch actual := value.(type) {
+	case nil, bool:
+		return 0, false
+	case string:
+		result, err := strconv.ParseFloat(strings.TrimSpace(actual), 64)
+		return result, err == nil
+	}
+	if !toolbox.IsNumber(value) {
+		return 0, false
+	}
+	return toolbox.AsFloat(value), true
+}
+
+func asText(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return toolbox.AsString(value)
+}
+
+func isTrue(value interface{}) bool {
+	switch actual := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return actual
+	case string:
+		return actual != "" && !strings.EqualFold(actual, "false") && actual != "0"
+	}
+	if number, ok := asNumber(value); ok {
+		return number != 0
+	}
+	if toolbox.IsSlice(value) {
+		return len(toolbox.AsSlice(value)) > 0
+	}
+	if toolbox.IsMap(value) {
+		return len(toolbox.AsMap(value)) > 0
+	}
+	return true
+}
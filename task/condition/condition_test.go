@@ -0,0 +1,63 @@
+package condition
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+
+	var state = map[string]interface{}{
+		"OutputRows": int64(120),
+		"BadRecords": int64(0),
+		"Dest":       "proj:db.events_20200417",
+		"Error":      "Quota exceeded: too many concurrent queries",
+		"Done":       true,
+		"Params": map[string]interface{}{
+			"Env": "prod",
+		},
+	}
+
+	useCases := []struct {
+		description string
+		expression  string
+		expect      bool
+		hasError    bool
+	}{
+		{description: "numeric comparison", expression: "OutputRows > 0", expect: true},
+		{description: "numeric equality", expression: "BadRecords == 0", expect: true},
+		{description: "numeric comparison false", expression: "OutputRows <= 100", expect: false},
+		{description: "regexp match", expression: `Dest =~ "events_.*"`, expect: true},
+		{description: "regexp not match", expression: `Dest !~ "events_.*"`, expect: false},
+		{description: "contains", expression: `Error contains "Quota"`, expect: true},
+		{description: "logical and", expression: `OutputRows > 0 && Dest =~ 'events'`, expect: true},
+		{description: "logical or with keywords", expression: `OutputRows == 0 or not Done`, expect: false},
+		{description: "parentheses", expression: `(OutputRows == 0 || BadRecords == 0) && Done`, expect: true},
+		{description: "negation", expression: `!(OutputRows > 0)`, expect: false},
+		{description: "dollar variable", expression: `$OutputRows >= 120`, expect: true},
+		{description: "braced variable", expression: `${OutputRows} < 121`, expect: true},
+		{description: "nested variable", expression: `Params.Env == "prod"`, expect: true},
+		{description: "case insensitive name", expression: `outputRows > 1`, expect: true},
+		{description: "unknown variable", expression: `Missing`, expect: false},
+		{description: "unknown variable comparison", expression: `Missing == ""`, expect: true},
+		{description: "bare operand", expression: `Done`, expect: true},
+		{description: "single equal", expression: `Done = true`, expect: true},
+		{description: "negative number", expression: `BadRecords > -1`, expect: true},
+		{description: "unterminated string", expression: `Dest == "events`, hasError: true},
+		{description: "missing operand", expression: `OutputRows >`, hasError: true},
+		{description: "unbalanced parentheses", expression: `(OutputRows > 0`, hasError: true},
+		{description: "invalid regexp", expression: `Dest =~ "events_[("`, hasError: true},
+	}
+
+	for _, useCase := range useCases {
+		actual, err := Evaluate(useCase.expression, state)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
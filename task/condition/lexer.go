@@ -0,0 +1,142 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+//keywordOperators maps word operators to their symbolic form
+var keywordOperators = map[string]string{
+	"and":      "&&",
+	"or":       "||",
+	"not":      "!",
+	"contains": "contains",
+}
+
+var symbolOperators = []string{"&&", "||", "==", "!=", ">=", "<=", "=~", "!~", ">", "<", "!", "="}
+
+func tokenize(expression string) ([]*token, error) {
+	var result = make([]*token, 0)
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			result = append(result, &token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			result = append(result, &token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			value, next, err := readString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, &token{kind: tokenString, value: value, pos: i})
+			i = next
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) && isOperandExpected(result)):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			result = append(result, &token{kind: tokenNumber, value: string(runes[start:i]), pos: start})
+		case isIdentStart(r):
+			start := i
+			i++
+			if r == '$' && i < len(runes) && runes[i] == '{' {
+				end := strings.IndexRune(string(runes[i:]), '}')
+				if end == -1 {
+					return nil, fmt.Errorf("unterminated variable at position %v", start)
+				}
+				i += len([]rune(string(runes[i:])[:end])) + 1
+			}
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			value := string(runes[start:i])
+			if operator, ok := keywordOperators[strings.ToLower(value)]; ok {
+				result = append(result, &token{kind: tokenOperator, value: operator, pos: start})
+				continue
+			}
+			result = append(result, &token{kind: tokenIdent, value: value, pos: start})
+		default:
+			operator := matchOperator(runes[i:])
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %v", r, i)
+			}
+			width := len(operator)
+			if operator == "=" {
+				operator = "=="
+			}
+			result = append(result, &token{kind: tokenOperator, value: operator, pos: i})
+			i += width
+		}
+	}
+	result = append(result, &token{kind: tokenEOF, pos: len(runes)})
+	return result, nil
+}
+
+func readString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	builder := strings.Builder{}
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				builder.WriteRune(runes[i])
+			}
+		case quote:
+			return builder.String(), i + 1, nil
+		default:
+			builder.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at position %v", start)
+}
+
+func matchOperator(runes []rune) string {
+	text := string(runes)
+	for _, candidate := range symbolOperators {
+		if strings.HasPrefix(text, candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func isOperandExpected(tokens []*token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == tokenOperator || last.kind == tokenLParen
+}
+
+func isIdentStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}